@@ -2,10 +2,9 @@ package main
 
 import (
 	"os"
+	"project-wraith/pkg/cli"
 	"project-wraith/pkg/config"
 	"project-wraith/pkg/consts"
-	"project-wraith/pkg/core"
-	"project-wraith/pkg/modules/logger"
 	"project-wraith/pkg/modules/tools"
 )
 
@@ -21,45 +20,10 @@ func main() {
 		panic(err)
 	}
 
-	cfg, err := config.LoadSetup(
-		consts.SetupFileName,
-		consts.SetupExtension,
-		consts.SetupPath)
-	if err != nil {
-		panic(err)
-	}
-
-	ini, err := config.LoadInit(
-		consts.InitFileName,
-		consts.InitExtension,
-		consts.InitPath)
-	if err != nil {
-		panic(err)
-	}
-
-	sct, err := config.LoadSecrets(
-		consts.SecretsFileName,
-		consts.SecretsExtension,
-		consts.SecretsPath)
-	if err != nil {
-		panic(err)
-	}
-
-	log := logger.NewLogger(cfg.Logger.FolderPath)
-	err = log.Initialize()
-	if err != nil {
-		panic(err)
-	}
-
-	err = core.Start(cfg, sct, ini, log)
-	if err != nil {
-		panic(err)
-	}
-
-	err = core.Teardown(cfg, sct, ini)
-	if err != nil {
-		panic(err)
-	}
+	setupFile := config.File{Name: consts.SetupFileName, Extension: consts.SetupExtension, Path: consts.SetupPath}
+	initFile := config.File{Name: consts.InitFileName, Extension: consts.InitExtension, Path: consts.InitPath}
+	secretsFile := config.File{Name: consts.SecretsFileName, Extension: consts.SecretsExtension, Path: consts.SecretsPath}
 
-	os.Exit(0)
+	app := cli.NewCLI(setupFile, initFile, secretsFile, os.Stdin, os.Stdout, os.Stderr)
+	os.Exit(app.Run(os.Args[1:]))
 }
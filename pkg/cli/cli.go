@@ -0,0 +1,271 @@
+package cli
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"project-wraith/pkg/config"
+	"project-wraith/pkg/core"
+	"project-wraith/pkg/modules/logger"
+	"sort"
+	"strings"
+)
+
+// Exit codes returned by Run.
+const (
+	ExitOK      = 0
+	ExitFailure = 1
+	ExitUsage   = 2
+	ExitConfig  = 3
+)
+
+type CLI interface {
+	// Run executes the command in args, the process arguments without the
+	// program name, and returns the exit code. No command means serve.
+	Run(args []string) int
+}
+
+type cli struct {
+	setup   config.File
+	init    config.File
+	secrets config.File
+	stdin   io.Reader
+	stdout  io.Writer
+	stderr  io.Writer
+
+	overrides []string
+	conf      *config.Config
+	log       logger.Logger
+}
+
+// command is one subcommand. Its name may have two words, like
+// "migrate up".
+type command struct {
+	name    string
+	summary string
+	run     func(c *cli, args []string) error
+}
+
+var commands = []command{
+	{"serve", "run the API server (the default)", serve},
+	{"config check", "validate the configuration and exit", configCheck},
+	{"config print", "print the effective configuration, secrets redacted", configPrint},
+	{"migrate up", "apply the pending schema migrations", migrateUp},
+	{"migrate down", "revert the last applied schema migrations", migrateDown},
+	{"migrate status", "list the schema migrations and whether they are applied", migrateStatus},
+	{"migrate crypt", "convert the users to the form selected by encrypt_db_data", migrateCrypt},
+	{"user create-admin", "register a user account from the command line", userCreateAdmin},
+	{"manticore add-operator", "add or update an internal API operator", manticoreAddOperator},
+	{"license issue", "store a new license and print its key", licenseIssue},
+	{"license verify", "check a license key is active and not expired", licenseVerify},
+	{"keys rotate", "re-encrypt the users with a new db data secret", keysRotate},
+	{"logs decrypt", "print the plain entries of a log file", logsDecrypt},
+	{"backup", "write every database to a gzipped backup file", backup},
+	{"restore", "upsert every document of a backup file", restore},
+}
+
+// usageError makes Run print the command usage and exit with ExitUsage.
+type usageError struct {
+	msg string
+}
+
+func (e usageError) Error() string {
+	return e.msg
+}
+
+func usagef(format string, args ...interface{}) error {
+	return usageError{msg: fmt.Sprintf(format, args...)}
+}
+
+// NewCLI is a function constructor for CLI. Every command loads the setup,
+// init and secrets files the same way, with the -set overrides given before
+// the command name.
+func NewCLI(setup, init, secrets config.File, stdin io.Reader, stdout, stderr io.Writer) CLI {
+	return &cli{
+		setup:   setup,
+		init:    init,
+		secrets: secrets,
+		stdin:   stdin,
+		stdout:  stdout,
+		stderr:  stderr,
+	}
+}
+
+func (c *cli) Run(args []string) int {
+	flags := flag.NewFlagSet("wraith", flag.ContinueOnError)
+	flags.SetOutput(c.stderr)
+	flags.Var((*overrideFlags)(&c.overrides), "set", "override a configuration key, e.g. -set server.port=9090")
+	flags.Usage = c.usage
+	if err := flags.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return ExitOK
+		}
+		return ExitUsage
+	}
+
+	args = flags.Args()
+	if len(args) == 0 {
+		args = []string{"serve"}
+	}
+
+	if args[0] == "help" {
+		c.usage()
+		return ExitOK
+	}
+
+	cmd, rest, ok := find(args)
+	if !ok {
+		fmt.Fprintf(c.stderr, "unknown command %q\n\n", strings.Join(args[:min(len(args), 2)], " "))
+		c.usage()
+		return ExitUsage
+	}
+
+	conf, err := config.Load(c.setup, c.init, c.secrets, c.overrides)
+	if err != nil {
+		fmt.Fprintln(c.stderr, err)
+		return ExitConfig
+	}
+	c.conf = conf
+
+	err = cmd.run(c, rest)
+
+	var usage usageError
+	switch {
+	case errors.Is(err, flag.ErrHelp):
+		return ExitOK
+	case errors.As(err, &usage):
+		fmt.Fprintf(c.stderr, "%s: %s\n", cmd.name, usage.msg)
+		return ExitUsage
+	case err != nil:
+		fmt.Fprintf(c.stderr, "%s: %v\n", cmd.name, err)
+		return ExitFailure
+	}
+
+	return ExitOK
+}
+
+// find matches the longest command name at the start of args.
+func find(args []string) (command, []string, bool) {
+	for _, words := range []int{2, 1} {
+		if len(args) < words {
+			continue
+		}
+
+		name := strings.Join(args[:words], " ")
+		for _, cmd := range commands {
+			if cmd.name == name {
+				return cmd, args[words:], true
+			}
+		}
+	}
+
+	return command{}, nil, false
+}
+
+func (c *cli) usage() {
+	fmt.Fprintln(c.stderr, "usage: wraith [-set key=value]... [command] [flags]")
+	fmt.Fprintln(c.stderr, "\ncommands:")
+
+	sorted := append([]command(nil), commands...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].name < sorted[j].name })
+	for _, cmd := range sorted {
+		fmt.Fprintf(c.stderr, "  %-24s %s\n", cmd.name, cmd.summary)
+	}
+
+	fmt.Fprintf(c.stderr, "\nexit codes: %d ok, %d failure, %d usage, %d invalid configuration\n",
+		ExitOK, ExitFailure, ExitUsage, ExitConfig)
+}
+
+// flags returns the flag set of cmd, reporting errors to stderr.
+func (c *cli) flags(name string) *flag.FlagSet {
+	flags := flag.NewFlagSet(name, flag.ContinueOnError)
+	flags.SetOutput(c.stderr)
+	return flags
+}
+
+// parse parses args into flags and checks the number of positional
+// arguments.
+func parse(flags *flag.FlagSet, args []string, positional int) error {
+	if err := flags.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return err
+		}
+		return usageError{msg: err.Error()}
+	}
+
+	if flags.NArg() != positional {
+		return usagef("expected %d arguments, got %d", positional, flags.NArg())
+	}
+
+	return nil
+}
+
+// logger builds the file logger on first use; commands that only print do
+// not need one.
+func (c *cli) logger() (logger.Logger, error) {
+	if c.log != nil {
+		return c.log, nil
+	}
+
+	cfg, ini, sct := c.conf.Setup, c.conf.Init, c.conf.Secrets
+
+	logsKey, err := core.LogsKey(sct, ini)
+	if err != nil {
+		return nil, err
+	}
+
+	log := logger.NewLogger(cfg.Logger.FolderPath, cfg.Logger.Debug, logger.Rotation{
+		MaxSizeMB:  cfg.Logger.MaxSizeMB,
+		MaxBackups: cfg.Logger.MaxBackups,
+		MaxAgeDays: cfg.Logger.MaxAgeDays,
+		Daily:      cfg.Logger.Daily,
+		Compress:   cfg.Logger.Compress,
+	}, logger.Redaction{
+		Fields: cfg.Logger.Redact.Fields,
+		Allow:  cfg.Logger.Redact.Allow,
+	}, logsKey)
+	if err := log.Initialize(); err != nil {
+		return nil, err
+	}
+
+	if err := log.SetLevel(cfg.Logger.Level); err != nil {
+		return nil, err
+	}
+
+	c.log = log
+	return log, nil
+}
+
+// secret returns value, or the first line of stdin when value is empty, so
+// passwords can be piped instead of left in the shell history.
+func (c *cli) secret(name, value string) (string, error) {
+	if value != "" {
+		return value, nil
+	}
+
+	line, err := bufio.NewReader(c.stdin).ReadString('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		return "", err
+	}
+
+	line = strings.TrimRight(line, "\r\n")
+	if line == "" {
+		return "", usagef("-%s is required, as a flag or on stdin", name)
+	}
+
+	return line, nil
+}
+
+// overrideFlags collects the repeated -set flags.
+type overrideFlags []string
+
+func (o *overrideFlags) String() string {
+	return strings.Join(*o, ",")
+}
+
+func (o *overrideFlags) Set(value string) error {
+	*o = append(*o, value)
+	return nil
+}
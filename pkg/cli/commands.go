@@ -0,0 +1,461 @@
+package cli
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"project-wraith/pkg/config"
+	"project-wraith/pkg/consts"
+	"project-wraith/pkg/core"
+	"project-wraith/pkg/internal/domain"
+	"project-wraith/pkg/internal/rules"
+	"project-wraith/pkg/modules/db"
+	"project-wraith/pkg/modules/guard"
+	"project-wraith/pkg/modules/lics"
+	"project-wraith/pkg/modules/migrate"
+	"text/tabwriter"
+	"time"
+
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+func serve(c *cli, args []string) error {
+	if err := parse(c.flags("serve"), args, 0); err != nil {
+		return err
+	}
+
+	log, err := c.logger()
+	if err != nil {
+		return err
+	}
+
+	watcher, err := config.NewWatcher(log, c.conf, c.setup, c.init, c.secrets, c.overrides)
+	if err != nil {
+		return err
+	}
+
+	return core.Start(watcher, log)
+}
+
+func configCheck(c *cli, args []string) error {
+	if err := parse(c.flags("config check"), args, 0); err != nil {
+		return err
+	}
+
+	// Run only gets here when the configuration loaded and validated.
+	fmt.Fprintln(c.stdout, "configuration is valid")
+	return nil
+}
+
+func configPrint(c *cli, args []string) error {
+	if err := parse(c.flags("config print"), args, 0); err != nil {
+		return err
+	}
+
+	return c.conf.Print(c.stdout)
+}
+
+func migrateUp(c *cli, args []string) error {
+	if err := parse(c.flags("migrate up"), args, 0); err != nil {
+		return err
+	}
+
+	return c.withMigrator(func(ctx context.Context, migrator migrate.Migrator) error {
+		applied, err := migrator.Up(ctx)
+		c.printMigrations("applied", applied)
+		return err
+	})
+}
+
+func migrateDown(c *cli, args []string) error {
+	flags := c.flags("migrate down")
+	steps := flags.Int("steps", 1, "number of migrations to revert")
+	if err := parse(flags, args, 0); err != nil {
+		return err
+	}
+
+	if *steps < 1 {
+		return usagef("-steps must be at least 1")
+	}
+
+	return c.withMigrator(func(ctx context.Context, migrator migrate.Migrator) error {
+		reverted, err := migrator.Down(ctx, *steps)
+		c.printMigrations("reverted", reverted)
+		return err
+	})
+}
+
+func migrateStatus(c *cli, args []string) error {
+	if err := parse(c.flags("migrate status"), args, 0); err != nil {
+		return err
+	}
+
+	return c.withMigrator(func(ctx context.Context, migrator migrate.Migrator) error {
+		statuses, err := migrator.Status(ctx)
+		if err != nil {
+			return err
+		}
+
+		table := tabwriter.NewWriter(c.stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(table, "VERSION\tNAME\tAPPLIED AT")
+		for _, status := range statuses {
+			appliedAt := "pending"
+			if status.Applied {
+				appliedAt = status.AppliedAt.Format(time.RFC3339)
+			}
+			fmt.Fprintf(table, "%d\t%s\t%s\n", status.Version, status.Name, appliedAt)
+		}
+
+		return table.Flush()
+	})
+}
+
+func migrateCrypt(c *cli, args []string) error {
+	flags := c.flags("migrate crypt")
+	dryRun := flags.Bool("dry-run", false, "report what would change without writing")
+	batchSize := flags.Int("batch", 500, "documents per batch")
+	if err := parse(flags, args, 0); err != nil {
+		return err
+	}
+
+	log, err := c.logger()
+	if err != nil {
+		return err
+	}
+
+	report, err := core.MigrateCrypt(c.conf.Secrets, c.conf.Init, log, *dryRun, *batchSize)
+	if report != nil {
+		fmt.Fprintln(c.stdout, report)
+	}
+
+	return err
+}
+
+func userCreateAdmin(c *cli, args []string) error {
+	flags := c.flags("user create-admin")
+	id := flags.String("id", "", "user id, generated when empty")
+	username := flags.String("username", "", "username (required)")
+	email := flags.String("email", "", "email (required)")
+	name := flags.String("name", "", "display name")
+	phone := flags.String("phone", "", "phone number")
+	password := flags.String("password", "", "password, read from stdin when empty")
+	if err := parse(flags, args, 0); err != nil {
+		return err
+	}
+
+	if *username == "" || *email == "" {
+		return usagef("-username and -email are required")
+	}
+
+	pass, err := c.secret("password", *password)
+	if err != nil {
+		return err
+	}
+
+	if *id == "" {
+		*id, err = randomHex(16)
+		if err != nil {
+			return err
+		}
+	}
+
+	return c.withDatabases(func(ctx context.Context, databases map[string]db.Client) error {
+		ini, sct := c.conf.Init, c.conf.Secrets
+
+		userCollection := databases["user"].Collection(consts.UsersCollection)
+		userRepo := domain.NewUserRepository(*userCollection, ini.Database.Timeout)
+		userRule := rules.NewUserRule(userRepo, ini.Options.EncryptDbData, sct.Keys.DbData, sct.Keys.Password)
+
+		user, err := userRule.Register(ctx, rules.User{
+			ID:       *id,
+			Username: *username,
+			Email:    *email,
+			Name:     *name,
+			Phone:    *phone,
+			Password: pass,
+		})
+		if err != nil {
+			return err
+		}
+
+		fmt.Fprintf(c.stdout, "user %s created with id %s\n", user.Username, user.ID)
+		return nil
+	})
+}
+
+func manticoreAddOperator(c *cli, args []string) error {
+	flags := c.flags("manticore add-operator")
+	username := flags.String("username", "", "operator username (required)")
+	password := flags.String("password", "", "operator password, read from stdin when empty")
+	if err := parse(flags, args, 0); err != nil {
+		return err
+	}
+
+	if *username == "" {
+		return usagef("-username is required")
+	}
+
+	pass, err := c.secret("password", *password)
+	if err != nil {
+		return err
+	}
+
+	return c.withDatabases(func(ctx context.Context, databases map[string]db.Client) error {
+		internalsCollection := databases["manager"].Collection(consts.InternalsCollection)
+		manticore := guard.NewManticore(*internalsCollection, c.conf.Init.Database.Timeout, c.conf.Secrets.Keys.Internals)
+
+		if err := manticore.AddOperator(ctx, guard.Credentials{Username: *username, Password: pass}); err != nil {
+			return err
+		}
+
+		fmt.Fprintf(c.stdout, "operator %s saved\n", *username)
+		return nil
+	})
+}
+
+func licenseIssue(c *cli, args []string) error {
+	flags := c.flags("license issue")
+	key := flags.String("key", "", "license key, generated when empty")
+	product := flags.String("product", consts.ServerName, "product name")
+	customer := flags.String("customer", "", "customer name (required)")
+	email := flags.String("email", "", "customer email")
+	days := flags.Int("days", 365, "days until the license expires")
+	activations := flags.Int("max-activations", 1, "maximum number of activations")
+	if err := parse(flags, args, 0); err != nil {
+		return err
+	}
+
+	if *customer == "" {
+		return usagef("-customer is required")
+	}
+	if *days < 1 || *activations < 1 {
+		return usagef("-days and -max-activations must be at least 1")
+	}
+
+	if *key == "" {
+		var err error
+		*key, err = randomHex(32)
+		if err != nil {
+			return err
+		}
+	}
+
+	return c.withDatabases(func(ctx context.Context, databases map[string]db.Client) error {
+		licensesCollection := databases["license"].Collection(consts.LicensesCollection)
+		licensesRepo := lics.NewLicenseRepository(*licensesCollection, c.conf.Init.Database.Timeout)
+
+		now := time.Now().UTC()
+		err := licensesRepo.Issue(ctx, lics.License{
+			ProductName:    *product,
+			LicenseKey:     *key,
+			CustomerName:   *customer,
+			CustomerEmail:  *email,
+			IssuedAt:       now,
+			ExpiryDate:     now.AddDate(0, 0, *days),
+			MaxActivations: *activations,
+			IsActive:       true,
+		})
+		if err != nil {
+			return err
+		}
+
+		fmt.Fprintln(c.stdout, *key)
+		return nil
+	})
+}
+
+func licenseVerify(c *cli, args []string) error {
+	flags := c.flags("license verify")
+	key := flags.String("key", "", "license key, the license file when empty")
+	if err := parse(flags, args, 0); err != nil {
+		return err
+	}
+
+	if *key == "" {
+		var err error
+		*key, err = config.LoadLicense(consts.LicFileName, consts.LicExtension, consts.LicPath)
+		if err != nil {
+			return err
+		}
+	}
+
+	return c.withDatabases(func(ctx context.Context, databases map[string]db.Client) error {
+		licensesCollection := databases["license"].Collection(consts.LicensesCollection)
+		licensesRepo := lics.NewLicenseRepository(*licensesCollection, c.conf.Init.Database.Timeout)
+
+		if err := core.Activate(ctx, licensesRepo, *key); err != nil {
+			return err
+		}
+
+		fmt.Fprintln(c.stdout, "license is valid")
+		return nil
+	})
+}
+
+func keysRotate(c *cli, args []string) error {
+	flags := c.flags("keys rotate")
+	next := flags.String("new", "", "new db data secret, generated when empty")
+	dryRun := flags.Bool("dry-run", false, "report what would change without writing")
+	batchSize := flags.Int("batch", 500, "documents per batch")
+	if err := parse(flags, args, 0); err != nil {
+		return err
+	}
+
+	generated := *next == ""
+	if generated {
+		var err error
+		*next, err = randomHex(32)
+		if err != nil {
+			return err
+		}
+	}
+
+	log, err := c.logger()
+	if err != nil {
+		return err
+	}
+
+	report, err := core.RotateCrypt(c.conf.Secrets, c.conf.Init, log, *next, *dryRun, *batchSize)
+	if report != nil {
+		fmt.Fprintln(c.stdout, report)
+	}
+	if err != nil {
+		return err
+	}
+
+	if !*dryRun {
+		if generated {
+			fmt.Fprintf(c.stdout, "new secret: %s\n", *next)
+		}
+		fmt.Fprintln(c.stdout, "set SECRET_DB to the new secret before restarting the server")
+	}
+
+	return nil
+}
+
+func logsDecrypt(c *cli, args []string) error {
+	flags := c.flags("logs decrypt")
+	out := flags.String("out", "", "write to this file instead of stdout")
+	if err := parse(flags, args, 1); err != nil {
+		return err
+	}
+
+	if *out == "" {
+		return core.DecryptLogs(c.conf.Secrets, flags.Arg(0), c.stdout)
+	}
+
+	dst, err := os.Create(*out)
+	if err != nil {
+		return err
+	}
+
+	err = core.DecryptLogs(c.conf.Secrets, flags.Arg(0), dst)
+	return errors.Join(err, dst.Close())
+}
+
+func backup(c *cli, args []string) error {
+	flags := c.flags("backup")
+	out := flags.String("out", "", "backup file (required)")
+	if err := parse(flags, args, 0); err != nil {
+		return err
+	}
+
+	if *out == "" {
+		return usagef("-out is required")
+	}
+
+	return c.withDatabases(func(ctx context.Context, databases map[string]db.Client) error {
+		dst, err := os.Create(*out)
+		if err != nil {
+			return err
+		}
+
+		report, err := core.Backup(ctx, c.mongoDatabases(databases), dst)
+		if err = errors.Join(err, dst.Close()); err != nil {
+			return err
+		}
+
+		fmt.Fprintln(c.stdout, report)
+		return nil
+	})
+}
+
+func restore(c *cli, args []string) error {
+	flags := c.flags("restore")
+	if err := parse(flags, args, 1); err != nil {
+		return err
+	}
+
+	src, err := os.Open(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	return c.withDatabases(func(ctx context.Context, databases map[string]db.Client) error {
+		report, err := core.Restore(ctx, c.mongoDatabases(databases), src)
+		fmt.Fprintln(c.stdout, report)
+		return err
+	})
+}
+
+func (c *cli) withDatabases(run func(ctx context.Context, databases map[string]db.Client) error) error {
+	databases, close, err := core.OpenDatabases(c.conf.Init)
+	if err != nil {
+		return err
+	}
+	defer close()
+
+	return run(context.Background(), databases)
+}
+
+func (c *cli) withMigrator(run func(ctx context.Context, migrator migrate.Migrator) error) error {
+	return c.withDatabases(func(ctx context.Context, databases map[string]db.Client) error {
+		migrator, err := core.Migrator(c.conf.Init, databases)
+		if err != nil {
+			return err
+		}
+
+		return run(ctx, migrator)
+	})
+}
+
+func (c *cli) printMigrations(verb string, migrations []migrate.Migration) {
+	if len(migrations) == 0 {
+		fmt.Fprintf(c.stdout, "no migration %s\n", verb)
+		return
+	}
+
+	for _, migration := range migrations {
+		fmt.Fprintf(c.stdout, "%s %d %s\n", verb, migration.Version, migration.Name)
+	}
+}
+
+// mongoDatabases maps user, manager and license to their database handles.
+func (c *cli) mongoDatabases(databases map[string]db.Client) map[string]*mongo.Database {
+	ini := c.conf.Init
+	names := map[string]string{
+		"user":    ini.Database.User.Name,
+		"manager": ini.Database.Manager.Name,
+		"license": ini.Database.License.Name,
+	}
+
+	handles := make(map[string]*mongo.Database, len(databases))
+	for key, client := range databases {
+		handles[key] = client.Client().Database(names[key])
+	}
+
+	return handles
+}
+
+func randomHex(size int) (string, error) {
+	buf := make([]byte, size)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(buf), nil
+}
@@ -0,0 +1,295 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// EnvPrefix starts the environment variables overriding configuration keys:
+// server.port is WRAITH_SERVER_PORT.
+const EnvPrefix = "WRAITH_"
+
+const redacted = "[redacted]"
+
+// File is the configuration file Name.Extension in Path.
+type File struct {
+	Name      string
+	Extension string
+	Path      string
+}
+
+// Config is the whole configuration tree. Every leaf has a snake_case key,
+// its field path, such as database.user.uri or notify.webhook.url.
+type Config struct {
+	Setup   *Setup
+	Init    *Init
+	Secrets *Secrets
+}
+
+// Load builds the configuration in layers: the defaults, the setup, init and
+// secrets files, the WRAITH_ environment variables and last the key=value
+// overrides. The result is validated and every problem is reported at once.
+func Load(setup, init, secrets File, overrides []string) (*Config, error) {
+	setupConfig, err := LoadSetup(setup.Name, setup.Extension, setup.Path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load setup file: %w", err)
+	}
+
+	initConfig, err := LoadInit(init.Name, init.Extension, init.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	secretsConfig, err := LoadSecrets(secrets.Name, secrets.Extension, secrets.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	config := &Config{Setup: setupConfig, Init: initConfig, Secrets: secretsConfig}
+
+	var errs []error
+	errs = append(errs, config.overrideEnv(os.Environ())...)
+	errs = append(errs, config.override(overrides)...)
+	if len(errs) == 0 {
+		errs = config.validate()
+	}
+
+	if len(errs) > 0 {
+		return nil, fmt.Errorf("invalid configuration:\n%w", errors.Join(errs...))
+	}
+
+	return config, nil
+}
+
+// Print writes the effective configuration as key = value lines. Secrets are
+// redacted, and so are the passwords in URLs.
+func (c *Config) Print(w io.Writer) error {
+	for _, leaf := range c.leaves() {
+		value := format(leaf.value)
+		switch {
+		case leaf.secret && value != "":
+			value = redacted
+		case strings.Contains(value, "://"):
+			if u, err := url.Parse(value); err == nil {
+				value = u.Redacted()
+			}
+		}
+
+		if _, err := fmt.Fprintf(w, "%s = %s\n", leaf.key, value); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (c *Config) overrideEnv(environ []string) []error {
+	var errs []error
+	for _, pair := range environ {
+		name, value, _ := strings.Cut(pair, "=")
+		if !strings.HasPrefix(name, EnvPrefix) {
+			continue
+		}
+
+		key := strings.ToLower(strings.TrimPrefix(name, EnvPrefix))
+		if err := c.set(key, value, func(key string) string {
+			return strings.ReplaceAll(key, ".", "_")
+		}); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", name, err))
+		}
+	}
+
+	return errs
+}
+
+func (c *Config) override(overrides []string) []error {
+	var errs []error
+	for _, override := range overrides {
+		key, value, ok := strings.Cut(override, "=")
+		if !ok {
+			errs = append(errs, fmt.Errorf("%s: expected key=value", override))
+			continue
+		}
+
+		if err := c.set(strings.TrimSpace(key), value, nil); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", key, err))
+		}
+	}
+
+	return errs
+}
+
+// describe names key in problems, with the variable it is read from when it
+// has its own.
+func (c *Config) describe(key string) string {
+	for _, leaf := range c.leaves() {
+		if leaf.key == key && leaf.env != "" {
+			return fmt.Sprintf("%s (%s)", key, leaf.env)
+		}
+	}
+
+	return key
+}
+
+// set parses raw into the leaf whose key, passed through name when given,
+// equals key.
+func (c *Config) set(key, raw string, name func(key string) string) error {
+	for _, leaf := range c.leaves() {
+		leafKey := leaf.key
+		if name != nil {
+			leafKey = name(leafKey)
+		}
+
+		if leafKey == key {
+			return parse(leaf.value, raw)
+		}
+	}
+
+	return errors.New("unknown configuration key")
+}
+
+type leaf struct {
+	key    string
+	env    string
+	value  reflect.Value
+	secret bool
+	reload bool
+}
+
+func (c *Config) leaves() []leaf {
+	var leaves []leaf
+	walk("", reflect.ValueOf(c.Setup).Elem(), false, &leaves)
+	walk("", reflect.ValueOf(c.Init).Elem(), false, &leaves)
+	walk("", reflect.ValueOf(c.Secrets).Elem(), true, &leaves)
+
+	return leaves
+}
+
+// walk collects the leaves of v. Fields tagged config:"secret", and every
+// field under secret, are redacted when printed; fields tagged reload:"true"
+// take effect on a reload. Embedded structs add no key.
+func walk(prefix string, v reflect.Value, secret bool, leaves *[]leaf) {
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Type().Field(i)
+
+		key := prefix
+		if !field.Anonymous {
+			key = strings.TrimPrefix(prefix+"."+snake(field.Name), ".")
+		}
+		fieldSecret := secret || field.Tag.Get("config") == "secret"
+
+		if field.Type.Kind() == reflect.Struct {
+			walk(key, v.Field(i), fieldSecret, leaves)
+			continue
+		}
+
+		*leaves = append(*leaves, leaf{
+			key:    key,
+			env:    field.Tag.Get("env"),
+			value:  v.Field(i),
+			secret: fieldSecret,
+			reload: field.Tag.Get("reload") == "true",
+		})
+	}
+}
+
+// snake turns a field name into its key: MaxSizeMB is max_size_mb.
+func snake(name string) string {
+	runes := []rune(name)
+
+	var b strings.Builder
+	for i, r := range runes {
+		if i > 0 && unicode.IsUpper(r) {
+			prev := runes[i-1]
+			nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if unicode.IsLower(prev) || unicode.IsDigit(prev) || (unicode.IsUpper(prev) && nextLower) {
+				b.WriteByte('_')
+			}
+		}
+		b.WriteRune(unicode.ToLower(r))
+	}
+
+	return b.String()
+}
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+func parse(v reflect.Value, raw string) error {
+	raw = strings.TrimSpace(raw)
+
+	if v.Type() == durationType {
+		d, err := time.ParseDuration(raw)
+		if err != nil {
+			return fmt.Errorf("invalid duration %q", raw)
+		}
+		v.SetInt(int64(d))
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(raw)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return fmt.Errorf("invalid boolean %q", raw)
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int64:
+		n, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid integer %q", raw)
+		}
+		v.SetInt(n)
+	case reflect.Float64:
+		f, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return fmt.Errorf("invalid number %q", raw)
+		}
+		v.SetFloat(f)
+	case reflect.Slice:
+		items := split(raw)
+		slice := reflect.MakeSlice(v.Type(), len(items), len(items))
+		for i, item := range items {
+			if err := parse(slice.Index(i), item); err != nil {
+				return err
+			}
+		}
+		v.Set(slice)
+	default:
+		return fmt.Errorf("unsupported type %s", v.Type())
+	}
+
+	return nil
+}
+
+func split(raw string) []string {
+	var items []string
+	for _, item := range strings.Split(raw, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+
+	return items
+}
+
+func format(v reflect.Value) string {
+	if v.Kind() == reflect.Slice {
+		items := make([]string, v.Len())
+		for i := range items {
+			items[i] = format(v.Index(i))
+		}
+		return strings.Join(items, ",")
+	}
+
+	return fmt.Sprint(v.Interface())
+}
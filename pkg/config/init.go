@@ -4,14 +4,24 @@ import (
 	"fmt"
 	"gopkg.in/ini.v1"
 	"path/filepath"
+	"time"
 )
 
+// NotifyRoute sends the notifications at Severity or above in one of
+// Categories, or in any category when there are none, to a channel.
+type NotifyRoute struct {
+	Enabled    bool
+	Severity   string
+	Categories []string
+}
+
 type Init struct {
 	App struct {
 		Level string
 	}
 	Database struct {
-		User struct {
+		Timeout time.Duration
+		User    struct {
 			Uri  string
 			Name string
 		}
@@ -25,21 +35,69 @@ type Init struct {
 		}
 	}
 	Storage struct {
-		Bucket string
+		Backend      string
+		Bucket       string
+		Endpoint     string
+		Region       string
+		PathStyle    bool
+		LocalPath    string
+		ShipInterval time.Duration
 	}
 	Sms struct {
 		ResetAsset string
 		From       string
 		AccountSID string
-		AuthToken  string
+		AuthToken  string `config:"secret"`
 	}
 	Mail struct {
 		From     string
-		Password string
+		Password string `config:"secret"`
 		Host     string
 		Port     string
 	}
+	Notify struct {
+		Window   time.Duration
+		Telegram struct {
+			NotifyRoute
+			BaseURL string
+		}
+		Slack   NotifyRoute
+		Webhook struct {
+			NotifyRoute
+			URL string
+		}
+		Email struct {
+			NotifyRoute
+			To       []string
+			Template string
+		}
+	}
+	Ops struct {
+		AllowedChats []int64
+		PollTimeout  time.Duration
+	}
+	Shutdown struct {
+		DrainTimeout time.Duration
+		Timeout      time.Duration
+	}
+	Health struct {
+		CacheTTL time.Duration
+		Timeout  time.Duration
+	}
+	Migrations struct {
+		Auto    bool
+		LockTTL time.Duration
+	}
+	Tracing struct {
+		Endpoint    string
+		Insecure    bool
+		SampleRatio float64
+		ServiceName string
+	}
 	Options struct {
+		NotifyErrors    bool
+		OpsBot          bool
+		Tracing         bool
 		EncryptResponse bool
 		EncryptDbData   bool
 		EncryptLogs     bool
@@ -59,7 +117,11 @@ func LoadInit(fileName, extension, folderPath string) (*Init, error) {
 
 	var initConfig Init
 
+	// App section
+	initConfig.App.Level = cfgIni.Section("app").Key("level").String()
+
 	// Database section
+	initConfig.Database.Timeout = cfgIni.Section("database").Key("timeout").MustDuration(5 * time.Second)
 	initConfig.Database.User.Uri = cfgIni.Section("database.user").Key("uri").String()
 	initConfig.Database.User.Name = cfgIni.Section("database.user").Key("name").String()
 	initConfig.Database.Manager.Uri = cfgIni.Section("database.manager").Key("uri").String()
@@ -67,11 +129,20 @@ func LoadInit(fileName, extension, folderPath string) (*Init, error) {
 	initConfig.Database.License.Uri = cfgIni.Section("database.license").Key("uri").String()
 	initConfig.Database.License.Name = cfgIni.Section("database.license").Key("name").String()
 
+	// Storage section
+	initConfig.Storage.Backend = cfgIni.Section("storage").Key("backend").MustString("s3")
+	initConfig.Storage.Bucket = cfgIni.Section("storage").Key("bucket").String()
+	initConfig.Storage.Endpoint = cfgIni.Section("storage").Key("endpoint").MustString("https://ewr1.vultrobjects.com/")
+	initConfig.Storage.Region = cfgIni.Section("storage").Key("region").MustString("ewr")
+	initConfig.Storage.PathStyle = cfgIni.Section("storage").Key("path_style").MustBool()
+	initConfig.Storage.LocalPath = cfgIni.Section("storage").Key("local_path").MustString("./storage")
+	initConfig.Storage.ShipInterval = cfgIni.Section("storage").Key("ship_interval").MustDuration(15 * time.Minute)
+
 	// SMS section
-	initConfig.Sms.ResetAsset = cfgIni.Section("sms").Key("reset_asset").String()
+	initConfig.Sms.ResetAsset = key(cfgIni.Section("sms"), "reset_asset", "resetAsset").String()
 	initConfig.Sms.From = cfgIni.Section("sms").Key("from").String()
-	initConfig.Sms.AccountSID = cfgIni.Section("sms").Key("account_sid").String()
-	initConfig.Sms.AuthToken = cfgIni.Section("sms").Key("auth_token").String()
+	initConfig.Sms.AccountSID = key(cfgIni.Section("sms"), "account_sid", "accountSID").String()
+	initConfig.Sms.AuthToken = key(cfgIni.Section("sms"), "auth_token", "authToken").String()
 
 	// Mail section
 	initConfig.Mail.From = cfgIni.Section("mail").Key("from").String()
@@ -79,7 +150,48 @@ func LoadInit(fileName, extension, folderPath string) (*Init, error) {
 	initConfig.Mail.Host = cfgIni.Section("mail").Key("host").String()
 	initConfig.Mail.Port = cfgIni.Section("mail").Key("port").String()
 
+	// Notify section
+	initConfig.Notify.Window = cfgIni.Section("notify").Key("window").MustDuration(5 * time.Minute)
+	initConfig.Notify.Telegram.NotifyRoute = notifyRoute(cfgIni.Section("notify.telegram"), true)
+	initConfig.Notify.Telegram.BaseURL = cfgIni.Section("notify.telegram").Key("base_url").MustString("https://api.telegram.org")
+	initConfig.Notify.Slack = notifyRoute(cfgIni.Section("notify.slack"), false)
+	initConfig.Notify.Webhook.NotifyRoute = notifyRoute(cfgIni.Section("notify.webhook"), false)
+	initConfig.Notify.Webhook.URL = cfgIni.Section("notify.webhook").Key("url").String()
+	initConfig.Notify.Email.NotifyRoute = notifyRoute(cfgIni.Section("notify.email"), false)
+	initConfig.Notify.Email.To = cfgIni.Section("notify.email").Key("to").Strings(",")
+	initConfig.Notify.Email.Template = cfgIni.Section("notify.email").Key("template").MustString("./public/views/notification.html")
+
+	// Ops section
+	if allowedChats := cfgIni.Section("ops").Key("allowed_chats"); allowedChats.String() != "" {
+		initConfig.Ops.AllowedChats, err = allowedChats.StrictInt64s(",")
+		if err != nil {
+			return nil, fmt.Errorf("invalid ops allowed_chats: %w", err)
+		}
+	}
+	initConfig.Ops.PollTimeout = cfgIni.Section("ops").Key("poll_timeout").MustDuration(30 * time.Second)
+
+	// Shutdown section
+	initConfig.Shutdown.DrainTimeout = cfgIni.Section("shutdown").Key("drain_timeout").MustDuration(15 * time.Second)
+	initConfig.Shutdown.Timeout = cfgIni.Section("shutdown").Key("timeout").MustDuration(30 * time.Second)
+
+	// Health section
+	initConfig.Health.CacheTTL = cfgIni.Section("health").Key("cache_ttl").MustDuration(5 * time.Second)
+	initConfig.Health.Timeout = cfgIni.Section("health").Key("timeout").MustDuration(2 * time.Second)
+
+	// Migrations section
+	initConfig.Migrations.Auto = cfgIni.Section("migrations").Key("auto").MustBool(true)
+	initConfig.Migrations.LockTTL = cfgIni.Section("migrations").Key("lock_ttl").MustDuration(10 * time.Minute)
+
+	// Tracing section
+	initConfig.Tracing.Endpoint = cfgIni.Section("tracing").Key("endpoint").MustString("localhost:4318")
+	initConfig.Tracing.Insecure = cfgIni.Section("tracing").Key("insecure").MustBool()
+	initConfig.Tracing.SampleRatio = cfgIni.Section("tracing").Key("sample_ratio").MustFloat64(1)
+	initConfig.Tracing.ServiceName = cfgIni.Section("tracing").Key("service_name").MustString("project-wraith")
+
 	// Options section
+	initConfig.Options.NotifyErrors = cfgIni.Section("options").Key("notify_errors").MustBool()
+	initConfig.Options.OpsBot = cfgIni.Section("options").Key("ops_bot").MustBool()
+	initConfig.Options.Tracing = cfgIni.Section("options").Key("tracing").MustBool()
 	initConfig.Options.EncryptResponse = cfgIni.Section("options").Key("encrypt_response").MustBool()
 	initConfig.Options.EncryptDbData = cfgIni.Section("options").Key("encrypt_db_data").MustBool()
 	initConfig.Options.EncryptLogs = cfgIni.Section("options").Key("encrypt_logs").MustBool()
@@ -88,3 +200,20 @@ func LoadInit(fileName, extension, folderPath string) (*Init, error) {
 
 	return &initConfig, nil
 }
+
+func notifyRoute(section *ini.Section, enabled bool) NotifyRoute {
+	return NotifyRoute{
+		Enabled:    section.Key("enabled").MustBool(enabled),
+		Severity:   section.Key("severity").MustString("error"),
+		Categories: section.Key("categories").Strings(","),
+	}
+}
+
+// key is the section key name, or legacy when only that spelling is set.
+func key(section *ini.Section, name, legacy string) *ini.Key {
+	if !section.HasKey(name) && section.HasKey(legacy) {
+		return section.Key(legacy)
+	}
+
+	return section.Key(name)
+}
@@ -5,30 +5,41 @@ import (
 	"github.com/joho/godotenv"
 	"os"
 	"path/filepath"
+	"reflect"
 )
 
 type Secrets struct {
 	Server struct {
-		KeyWord string
+		KeyWord string `env:"SERVER_KEY_WORD"`
 	}
 	Keys struct {
-		Jwt       string
-		DbData    string
-		Response  string
-		Password  string
-		Cookies   string
-		Internals string
-		Logs      string
+		Jwt       string `env:"SECRET_JWT"`
+		DbData    string `env:"SECRET_DB"`
+		Response  string `env:"SECRET_RESPONSE"`
+		Password  string `env:"SECRET_PASSWORD"`
+		Cookies   string `env:"SECRET_COOKIES"`
+		Internals string `env:"SECRET_INTERNALS"`
+		Logs      string `env:"SECRET_LOGS"`
+		Hybrid    string `env:"SECRET_HYBRID"`
 	}
 	Storage struct {
-		AccessKey string
-		SecretKey string
+		AccessKey string `env:"STORAGE_ACCESS_KEY"`
+		SecretKey string `env:"STORAGE_SECRET_KEY"`
 	}
 	Notifiers struct {
 		Bot struct {
-			Token string
-			Chat  string
+			Token string `env:"NOTIFIER_TLG_BOT_TOKEN"`
+			Chat  string `env:"NOTIFIER_TLG_BOT_CHAT"`
 		}
+		Slack struct {
+			Webhook string `env:"NOTIFIER_SLACK_WEBHOOK"`
+		}
+		Webhook struct {
+			Secret string `env:"NOTIFIER_WEBHOOK_SECRET"`
+		}
+	}
+	Metrics struct {
+		ScrapeToken string `env:"METRICS_SCRAPE_TOKEN"`
 	}
 }
 
@@ -42,17 +53,22 @@ func LoadSecrets(fileName, extension, folderPath string) (*Secrets, error) {
 	}
 
 	var secrets Secrets
-
-	secrets.Server.KeyWord = os.Getenv("SERVER_KEY_WORD")
-	secrets.Keys.Jwt = os.Getenv("SECRET_JWT")
-	secrets.Keys.DbData = os.Getenv("SECRET_DB")
-	secrets.Keys.Response = os.Getenv("SECRET_RESPONSE")
-	secrets.Keys.Password = os.Getenv("SECRET_PASSWORD")
-	secrets.Keys.Cookies = os.Getenv("SECRET_COOKIES")
-	secrets.Keys.Internals = os.Getenv("SECRET_INTERNALS")
-	secrets.Keys.Logs = os.Getenv("SECRET_LOGS")
-	secrets.Notifiers.Bot.Token = os.Getenv("NOTIFIER_TLG_BOT_TOKEN")
-	secrets.Notifiers.Bot.Chat = os.Getenv("NOTIFIER_TLG_BOT_CHAT")
+	fromEnv(reflect.ValueOf(&secrets).Elem())
 
 	return &secrets, nil
 }
+
+// fromEnv fills the fields of v tagged env from the environment.
+func fromEnv(v reflect.Value) {
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Type().Field(i)
+		if field.Type.Kind() == reflect.Struct {
+			fromEnv(v.Field(i))
+			continue
+		}
+
+		if name := field.Tag.Get("env"); name != "" {
+			v.Field(i).SetString(os.Getenv(name))
+		}
+	}
+}
@@ -1,20 +1,35 @@
 package config
 
-import "github.com/spf13/viper"
+import (
+	"github.com/spf13/viper"
+	"time"
+)
 
 type Setup struct {
 	Server struct {
 		Host               string
 		Port               int
 		BasePath           string
-		CookiesMinutesLife int
+		RequestTimeout     time.Duration
+		CookiesMinutesLife int      `reload:"true"`
+		CorsOrigins        []string `reload:"true"`
 	}
 	Logger struct {
 		Debug      bool
+		Level      string `reload:"true"`
 		FolderPath string
+		MaxSizeMB  int
+		MaxBackups int
+		MaxAgeDays int
+		Daily      bool
+		Compress   bool
+		Redact     struct {
+			Fields []string
+			Allow  []string
+		}
 	}
 	Redirects struct {
-		ResetUrl string
+		ResetUrl string `reload:"true"`
 	}
 }
 
@@ -24,6 +39,13 @@ func LoadSetup(fileName, extension, folderPath string) (*Setup, error) {
 	snake.SetConfigType(extension)
 	snake.AddConfigPath(folderPath)
 
+	snake.SetDefault("server.host", "localhost")
+	snake.SetDefault("server.port", 8080)
+	snake.SetDefault("server.requestTimeout", 30*time.Second)
+	snake.SetDefault("server.cookiesMinutesLife", 15)
+	snake.SetDefault("server.corsOrigins", []string{"*"})
+	snake.SetDefault("logger.folderPath", "./logs")
+
 	if err := snake.ReadInConfig(); err != nil {
 		return nil, err
 	}
@@ -33,5 +55,12 @@ func LoadSetup(fileName, extension, folderPath string) (*Setup, error) {
 		return nil, err
 	}
 
+	if config.Logger.Level == "" {
+		config.Logger.Level = "info"
+		if config.Logger.Debug {
+			config.Logger.Level = "debug"
+		}
+	}
+
 	return &config, nil
 }
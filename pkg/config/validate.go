@@ -0,0 +1,169 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+type problems []error
+
+func (p *problems) add(key, format string, args ...interface{}) {
+	*p = append(*p, fmt.Errorf("%s: "+format, append([]interface{}{key}, args...)...))
+}
+
+func (p *problems) required(key, value string) {
+	if value == "" {
+		p.add(key, "is required")
+	}
+}
+
+func (p *problems) port(key string, port int) {
+	if port < 1 || port > 65535 {
+		p.add(key, "must be between 1 and 65535, got %d", port)
+	}
+}
+
+func (p *problems) positive(key string, d time.Duration) {
+	if d <= 0 {
+		p.add(key, "must be a positive duration, got %s", d)
+	}
+}
+
+func (p *problems) atLeast(key string, n, min int) {
+	if n < min {
+		p.add(key, "must be at least %d, got %d", min, n)
+	}
+}
+
+// url checks value is an absolute URL with one of schemes.
+func (p *problems) url(key, value string, schemes ...string) {
+	if value == "" {
+		p.add(key, "is required")
+		return
+	}
+
+	u, err := url.Parse(value)
+	if err != nil || u.Host == "" {
+		p.add(key, "must be an absolute URL")
+		return
+	}
+
+	for _, scheme := range schemes {
+		if u.Scheme == scheme {
+			return
+		}
+	}
+	p.add(key, "must use one of the schemes %v, got %q", schemes, u.Scheme)
+}
+
+func (c *Config) validate() []error {
+	var p problems
+	setup, ini, sct := c.Setup, c.Init, c.Secrets
+
+	p.required("server.host", setup.Server.Host)
+	p.port("server.port", setup.Server.Port)
+	p.positive("server.request_timeout", setup.Server.RequestTimeout)
+	p.atLeast("server.cookies_minutes_life", setup.Server.CookiesMinutesLife, 1)
+	for _, origin := range setup.Server.CorsOrigins {
+		if origin != "*" {
+			p.url("server.cors_origins", origin, "http", "https")
+		}
+	}
+	switch setup.Logger.Level {
+	case "debug", "info", "warn", "error":
+	default:
+		p.add("logger.level", "must be debug, info, warn or error, got %q", setup.Logger.Level)
+	}
+	p.required("logger.folder_path", setup.Logger.FolderPath)
+	p.atLeast("logger.max_size_mb", setup.Logger.MaxSizeMB, 0)
+	p.atLeast("logger.max_backups", setup.Logger.MaxBackups, 0)
+	p.atLeast("logger.max_age_days", setup.Logger.MaxAgeDays, 0)
+	p.url("redirects.reset_url", setup.Redirects.ResetUrl, "http", "https")
+
+	p.positive("database.timeout", ini.Database.Timeout)
+	p.url("database.user.uri", ini.Database.User.Uri, "mongodb", "mongodb+srv")
+	p.required("database.user.name", ini.Database.User.Name)
+	p.url("database.manager.uri", ini.Database.Manager.Uri, "mongodb", "mongodb+srv")
+	p.required("database.manager.name", ini.Database.Manager.Name)
+	p.url("database.license.uri", ini.Database.License.Uri, "mongodb", "mongodb+srv")
+	p.required("database.license.name", ini.Database.License.Name)
+
+	p.required("sms.reset_asset", ini.Sms.ResetAsset)
+	p.required("mail.from", ini.Mail.From)
+	p.required("mail.host", ini.Mail.Host)
+	if port, err := strconv.Atoi(ini.Mail.Port); err != nil {
+		p.add("mail.port", "must be a number, got %q", ini.Mail.Port)
+	} else {
+		p.port("mail.port", port)
+	}
+
+	p.positive("shutdown.drain_timeout", ini.Shutdown.DrainTimeout)
+	if ini.Shutdown.Timeout < ini.Shutdown.DrainTimeout {
+		p.add("shutdown.timeout", "must be at least shutdown.drain_timeout (%s), got %s",
+			ini.Shutdown.DrainTimeout, ini.Shutdown.Timeout)
+	}
+	p.positive("health.timeout", ini.Health.Timeout)
+	p.positive("migrations.lock_ttl", ini.Migrations.LockTTL)
+	if ini.Health.CacheTTL < 0 {
+		p.add("health.cache_ttl", "must not be negative, got %s", ini.Health.CacheTTL)
+	}
+
+	if ini.Options.UploadLogs {
+		switch ini.Storage.Backend {
+		case "s3":
+			p.required("storage.bucket", ini.Storage.Bucket)
+			p.url("storage.endpoint", ini.Storage.Endpoint, "http", "https")
+			p.required(c.describe("storage.access_key"), sct.Storage.AccessKey)
+			p.required(c.describe("storage.secret_key"), sct.Storage.SecretKey)
+		case "local":
+			p.required("storage.local_path", ini.Storage.LocalPath)
+		default:
+			p.add("storage.backend", "must be s3 or local, got %q", ini.Storage.Backend)
+		}
+		p.positive("storage.ship_interval", ini.Storage.ShipInterval)
+	}
+
+	if ini.Options.NotifyErrors {
+		p.positive("notify.window", ini.Notify.Window)
+		if ini.Notify.Telegram.Enabled {
+			p.url("notify.telegram.base_url", ini.Notify.Telegram.BaseURL, "http", "https")
+		}
+		if ini.Notify.Webhook.Enabled {
+			p.url("notify.webhook.url", ini.Notify.Webhook.URL, "http", "https")
+		}
+		if ini.Notify.Email.Enabled && len(ini.Notify.Email.To) == 0 {
+			p.add("notify.email.to", "is required")
+		}
+	}
+
+	if ini.Options.OpsBot {
+		p.positive("ops.poll_timeout", ini.Ops.PollTimeout)
+	}
+
+	if ini.Options.Tracing {
+		p.required("tracing.endpoint", ini.Tracing.Endpoint)
+		p.required("tracing.service_name", ini.Tracing.ServiceName)
+		if ini.Tracing.SampleRatio < 0 || ini.Tracing.SampleRatio > 1 {
+			p.add("tracing.sample_ratio", "must be between 0 and 1, got %g", ini.Tracing.SampleRatio)
+		}
+	}
+
+	p.required(c.describe("server.key_word"), sct.Server.KeyWord)
+	p.required(c.describe("keys.jwt"), sct.Keys.Jwt)
+	p.required(c.describe("keys.password"), sct.Keys.Password)
+	p.required(c.describe("keys.cookies"), sct.Keys.Cookies)
+	p.required(c.describe("keys.internals"), sct.Keys.Internals)
+	if ini.Options.EncryptDbData {
+		p.required(c.describe("keys.db_data"), sct.Keys.DbData)
+	}
+	if ini.Options.EncryptResponse {
+		p.required(c.describe("keys.response"), sct.Keys.Response)
+	}
+	if ini.Options.EncryptLogs {
+		p.required(c.describe("keys.logs"), sct.Keys.Logs)
+	}
+
+	return p
+}
@@ -0,0 +1,199 @@
+package config
+
+import (
+	"fmt"
+	"path/filepath"
+	"project-wraith/pkg/modules/logger"
+	"reflect"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// reloadDelay lets an editor finish writing before the files are read.
+const reloadDelay = 200 * time.Millisecond
+
+// Subscriber applies a reloaded configuration. An error rejects the reload.
+type Subscriber func(conf *Config) error
+
+// Watcher reloads the setup and init files when they change. Only the keys
+// tagged reload take effect, and only when every subscriber accepts them;
+// otherwise the subscribers that did are given the current configuration
+// back. Other changed keys are reported as needing a restart.
+type Watcher interface {
+	Current() *Config
+	Subscribe(name string, apply Subscriber)
+	Reload() error
+	Run(stop <-chan struct{})
+}
+
+type subscription struct {
+	name  string
+	apply Subscriber
+}
+
+type watcher struct {
+	log       logger.Logger
+	setup     File
+	init      File
+	secrets   File
+	overrides []string
+	events    *fsnotify.Watcher
+
+	current     atomic.Pointer[Config]
+	mu          sync.Mutex
+	subscribers []subscription
+}
+
+// NewWatcher is a function constructor for Watcher. Reloads read the files
+// with the same overrides Load was given.
+func NewWatcher(log logger.Logger, conf *Config, setup, init, secrets File, overrides []string) (Watcher, error) {
+	events, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, fmt.Errorf("failed to watch configuration: %w", err)
+	}
+
+	// Editors often replace a file instead of writing it, so the folders are
+	// watched rather than the files.
+	folders := map[string]bool{}
+	for _, file := range []File{setup, init} {
+		folder := filepath.Clean(file.Path)
+		if folders[folder] {
+			continue
+		}
+		folders[folder] = true
+
+		if err := events.Add(folder); err != nil {
+			_ = events.Close()
+			return nil, fmt.Errorf("failed to watch configuration: %w", err)
+		}
+	}
+
+	w := &watcher{
+		log:       log,
+		setup:     setup,
+		init:      init,
+		secrets:   secrets,
+		overrides: overrides,
+		events:    events,
+	}
+	w.current.Store(conf)
+
+	return w, nil
+}
+
+func (w *watcher) Current() *Config {
+	return w.current.Load()
+}
+
+func (w *watcher) Subscribe(name string, apply Subscriber) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.subscribers = append(w.subscribers, subscription{name: name, apply: apply})
+}
+
+func (w *watcher) Reload() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	loaded, err := Load(w.setup, w.init, w.secrets, w.overrides)
+	if err != nil {
+		w.log.Error("configuration reload rejected, keeping the current one: %v", err)
+		return err
+	}
+
+	current := w.current.Load()
+	next, changed, restart := current.merge(loaded)
+	for _, key := range restart {
+		w.log.Warn("configuration key %s changed, restart to apply it", key)
+	}
+
+	if len(changed) == 0 {
+		return nil
+	}
+
+	for i, subscriber := range w.subscribers {
+		if err := subscriber.apply(next); err != nil {
+			w.rollback(current, w.subscribers[:i])
+			w.log.Error("configuration reload rejected by %s, keeping the current one: %v", subscriber.name, err)
+			return fmt.Errorf("%s: %w", subscriber.name, err)
+		}
+	}
+
+	w.current.Store(next)
+	w.log.Info("configuration reloaded: %s", strings.Join(changed, ", "))
+	return nil
+}
+
+func (w *watcher) rollback(current *Config, applied []subscription) {
+	for i := len(applied) - 1; i >= 0; i-- {
+		if err := applied[i].apply(current); err != nil {
+			w.log.Error("failed to restore the configuration of %s: %v", applied[i].name, err)
+		}
+	}
+}
+
+// Run reloads after the watched files settle, until stop is closed.
+func (w *watcher) Run(stop <-chan struct{}) {
+	defer w.events.Close()
+
+	files := map[string]bool{
+		filepath.Join(w.setup.Path, w.setup.Name+"."+w.setup.Extension): true,
+		filepath.Join(w.init.Path, w.init.Name+"."+w.init.Extension):    true,
+	}
+
+	timer := time.NewTimer(reloadDelay)
+	timer.Stop()
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case event, ok := <-w.events.Events:
+			if !ok {
+				return
+			}
+			if files[filepath.Clean(event.Name)] && !event.Has(fsnotify.Chmod) {
+				timer.Reset(reloadDelay)
+			}
+		case err, ok := <-w.events.Errors:
+			if !ok {
+				return
+			}
+			w.log.Warn("configuration watcher: %v", err)
+		case <-timer.C:
+			_ = w.Reload()
+		}
+	}
+}
+
+// merge returns a copy of c with the reloadable values of loaded, the keys
+// of the values it took and the keys of the other values that differ.
+func (c *Config) merge(loaded *Config) (*Config, []string, []string) {
+	setup, init := *c.Setup, *c.Init
+	next := &Config{Setup: &setup, Init: &init, Secrets: c.Secrets}
+
+	var changed, restart []string
+	loadedLeaves := loaded.leaves()
+	for i, leaf := range next.leaves() {
+		value := loadedLeaves[i].value
+		if reflect.DeepEqual(leaf.value.Interface(), value.Interface()) {
+			continue
+		}
+
+		if !leaf.reload {
+			restart = append(restart, leaf.key)
+			continue
+		}
+
+		leaf.value.Set(value)
+		changed = append(changed, leaf.key)
+	}
+
+	return next, changed, restart
+}
@@ -0,0 +1,131 @@
+package config_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"project-wraith/pkg/config"
+	"project-wraith/pkg/modules/logger"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func newWatcher(t *testing.T) (config.Watcher, config.File, *logger.MockLogger) {
+	setup, init, secrets := writeConfig(t, testInit)
+
+	conf, err := config.Load(setup, init, secrets, nil)
+	require.NoError(t, err)
+
+	logMock := &logger.MockLogger{}
+	logMock.On("Info", mock.Anything).Return(nil)
+	logMock.On("Warn", mock.Anything).Return(nil)
+	logMock.On("Error", mock.Anything).Return(nil)
+
+	watcher, err := config.NewWatcher(logMock, conf, setup, init, secrets, nil)
+	require.NoError(t, err)
+
+	return watcher, setup, logMock
+}
+
+func writeSetup(t *testing.T, setup config.File, replacements ...string) {
+	content := strings.NewReplacer(replacements...).Replace(testSetup)
+	path := filepath.Join(setup.Path, "config.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+}
+
+func TestReload(t *testing.T) {
+	tests := []struct {
+		name             string
+		replacements     []string
+		rejectBy         string
+		expectedError    bool
+		expectedApplied  []string
+		expectedPort     int
+		expectedResetUrl string
+	}{
+		{
+			name:             "Test Reload - Applied",
+			replacements:     []string{"port: 8080", "port: 9090\n  cookiesMinutesLife: 30", "example.com/reset", "example.org/reset"},
+			expectedApplied:  []string{"first:https://example.org/reset", "second:https://example.org/reset"},
+			expectedPort:     8080,
+			expectedResetUrl: "https://example.org/reset",
+		},
+		{
+			name:             "Test Reload - Invalid file",
+			replacements:     []string{"https://example.com/reset", "not a url"},
+			expectedError:    true,
+			expectedPort:     8080,
+			expectedResetUrl: "https://example.com/reset",
+		},
+		{
+			name:          "Test Reload - Rejected by subscriber",
+			replacements:  []string{"example.com/reset", "example.org/reset"},
+			rejectBy:      "second",
+			expectedError: true,
+			expectedApplied: []string{
+				"first:https://example.org/reset",
+				"first:https://example.com/reset",
+			},
+			expectedPort:     8080,
+			expectedResetUrl: "https://example.com/reset",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			watcher, setup, _ := newWatcher(t)
+
+			var applied []string
+			for _, name := range []string{"first", "second"} {
+				name := name
+				watcher.Subscribe(name, func(conf *config.Config) error {
+					if name == tc.rejectBy {
+						return errors.New("rejected")
+					}
+					applied = append(applied, name+":"+conf.Setup.Redirects.ResetUrl)
+					return nil
+				})
+			}
+
+			writeSetup(t, setup, tc.replacements...)
+			err := watcher.Reload()
+			if tc.expectedError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+
+			assert.Equal(t, tc.expectedApplied, applied)
+			assert.Equal(t, tc.expectedPort, watcher.Current().Setup.Server.Port, "restart only")
+			assert.Equal(t, tc.expectedResetUrl, watcher.Current().Setup.Redirects.ResetUrl)
+		})
+	}
+}
+
+func TestWatch(t *testing.T) {
+	watcher, setup, _ := newWatcher(t)
+
+	reloaded := make(chan string, 1)
+	watcher.Subscribe("test", func(conf *config.Config) error {
+		reloaded <- conf.Setup.Logger.Level
+		return nil
+	})
+
+	stop := make(chan struct{})
+	defer close(stop)
+	go watcher.Run(stop)
+
+	writeSetup(t, setup, "redirects:", "logger:\n  level: debug\nredirects:")
+
+	select {
+	case level := <-reloaded:
+		assert.Equal(t, "debug", level)
+	case <-time.After(3 * time.Second):
+		t.Fatal("configuration was not reloaded")
+	}
+}
@@ -1,7 +1,12 @@
 package consts
 
 const (
-	LicensesCollection  = "licenses"
-	UsersCollection     = "users"
-	InternalsCollection = "internals"
+	LicensesCollection   = "licenses"
+	UsersCollection      = "users"
+	InternalsCollection  = "internals"
+	MigrationsCollection = "migrations"
+	ClientsCollection    = "clients"
+
+	SchemaMigrationsCollection = "schema_migrations"
+	SchemaLocksCollection      = "schema_locks"
 )
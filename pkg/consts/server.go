@@ -3,6 +3,10 @@ package consts
 const (
 	LicenseCheck      = false // Change to false if you don't want to check for licenses during development
 	LoggerCallerLevel = 2
+	LogReportLimit    = 500
 	ServerName        = "project-wraith"
 	ServerHeader      = "dall-project-wraith"
+	RequestIDLocal    = "requestId"
+	SessionLocal      = "user"    // Where the jwt middleware keeps the validated session token
+	ScraperLocal      = "scraper" // Set when the request carries the metrics scrape token
 )
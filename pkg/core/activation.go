@@ -1,28 +1,31 @@
 package core
 
 import (
+	"context"
 	"errors"
 	"project-wraith/pkg/modules/lics"
 	"time"
 )
 
-func Activate(repo lics.LicenseRepository, licenseKey string) error {
+var ErrLicenseInvalid = errors.New("license is either inactive or expired")
+
+func Activate(ctx context.Context, repo lics.LicenseRepository, licenseKey string) error {
 	lic := &lics.License{
 		LicenseKey: licenseKey,
 	}
 
-	result, err := repo.Get(*lic)
+	result, err := repo.Get(ctx, *lic)
 	if err != nil {
 		return err
 	}
 
 	// Check if result is nil
 	if result == nil {
-		return errors.New("license not found")
+		return lics.ErrNotFound
 	}
 
 	if !result.IsActive || result.ExpiryDate.Before(time.Now()) {
-		return errors.New("license is either inactive or expired")
+		return ErrLicenseInvalid
 	}
 
 	return nil
@@ -1,14 +1,19 @@
 package core_test
 
 import (
+	"context"
 	"errors"
 	"testing"
 	"time"
 
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 	"project-wraith/pkg/core" // Update import according to your project's structure
+	"project-wraith/pkg/modules/db"
 	"project-wraith/pkg/modules/lics"
+	"project-wraith/pkg/modules/metrics"
 )
 
 func TestActivate(t *testing.T) {
@@ -77,13 +82,13 @@ func TestActivate(t *testing.T) {
 
 			// Setup the expected calls and return values
 			if tc.mockLicense != nil {
-				repo.On("Get", mock.Anything).Return(tc.mockLicense, nil)
+				repo.On("Get", mock.Anything, mock.Anything).Return(tc.mockLicense, nil)
 			} else {
 				// Return nil license and an error for the "not found" scenario
-				repo.On("Get", mock.Anything).Return((*lics.License)(nil), errors.New("license not found"))
+				repo.On("Get", mock.Anything, mock.Anything).Return((*lics.License)(nil), errors.New("license not found"))
 			}
 
-			err := core.Activate(repo, tc.licenseKey)
+			err := core.Activate(context.Background(), repo, tc.licenseKey)
 
 			// Check the error against the expected error
 			if tc.expectedErr != nil {
@@ -97,3 +102,28 @@ func TestActivate(t *testing.T) {
 		})
 	}
 }
+
+func TestLicenseCheck(t *testing.T) {
+	license := &lics.License{LicenseKey: "key", IsActive: true, ExpiryDate: time.Now().Add(time.Hour)}
+
+	repo := new(lics.MockLicenseRepository)
+	check := core.LicenseCheck(repo, "key")
+	active := func() float64 {
+		return testutil.ToFloat64(metrics.LicenseStatus.WithLabelValues(metrics.LicenseActive))
+	}
+
+	repo.On("Get", mock.Anything, mock.Anything).Return(license, nil).Once()
+	require.NoError(t, check(context.Background()))
+	assert.Equal(t, 1.0, active())
+
+	repo.On("Get", mock.Anything, mock.Anything).Return((*lics.License)(nil), db.ErrTimeout).Once()
+	assert.Error(t, check(context.Background()))
+	assert.Equal(t, 1.0, active())
+
+	expired := *license
+	expired.ExpiryDate = time.Now().Add(-time.Hour)
+	repo.On("Get", mock.Anything, mock.Anything).Return(&expired, nil).Once()
+	assert.ErrorIs(t, check(context.Background()), core.ErrLicenseInvalid)
+	assert.Equal(t, 0.0, active())
+	assert.Equal(t, 1.0, testutil.ToFloat64(metrics.LicenseStatus.WithLabelValues(metrics.LicenseInvalid)))
+}
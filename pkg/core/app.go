@@ -1,6 +1,8 @@
 package core
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/template/html/v2"
@@ -12,53 +14,134 @@ import (
 	"project-wraith/pkg/modules/apikey"
 	"project-wraith/pkg/modules/db"
 	"project-wraith/pkg/modules/guard"
+	"project-wraith/pkg/modules/health"
 	"project-wraith/pkg/modules/lics"
+	"project-wraith/pkg/modules/lifecycle"
 	"project-wraith/pkg/modules/link"
 	"project-wraith/pkg/modules/logger"
 	"project-wraith/pkg/modules/mail"
+	"project-wraith/pkg/modules/metrics"
+	"project-wraith/pkg/modules/notifier"
+	"project-wraith/pkg/modules/shipper"
 	"project-wraith/pkg/modules/sms"
 	"project-wraith/pkg/modules/storage"
 	"project-wraith/pkg/modules/tools"
+	"time"
 )
 
-func Start(cfg *config.Setup, sct *config.Secrets, ini *config.Init, log logger.Logger) error {
-	userDbClient := db.NewClient(ini.Database.User.Uri, ini.Database.User.Name)
-	err := userDbClient.Open()
-	if err != nil {
-		log.Error("failed to open db client", err)
-		return err
+// Start wires the server and serves until SIGINT or SIGTERM, then drains the
+// in-flight requests and stops every subsystem in the reverse order it was
+// started, shipping the logs one last time before the databases close.
+func Start(watcher config.Watcher, log logger.Logger) (err error) {
+	started := time.Now()
+	conf := watcher.Current()
+	cfg, ini, sct := conf.Setup, conf.Init, conf.Secrets
+	ctx := context.Background()
+
+	lc := lifecycle.NewLifecycle(log, ini.Shutdown.Timeout)
+	defer func() {
+		if stopErr := lc.Stop(); stopErr != nil {
+			err = errors.Join(err, stopErr)
+			return
+		}
+		log.Info("shutdown complete")
+	}()
+
+	if ini.Options.Tracing {
+		err = lc.Start(ctx, TracingHook(ini, log))
+		if err != nil {
+			log.Error("failed to set up tracing: %v", err)
+			return err
+		}
 	}
 
-	licenseDbClient := db.NewClient(ini.Database.License.Uri, ini.Database.License.Name)
-	err = licenseDbClient.Open()
-	if err != nil {
-		log.Error("failed to open db client", err)
-		return err
+	mailer := mail.NewMail(
+		ini.Mail.From,
+		ini.Mail.Password,
+		ini.Mail.Host,
+		ini.Mail.Port)
+
+	if ini.Options.NotifyErrors {
+		notifiers, err := Notifiers(sct, ini, mailer)
+		if err != nil {
+			log.Error("invalid notify configuration: %v", err)
+			return err
+		}
+
+		relay := notifier.NewRelay(log, notifiers, ini.Notify.Window)
+		err = log.AddHook("error", relay.Hook)
+		if err != nil {
+			return err
+		}
+
+		err = lc.Start(ctx, lifecycle.Background("notifier relay", relay.Run))
+		if err != nil {
+			return err
+		}
 	}
 
+	userDbClient := db.NewClient(ini.Database.User.Uri, ini.Database.User.Name)
+	licenseDbClient := db.NewClient(ini.Database.License.Uri, ini.Database.License.Name)
 	managerDbClient := db.NewClient(ini.Database.Manager.Uri, ini.Database.Manager.Name)
-	err = managerDbClient.Open()
-	if err != nil {
-		log.Error("failed to open db client", err)
-		return err
+
+	databases := map[string]db.Client{
+		"user":    userDbClient,
+		"manager": managerDbClient,
+		"license": licenseDbClient,
+	}
+	for _, name := range []string{"user", "license", "manager"} {
+		err = lc.Start(ctx, DatabaseHook(name, databases[name]))
+		if err != nil {
+			log.Error("failed to open db client: %v", err)
+			return err
+		}
+	}
+
+	if ini.Migrations.Auto {
+		err = MigrateOnStart(ctx, ini, log, databases)
+		if err != nil {
+			log.Error("failed to apply migrations: %v", err)
+			return err
+		}
+	}
+
+	readiness := health.NewChecker(log, ini.Health.CacheTTL, ini.Health.Timeout)
+	for name, client := range databases {
+		readiness.Add("db."+name, DatabaseCheck(client))
+	}
+	readiness.Add("smtp", MailCheck(mailer))
+
+	if ini.Options.UploadLogs {
+		var objectStorage storage.Storage
+		objectStorage, err = ObjectStorage(sct, ini)
+		if err != nil {
+			log.Error("failed to create object storage: %v", err)
+			return err
+		}
+		readiness.Add("storage", StorageCheck(objectStorage, ini.Storage.Bucket))
 	}
 
 	if ini.Options.UseLicense {
-		licString, err := config.LoadLicense(consts.LicFileName, consts.LicExtension, consts.LicPath)
+		var licString string
+		licString, err = config.LoadLicense(consts.LicFileName, consts.LicExtension, consts.LicPath)
 		if err != nil {
 			log.Error("failed to load license", err)
 			return err
 		}
 
 		licensesCollection := licenseDbClient.Collection(consts.LicensesCollection)
-		licensesCtx := licenseDbClient.Ctx()
-
-		licensesRepo := lics.NewLicenseRepository(*licensesCollection, licensesCtx)
-		err = Activate(licensesRepo, licString)
+		licensesRepo := lics.NewLicenseRepository(*licensesCollection, ini.Database.Timeout)
+		err = Activate(ctx, licensesRepo, licString)
 		if err != nil {
+			metrics.License(metrics.LicenseInvalid)
 			log.Error("failed to activate license", err)
 			return err
 		}
+		metrics.License(metrics.LicenseActive)
+
+		readiness.Add("license", LicenseCheck(licensesRepo, licString))
+	} else {
+		metrics.License(metrics.LicenseUnused)
 	}
 
 	resetSmsAsset, err := tools.ReadAsset(ini.Sms.ResetAsset)
@@ -67,11 +150,6 @@ func Start(cfg *config.Setup, sct *config.Secrets, ini *config.Init, log logger.
 		return err
 	}
 
-	mailer := mail.NewMail(
-		ini.Mail.From,
-		ini.Mail.Password,
-		ini.Mail.Host,
-		ini.Mail.Port)
 	smsResetSender := sms.NewTwilio(
 		ini.Sms.From,
 		ini.Sms.AccountSID,
@@ -80,9 +158,12 @@ func Start(cfg *config.Setup, sct *config.Secrets, ini *config.Init, log logger.
 	)
 
 	userCollection := userDbClient.Collection(consts.UsersCollection)
-	userCtx := userDbClient.Ctx()
+	userRepo := domain.NewUserRepository(*userCollection, ini.Database.Timeout)
 
-	userRepo := domain.NewUserRepository(*userCollection, userCtx)
+	clientsCollection := managerDbClient.Collection(consts.ClientsCollection)
+	clientRepo := domain.NewClientRepository(*clientsCollection, ini.Database.Timeout)
+	clientRule := rules.NewClientRule(clientRepo)
+	clientCtrl := gateway.NewClientController(log, clientRule)
 
 	userRule := rules.NewUserRule(
 		userRepo, ini.Options.EncryptDbData, sct.Keys.DbData, sct.Keys.Password)
@@ -90,15 +171,14 @@ func Start(cfg *config.Setup, sct *config.Secrets, ini *config.Init, log logger.
 		log,
 		userRule,
 		sct.Keys.Jwt,
-		ini.Options.EncryptResponse,
-		sct.Keys.Response,
 		cfg.Server.CookiesMinutesLife)
 
+	settings := gateway.NewSettings(cfg.Server.CookiesMinutesLife, cfg.Redirects.ResetUrl)
 	authCtrl := gateway.NewAuthController(
 		log,
 		userRule,
 		sct.Keys.Jwt,
-		cfg.Server.CookiesMinutesLife)
+		settings)
 
 	resetRule := rules.NewResetRule(userRepo, sct.Keys.Jwt)
 	resetCtrl := gateway.NewResetController(
@@ -109,18 +189,38 @@ func Start(cfg *config.Setup, sct *config.Secrets, ini *config.Init, log logger.
 		cfg.Server.CookiesMinutesLife,
 		mailer,
 		smsResetSender,
-		cfg.Redirects.ResetUrl,
+		settings,
 	)
 
 	internalsCollection := managerDbClient.Collection(consts.InternalsCollection)
-	internalsCtx := managerDbClient.Ctx()
+	manticore := guard.NewManticore(*internalsCollection, ini.Database.Timeout, sct.Keys.Internals)
 
-	manticore := guard.NewManticore(
-		*internalsCollection,
-		internalsCtx,
-		sct.Keys.Internals)
+	logsKey, err := LogsKey(sct, ini)
+	if err != nil {
+		log.Error("invalid logs configuration: %v", err)
+		return err
+	}
 
-	staticsCtrl := gateway.NewStaticsController(log, consts.AppManifest.Version, cfg.Logger.FolderPath, cfg.Server.BasePath)
+	if ini.Options.OpsBot {
+		var opsBot notifier.OpsBot
+		migrationsCollection := managerDbClient.Collection(consts.MigrationsCollection)
+		checkpoints := domain.NewCheckpointRepository(*migrationsCollection, ini.Database.Timeout)
+
+		opsBot, err = OpsBot(sct, ini, log, userRule, checkpoints, databases, cfg.Logger.FolderPath, logsKey, started)
+		if err != nil {
+			log.Error("failed to create ops bot: %v", err)
+			return err
+		}
+
+		err = lc.Start(ctx, lifecycle.Background("ops bot", opsBot.Run))
+		if err != nil {
+			return err
+		}
+	}
+
+	staticsCtrl := gateway.NewStaticsController(log, consts.AppManifest.Version, cfg.Server.BasePath)
+	logsCtrl := gateway.NewLogsController(log, cfg.Logger.FolderPath, logsKey)
+	healthCtrl := gateway.NewHealthController(readiness)
 
 	serverApiKey := apikey.CrateApiKey(sct.Server.KeyWord)
 
@@ -145,16 +245,53 @@ func Start(cfg *config.Setup, sct *config.Secrets, ini *config.Init, log logger.
 		"swagger": fmt.Sprintf("%s/swagger/*", cfg.Server.BasePath),
 		"logs":    fmt.Sprintf("%s/logs", cfg.Server.BasePath),
 		"metrics": fmt.Sprintf("%s/metrics", cfg.Server.BasePath),
+		"clients": fmt.Sprintf("%s/clients", cfg.Server.BasePath),
+		"healthz": fmt.Sprintf("%s/healthz", cfg.Server.BasePath),
+		"readyz":  fmt.Sprintf("%s/readyz", cfg.Server.BasePath),
 	}
 
+	cors := NewSwappable(CORS(cfg.Server.CorsOrigins))
+	Reloads(watcher, log, cors, settings)
+
 	Middleware(
-		fiberApp, log, paths, serverApiKey, sct.Keys.Jwt, sct.Keys.Cookies, manticore)
-	EnRoute(fiberApp, paths, userCtrl, authCtrl, resetCtrl, staticsCtrl)
+		fiberApp, log, paths, cfg.Server.RequestTimeout, serverApiKey, sct.Metrics.ScrapeToken, sct.Keys.Jwt, sct.Keys.Cookies, manticore,
+		ini.Options.EncryptResponse, sct.Keys.Response, sct.Keys.Hybrid, clientRule, cors.Handle)
+	EnRoute(fiberApp, paths, userCtrl, authCtrl, resetCtrl, staticsCtrl, logsCtrl, clientCtrl, healthCtrl)
+
+	if ini.Options.UploadLogs {
+		err = lc.Start(ctx, lifecycle.Hook{
+			Name: "log teardown",
+			Stop: func(ctx context.Context) error {
+				return Teardown(cfg, sct, ini, log)
+			},
+		})
+		if err != nil {
+			return err
+		}
+
+		var logShipper shipper.Shipper
+		logShipper, err = LogShipper(cfg, sct, ini, log)
+		if err != nil {
+			log.Error("failed to create log shipper", err)
+			return err
+		}
+
+		err = lc.Start(ctx, lifecycle.Background("log shipper", func(stop <-chan struct{}) {
+			logShipper.Run(ini.Storage.ShipInterval, stop)
+		}))
+		if err != nil {
+			return err
+		}
+	}
+
+	err = lc.Start(ctx, lifecycle.Background("config watcher", watcher.Run))
+	if err != nil {
+		return err
+	}
 
 	listenOn := fmt.Sprintf("%s:%d", cfg.Server.Host, cfg.Server.Port)
-	err = fiberApp.Listen(listenOn)
+	err = lc.Start(ctx, ServerHook(fiberApp, lc, listenOn, ini.Shutdown.DrainTimeout))
 	if err != nil {
-		log.Error("failed to start server", err)
 		return err
 	}
 
@@ -163,95 +300,62 @@ func Start(cfg *config.Setup, sct *config.Secrets, ini *config.Init, log logger.
 		cfg.Server.Host, cfg.Server.Port, cfg.Server.BasePath)
 	log.Info(startLog)
 
-	fmt.Printf(
-		"\nHelpful routes:\n"+
-			"%s:%d%s/hello\n"+
-			"%s:%d%s/metrics\n"+
-			"%s:%d%s/log\n",
-		cfg.Server.Host, cfg.Server.Port, cfg.Server.BasePath, // For /hello
-		cfg.Server.Host, cfg.Server.Port, cfg.Server.BasePath, // For /metrics
-		cfg.Server.Host, cfg.Server.Port, cfg.Server.BasePath, // For /log
-	)
-
-	log.Info("shutting down server...")
+	return lc.Wait(ctx)
+}
 
-	err = userDbClient.Close()
-	if err != nil {
-		log.Error("failed to close db client", err)
-		return err
-	}
-	err = managerDbClient.Close()
-	if err != nil {
-		log.Error("failed to close db client", err)
-		return err
-	}
-	err = licenseDbClient.Close()
-	if err != nil {
-		log.Error("failed to close db client", err)
-		return err
+// ObjectStorage picks the storage backend configured in the ini storage section.
+func ObjectStorage(sct *config.Secrets, ini *config.Init) (storage.Storage, error) {
+	switch ini.Storage.Backend {
+	case "local":
+		return storage.NewLocalStorage(ini.Storage.LocalPath), nil
+	case "s3":
+		return storage.NewObjectStorage(
+			sct.Storage.AccessKey,
+			sct.Storage.SecretKey,
+			ini.Storage.Endpoint,
+			ini.Storage.Region,
+			ini.Storage.PathStyle,
+		)
+	default:
+		return nil, fmt.Errorf("unknown storage backend %q", ini.Storage.Backend)
 	}
+}
 
-	log.Info("successfully closed db client!")
-
-	err = fiberApp.Shutdown()
+// LogShipper builds the shipper for every level file the logger writes.
+func LogShipper(cfg *config.Setup, sct *config.Secrets, ini *config.Init, log logger.Logger) (shipper.Shipper, error) {
+	objectStorage, err := ObjectStorage(sct, ini)
 	if err != nil {
-		log.Error("failed to shutdown", err)
-		return err
+		return nil, err
 	}
 
-	log.Info("successfully shutdown server!")
-
-	return nil
+	return shipper.NewShipper(
+		log,
+		objectStorage,
+		ini.Storage.Bucket,
+		ini.App.Level,
+		cfg.Logger.FolderPath,
+		log.Files(),
+		ini.Options.EncryptLogs,
+		sct.Keys.Logs,
+	), nil
 }
 
-func Teardown(cfg *config.Setup, sct *config.Secrets, ini *config.Init) error {
-	objectStorage := storage.NewObjectStorage(
-		sct.Storage.AccessKey,
-		sct.Storage.SecretKey,
-	)
-
-	type uploadInfo struct {
-		bucket     string
-		directory  string
-		filename   string
-		localPath  string
-		permission string
-		encrypt    bool
-		encryptKey string
+// Teardown ships whatever the loggers wrote since the last periodic pass.
+func Teardown(cfg *config.Setup, sct *config.Secrets, ini *config.Init, log logger.Logger) error {
+	if !ini.Options.UploadLogs {
+		return nil
 	}
 
-	var toUpload []uploadInfo
-
-	if ini.Options.UploadLogs {
-		logFiles := []string{"info.log", "warn.log", "error.log"}
-
-		for _, logFile := range logFiles {
-			directory := fmt.Sprintf("%s/logs", ini.App.Level)
-
-			toUpload = append(toUpload, uploadInfo{
-				bucket:     ini.Storage.Bucket,
-				directory:  directory,
-				filename:   logFile,
-				localPath:  fmt.Sprintf("%s/%s", cfg.Logger.FolderPath, logFile),
-				permission: "read",
-				encrypt:    ini.Options.EncryptLogs,
-				encryptKey: sct.Keys.Logs,
-			})
-		}
+	logShipper, err := LogShipper(cfg, sct, ini, log)
+	if err != nil {
+		return err
 	}
 
-	for _, upload := range toUpload {
-		_, err := objectStorage.UploadObject(
-			upload.bucket,
-			upload.directory,
-			upload.filename,
-			upload.permission,
-			upload.localPath,
-		)
-		if err != nil {
-			return err
-		}
+	report, err := logShipper.Ship()
+	if err != nil {
+		return err
 	}
 
+	log.Info("logs shipped, %s", report)
 	return nil
 }
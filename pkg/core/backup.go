@@ -0,0 +1,154 @@
+package core
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// BackupReport counts the documents written or restored per
+// database.collection.
+type BackupReport map[string]int
+
+func (br BackupReport) String() string {
+	names := make([]string, 0, len(br))
+	total := 0
+	for name, count := range br {
+		names = append(names, name)
+		total += count
+	}
+	sort.Strings(names)
+
+	parts := make([]string, len(names))
+	for i, name := range names {
+		parts[i] = fmt.Sprintf("%s: %d", name, br[name])
+	}
+
+	return fmt.Sprintf("documents: %d (%s)", total, strings.Join(parts, ", "))
+}
+
+// backupLine is one document of a backup. Document is canonical extended
+// JSON, so every BSON type survives the round trip.
+type backupLine struct {
+	Database   string          `json:"database"`
+	Collection string          `json:"collection"`
+	Document   json.RawMessage `json:"document"`
+}
+
+// Backup writes every document of every collection in databases to dst as
+// gzipped JSON lines. The lines name the database by its key in databases,
+// so a backup restores into databases with other names.
+func Backup(ctx context.Context, databases map[string]*mongo.Database, dst io.Writer) (BackupReport, error) {
+	names := make([]string, 0, len(databases))
+	for name := range databases {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	gz := gzip.NewWriter(dst)
+	encoder := json.NewEncoder(gz)
+	report := BackupReport{}
+
+	for _, name := range names {
+		database := databases[name]
+		collections, err := database.ListCollectionNames(ctx, bson.M{"name": bson.M{"$not": bson.M{"$regex": "^system\\."}}})
+		if err != nil {
+			return report, fmt.Errorf("failed to list collections of %s: %w", name, err)
+		}
+		sort.Strings(collections)
+
+		for _, collection := range collections {
+			cursor, err := database.Collection(collection).Find(ctx, bson.M{}, options.Find().SetSort(bson.M{"_id": 1}))
+			if err != nil {
+				return report, fmt.Errorf("failed to read %s.%s: %w", name, collection, err)
+			}
+
+			for cursor.Next(ctx) {
+				document, err := bson.MarshalExtJSON(cursor.Current, true, false)
+				if err != nil {
+					_ = cursor.Close(ctx)
+					return report, fmt.Errorf("failed to encode %s.%s: %w", name, collection, err)
+				}
+
+				err = encoder.Encode(backupLine{Database: name, Collection: collection, Document: document})
+				if err != nil {
+					_ = cursor.Close(ctx)
+					return report, err
+				}
+				report[name+"."+collection]++
+			}
+
+			err = cursor.Err()
+			_ = cursor.Close(ctx)
+			if err != nil {
+				return report, fmt.Errorf("failed to read %s.%s: %w", name, collection, err)
+			}
+		}
+	}
+
+	return report, gz.Close()
+}
+
+// Restore reads a backup written by Backup and upserts every document by its
+// _id, so restoring twice, or over a partially restored database, is safe.
+func Restore(ctx context.Context, databases map[string]*mongo.Database, src io.Reader) (BackupReport, error) {
+	gz, err := gzip.NewReader(src)
+	if err != nil {
+		return nil, fmt.Errorf("not a backup file: %w", err)
+	}
+	defer gz.Close()
+
+	scanner := bufio.NewScanner(gz)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	report := BackupReport{}
+
+	for n := 1; scanner.Scan(); n++ {
+		var line backupLine
+		if err := json.Unmarshal(scanner.Bytes(), &line); err != nil {
+			return report, fmt.Errorf("line %d: %w", n, err)
+		}
+
+		database, ok := databases[line.Database]
+		if !ok {
+			return report, fmt.Errorf("line %d: unknown database %q", n, line.Database)
+		}
+
+		var document bson.D
+		if err := bson.UnmarshalExtJSON(line.Document, true, &document); err != nil {
+			return report, fmt.Errorf("line %d: %w", n, err)
+		}
+
+		id, ok := documentID(document)
+		if !ok {
+			return report, fmt.Errorf("line %d: document without _id", n)
+		}
+
+		_, err := database.Collection(line.Collection).ReplaceOne(
+			ctx, bson.M{"_id": id}, document, options.Replace().SetUpsert(true))
+		if err != nil {
+			return report, fmt.Errorf("line %d: failed to restore %s.%s: %w", n, line.Database, line.Collection, err)
+		}
+		report[line.Database+"."+line.Collection]++
+	}
+
+	return report, scanner.Err()
+}
+
+func documentID(document bson.D) (interface{}, bool) {
+	for _, element := range document {
+		if element.Key == "_id" {
+			return element.Value, true
+		}
+	}
+
+	return nil, false
+}
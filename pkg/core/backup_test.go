@@ -0,0 +1,70 @@
+package core_test
+
+import (
+	"bytes"
+	"context"
+	"project-wraith/pkg/core"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+)
+
+func TestBackupRestore(test *testing.T) {
+	mt := mtest.New(test, mtest.NewOptions().ClientType(mtest.Mock))
+
+	mt.Run("Test Backup and Restore - Round trip", func(mongoTest *mtest.T) {
+		ctx := context.Background()
+		id := primitive.NewObjectID()
+		created := primitive.NewDateTimeFromTime(time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC))
+
+		mongoTest.AddMockResponses(
+			mtest.CreateCursorResponse(0, "db.$cmd.listCollections", mtest.FirstBatch,
+				bson.D{{Key: "name", Value: "users"}, {Key: "type", Value: "collection"}}),
+			mtest.CreateCursorResponse(0, "db.users", mtest.FirstBatch,
+				bson.D{{Key: "_id", Value: id}, {Key: "username", Value: "wraith"}, {Key: "created", Value: created}}),
+		)
+
+		databases := map[string]*mongo.Database{"user": mongoTest.DB}
+
+		var backup bytes.Buffer
+		report, err := core.Backup(ctx, databases, &backup)
+		require.NoError(mongoTest, err)
+		assert.Equal(mongoTest, core.BackupReport{"user.users": 1}, report)
+
+		mongoTest.ClearEvents()
+		mongoTest.AddMockResponses(mtest.CreateSuccessResponse())
+		report, err = core.Restore(ctx, databases, &backup)
+		require.NoError(mongoTest, err)
+		assert.Equal(mongoTest, core.BackupReport{"user.users": 1}, report)
+
+		started := mongoTest.GetStartedEvent()
+		require.NotNil(mongoTest, started)
+		assert.Equal(mongoTest, "update", started.CommandName)
+
+		update := started.Command.Lookup("updates").Array().Index(0).Value().Document()
+		assert.Equal(mongoTest, id, update.Lookup("q", "_id").ObjectID())
+		assert.Equal(mongoTest, int64(created), update.Lookup("u", "created").DateTime())
+		assert.True(mongoTest, update.Lookup("upsert").Boolean())
+	})
+
+	mt.Run("Test Restore - Unknown database", func(mongoTest *mtest.T) {
+		var backup bytes.Buffer
+		mongoTest.AddMockResponses(
+			mtest.CreateCursorResponse(0, "db.$cmd.listCollections", mtest.FirstBatch,
+				bson.D{{Key: "name", Value: "licenses"}}),
+			mtest.CreateCursorResponse(0, "db.licenses", mtest.FirstBatch,
+				bson.D{{Key: "_id", Value: "key"}}),
+		)
+		_, err := core.Backup(context.Background(), map[string]*mongo.Database{"license": mongoTest.DB}, &backup)
+		require.NoError(mongoTest, err)
+
+		_, err = core.Restore(context.Background(), map[string]*mongo.Database{"user": mongoTest.DB}, &backup)
+		assert.EqualError(mongoTest, err, `line 1: unknown database "license"`)
+	})
+}
@@ -0,0 +1,86 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"project-wraith/pkg/config"
+	"project-wraith/pkg/consts"
+	"project-wraith/pkg/internal/domain"
+	"project-wraith/pkg/internal/rules"
+	"project-wraith/pkg/modules/db"
+	"project-wraith/pkg/modules/logger"
+)
+
+// MigrateCrypt converts the stored users to the form selected by
+// options.encrypt_db_data. See rules.CryptRule for the guarantees.
+func MigrateCrypt(sct *config.Secrets, ini *config.Init, log logger.Logger, dryRun bool, batchSize int) (*rules.CryptReport, error) {
+	cryptRule, close, err := cryptRule(sct, ini, log, batchSize)
+	if err != nil {
+		return nil, err
+	}
+	defer close()
+
+	log.Info("migrating users, encrypt: %t, dry run: %t", ini.Options.EncryptDbData, dryRun)
+	report, err := cryptRule.Migrate(context.Background(), ini.Options.EncryptDbData, dryRun)
+	if err != nil {
+		log.Error("failed to migrate users: %v", err)
+		return report, err
+	}
+
+	log.Info("users migrated: %s", report)
+	return report, nil
+}
+
+// RotateCrypt re-encrypts the stored users from keys.db_data to next. The
+// server must be restarted with next as SECRET_DB once it is done.
+func RotateCrypt(sct *config.Secrets, ini *config.Init, log logger.Logger, next string, dryRun bool, batchSize int) (*rules.CryptReport, error) {
+	if !ini.Options.EncryptDbData {
+		return nil, errors.New("encrypt_db_data is disabled, there is no key to rotate")
+	}
+
+	cryptRule, close, err := cryptRule(sct, ini, log, batchSize)
+	if err != nil {
+		return nil, err
+	}
+	defer close()
+
+	log.Info("rotating the users key, dry run: %t", dryRun)
+	report, err := cryptRule.Rotate(context.Background(), next, dryRun)
+	if err != nil {
+		log.Error("failed to rotate the users key: %v", err)
+		return report, err
+	}
+
+	log.Info("users key rotated: %s", report)
+	return report, nil
+}
+
+func cryptRule(sct *config.Secrets, ini *config.Init, log logger.Logger, batchSize int) (rules.CryptRule, func(), error) {
+	userDbClient := db.NewClient(ini.Database.User.Uri, ini.Database.User.Name)
+	err := userDbClient.Open()
+	if err != nil {
+		log.Error("failed to open db client", err)
+		return nil, nil, err
+	}
+
+	managerDbClient := db.NewClient(ini.Database.Manager.Uri, ini.Database.Manager.Name)
+	err = managerDbClient.Open()
+	if err != nil {
+		log.Error("failed to open db client", err)
+		_ = userDbClient.Close()
+		return nil, nil, err
+	}
+
+	userCollection := userDbClient.Collection(consts.UsersCollection)
+	userRepo := domain.NewUserRepository(*userCollection, ini.Database.Timeout)
+
+	migrationsCollection := managerDbClient.Collection(consts.MigrationsCollection)
+	checkpointRepo := domain.NewCheckpointRepository(*migrationsCollection, ini.Database.Timeout)
+
+	close := func() {
+		_ = managerDbClient.Close()
+		_ = userDbClient.Close()
+	}
+
+	return rules.NewCryptRule(userRepo, checkpointRepo, sct.Keys.DbData, batchSize), close, nil
+}
@@ -0,0 +1,126 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"project-wraith/pkg/config"
+	"project-wraith/pkg/consts"
+	"project-wraith/pkg/modules/db"
+	"project-wraith/pkg/modules/logger"
+	"project-wraith/pkg/modules/migrate"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// OpenDatabases connects the user, manager and license databases. close
+// disconnects the ones that were opened, also when a later one failed.
+func OpenDatabases(ini *config.Init) (_ map[string]db.Client, close func(), err error) {
+	databases := map[string]db.Client{
+		"user":    db.NewClient(ini.Database.User.Uri, ini.Database.User.Name),
+		"manager": db.NewClient(ini.Database.Manager.Uri, ini.Database.Manager.Name),
+		"license": db.NewClient(ini.Database.License.Uri, ini.Database.License.Name),
+	}
+
+	var opened []db.Client
+	close = func() {
+		for _, client := range opened {
+			_ = client.Close()
+		}
+	}
+
+	for _, name := range []string{"user", "license", "manager"} {
+		if err := databases[name].Open(); err != nil {
+			close()
+			return nil, nil, fmt.Errorf("failed to open db.%s: %w", name, err)
+		}
+		opened = append(opened, databases[name])
+	}
+
+	return databases, close, nil
+}
+
+// Migrations lists the schema migrations of every database, in version
+// order. Released migrations must never change; add new versions instead.
+func Migrations(databases map[string]db.Client) []migrate.Migration {
+	users := databases["user"].Collection(consts.UsersCollection)
+	internals := databases["manager"].Collection(consts.InternalsCollection)
+	clients := databases["manager"].Collection(consts.ClientsCollection)
+	locks := databases["manager"].Collection(consts.SchemaLocksCollection)
+	licenses := databases["license"].Collection(consts.LicensesCollection)
+
+	return []migrate.Migration{
+		migrate.Indexes(1, "unique user identifiers", users,
+			uniqueIndex("users_username_unique", "username", true),
+			uniqueIndex("users_email_unique", "email", true),
+			uniqueIndex("users_phone_unique", "phone", true),
+		),
+		migrate.Indexes(2, "unique operator usernames", internals,
+			uniqueIndex("internals_username_unique", "username", false),
+		),
+		migrate.Indexes(3, "unique client api keys", clients,
+			uniqueIndex("clients_api_key_unique", "apiKey", true),
+		),
+		migrate.Indexes(4, "unique license keys", licenses,
+			uniqueIndex("licenses_license_key_unique", "license_key", false),
+		),
+		migrate.Indexes(5, "expire stale migration locks", locks,
+			mongo.IndexModel{
+				Keys:    bson.D{{Key: "expires_at", Value: 1}},
+				Options: options.Index().SetName("schema_locks_expires_at_ttl").SetExpireAfterSeconds(0),
+			},
+		),
+	}
+}
+
+// uniqueIndex makes field unique. A sparse index ignores the documents where
+// field is missing or empty, as optional fields are stored as "".
+func uniqueIndex(name, field string, sparse bool) mongo.IndexModel {
+	opts := options.Index().SetName(name).SetUnique(true)
+	if sparse {
+		opts.SetPartialFilterExpression(bson.M{field: bson.M{"$gt": ""}})
+	}
+
+	return mongo.IndexModel{Keys: bson.D{{Key: field, Value: 1}}, Options: opts}
+}
+
+// Migrator runs Migrations, recording them and taking its lock in the manager
+// database.
+func Migrator(ini *config.Init, databases map[string]db.Client) (migrate.Migrator, error) {
+	records := databases["manager"].Collection(consts.SchemaMigrationsCollection)
+	locks := databases["manager"].Collection(consts.SchemaLocksCollection)
+	return migrate.NewMigrator(*records, *locks, ini.Migrations.LockTTL, Migrations(databases))
+}
+
+// MigrateOnStart applies the pending migrations. When another replica holds
+// the lock it waits for it, up to the lock ttl, and then applies whatever
+// that replica left pending.
+func MigrateOnStart(ctx context.Context, ini *config.Init, log logger.Logger, databases map[string]db.Client) error {
+	migrator, err := Migrator(ini, databases)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, ini.Migrations.LockTTL)
+	defer cancel()
+
+	for {
+		applied, err := migrator.Up(ctx)
+		for _, migration := range applied {
+			log.Info("applied migration %d %s", migration.Version, migration.Name)
+		}
+		if !errors.Is(err, migrate.ErrLocked) {
+			return err
+		}
+
+		log.Info("migrations are locked by another runner, waiting")
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("%w: gave up after %s", migrate.ErrLocked, ini.Migrations.LockTTL)
+		case <-time.After(2 * time.Second):
+		}
+	}
+}
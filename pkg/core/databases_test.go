@@ -0,0 +1,35 @@
+package core_test
+
+import (
+	"project-wraith/pkg/config"
+	"project-wraith/pkg/core"
+	"project-wraith/pkg/modules/db"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+)
+
+func TestMigrations(test *testing.T) {
+	mt := mtest.New(test, mtest.NewOptions().ClientType(mtest.Mock))
+
+	mt.Run("Test Migrations - Valid and ordered", func(mongoTest *mtest.T) {
+		client := db.NewMockClient()
+		client.On("Collection", mock.Anything).Return(mongoTest.Coll)
+		databases := map[string]db.Client{"user": client, "manager": client, "license": client}
+
+		migrations := core.Migrations(databases)
+		for i, migration := range migrations {
+			assert.Equal(mongoTest, i+1, migration.Version)
+			assert.NotNil(mongoTest, migration.Down, migration.Name)
+		}
+
+		var ini config.Init
+		ini.Migrations.LockTTL = time.Minute
+		_, err := core.Migrator(&ini, databases)
+		require.NoError(mongoTest, err)
+	})
+}
@@ -0,0 +1,11 @@
+//go:build !unix
+
+package core
+
+import "net"
+
+// hungUp cannot tell on this platform; requests are still bounded by the
+// request timeout.
+func hungUp(conn net.Conn) bool {
+	return false
+}
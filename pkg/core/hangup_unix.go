@@ -0,0 +1,38 @@
+//go:build unix
+
+package core
+
+import (
+	"errors"
+	"net"
+	"syscall"
+)
+
+// hungUp peeks at the non-blocking conn without consuming anything. Only a
+// reset connection counts as hung up: a peer that reached EOF may just have
+// closed its sending side and still wait for the response, and any other
+// failure, such as an expired read deadline, says nothing about the peer.
+// Connections that cannot be peeked at, such as TLS ones, are taken as live.
+func hungUp(conn net.Conn) bool {
+	sc, ok := conn.(syscall.Conn)
+	if !ok {
+		return false
+	}
+
+	raw, err := sc.SyscallConn()
+	if err != nil {
+		return false
+	}
+
+	var peekErr error
+	err = raw.Read(func(fd uintptr) bool {
+		var buf [1]byte
+		_, _, peekErr = syscall.Recvfrom(int(fd), buf[:], syscall.MSG_PEEK)
+		return true
+	})
+	if err != nil {
+		return false
+	}
+
+	return errors.Is(peekErr, syscall.ECONNRESET) || errors.Is(peekErr, syscall.EPIPE)
+}
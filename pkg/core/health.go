@@ -0,0 +1,59 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"project-wraith/pkg/modules/db"
+	"project-wraith/pkg/modules/health"
+	"project-wraith/pkg/modules/lics"
+	"project-wraith/pkg/modules/mail"
+	"project-wraith/pkg/modules/metrics"
+	"project-wraith/pkg/modules/storage"
+)
+
+// readinessProbeKey is listed to check the storage credentials; it need not
+// exist.
+const readinessProbeKey = ".readyz"
+
+// DatabaseCheck pings the Mongo deployment behind client.
+func DatabaseCheck(client db.Client) health.Check {
+	return func(ctx context.Context) error {
+		mongoClient := client.Client()
+		if mongoClient == nil {
+			return errors.New("not connected")
+		}
+
+		return mongoClient.Ping(ctx, nil)
+	}
+}
+
+// MailCheck waits for the greeting of the SMTP server.
+func MailCheck(mailer mail.Mail) health.Check {
+	return mailer.Ping
+}
+
+// StorageCheck lists bucket, which fails on bad credentials or a missing
+// bucket.
+func StorageCheck(objectStorage storage.Storage, bucket string) health.Check {
+	return func(ctx context.Context) error {
+		_, err := objectStorage.List(bucket, readinessProbeKey)
+		return err
+	}
+}
+
+// LicenseCheck activates the license again and keeps the license status
+// metric in step with the result. A failure to reach the database leaves the
+// metric as it was.
+func LicenseCheck(repo lics.LicenseRepository, licenseKey string) health.Check {
+	return func(ctx context.Context) error {
+		err := Activate(ctx, repo, licenseKey)
+		switch {
+		case err == nil:
+			metrics.License(metrics.LicenseActive)
+		case errors.Is(err, lics.ErrNotFound), errors.Is(err, ErrLicenseInvalid):
+			metrics.License(metrics.LicenseInvalid)
+		}
+
+		return err
+	}
+}
@@ -0,0 +1,46 @@
+package core
+
+import (
+	"context"
+	"project-wraith/pkg/modules/db"
+	"project-wraith/pkg/modules/lifecycle"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// DatabaseHook opens client on start and disconnects it on stop.
+func DatabaseHook(name string, client db.Client) lifecycle.Hook {
+	return lifecycle.Hook{
+		Name: "db." + name,
+		Start: func(ctx context.Context) error {
+			return client.Open()
+		},
+		Stop: func(ctx context.Context) error {
+			return client.Close()
+		},
+	}
+}
+
+// ServerHook listens on addr in the background; a failure to listen stops
+// lc. Stopping refuses new connections and waits up to drainTimeout for the
+// in-flight requests.
+func ServerHook(app *fiber.App, lc lifecycle.Lifecycle, addr string, drainTimeout time.Duration) lifecycle.Hook {
+	return lifecycle.Hook{
+		Name: "server",
+		Start: func(ctx context.Context) error {
+			go func() {
+				if err := app.Listen(addr); err != nil {
+					lc.Fail(err)
+				}
+			}()
+			return nil
+		},
+		Stop: func(ctx context.Context) error {
+			ctx, cancel := context.WithTimeout(ctx, drainTimeout)
+			defer cancel()
+
+			return app.ShutdownWithContext(ctx)
+		},
+	}
+}
@@ -0,0 +1,64 @@
+package core
+
+import (
+	"bufio"
+	"compress/gzip"
+	"errors"
+	"io"
+	"os"
+	"project-wraith/pkg/config"
+	"project-wraith/pkg/modules/alchemy"
+	"project-wraith/pkg/modules/logger"
+	"project-wraith/pkg/modules/shipper"
+)
+
+// LogsKey returns the secret sealing log files at rest, or an empty string
+// when options.encrypt_logs is off.
+func LogsKey(sct *config.Secrets, ini *config.Init) (string, error) {
+	if !ini.Options.EncryptLogs {
+		return "", nil
+	}
+
+	if sct.Keys.Logs == "" {
+		return "", errors.New("encrypt_logs is enabled but SECRET_LOGS is empty")
+	}
+
+	return sct.Keys.Logs, nil
+}
+
+// DecryptLogs writes the plain entries of a log file to dst. It accepts the
+// files in the logs folder, rotated segments, gzipped or not, and segments
+// downloaded from storage, sealed by the shipper or not.
+func DecryptLogs(sct *config.Secrets, filePath string, dst io.Writer) error {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	reader := bufio.NewReader(file)
+	prefix, err := reader.Peek(4)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return err
+	}
+
+	var src io.Reader = reader
+	switch {
+	case alchemy.IsSealedStream(prefix):
+		segment, err := shipper.OpenSegment(reader, sct.Keys.Logs)
+		if err != nil {
+			return err
+		}
+		defer segment.Close()
+		src = segment
+	case len(prefix) >= 2 && prefix[0] == 0x1f && prefix[1] == 0x8b:
+		gz, err := gzip.NewReader(reader)
+		if err != nil {
+			return err
+		}
+		defer gz.Close()
+		src = gz
+	}
+
+	return logger.DecryptLines(src, dst, sct.Keys.Logs)
+}
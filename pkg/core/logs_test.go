@@ -0,0 +1,71 @@
+package core_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"project-wraith/pkg/config"
+	"project-wraith/pkg/core"
+	"project-wraith/pkg/modules/alchemy"
+	"project-wraith/pkg/modules/logger"
+)
+
+func TestDecryptLogs(t *testing.T) {
+	t.Parallel()
+
+	secret := "logs_secret"
+	folder := t.TempDir()
+
+	log := logger.NewLogger(folder, false, logger.Rotation{}, logger.Redaction{}, secret)
+	require.NoError(t, log.Initialize())
+	log.Info("sealed at rest")
+
+	sealedLines, err := os.ReadFile(filepath.Join(folder, "info.log"))
+	require.NoError(t, err)
+
+	var gzipped bytes.Buffer
+	gz := gzip.NewWriter(&gzipped)
+	_, err = gz.Write(sealedLines)
+	require.NoError(t, err)
+	require.NoError(t, gz.Close())
+
+	var shipped bytes.Buffer
+	sealer, err := alchemy.SealStream(&shipped, secret, false, alchemy.DefaultChunkSize)
+	require.NoError(t, err)
+	_, err = io.Copy(sealer, bytes.NewReader(gzipped.Bytes()))
+	require.NoError(t, err)
+	require.NoError(t, sealer.Close())
+
+	tests := []struct {
+		name    string
+		file    string
+		content []byte
+	}{
+		{name: "Active file", file: "info.log", content: sealedLines},
+		{name: "Rotated segment", file: "info-20261019T120000.000Z.log.gz", content: gzipped.Bytes()},
+		{name: "Shipped segment", file: "info-20261019T120000.000Z.log.gz.enc", content: shipped.Bytes()},
+	}
+
+	sct := &config.Secrets{}
+	sct.Keys.Logs = secret
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			filePath := filepath.Join(t.TempDir(), tc.file)
+			require.NoError(t, os.WriteFile(filePath, tc.content, 0600))
+
+			var out bytes.Buffer
+			require.NoError(t, core.DecryptLogs(sct, filePath, &out))
+			assert.Contains(t, out.String(), `"message":"sealed at rest"`)
+		})
+	}
+}
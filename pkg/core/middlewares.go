@@ -1,7 +1,13 @@
 package core
 
 import (
+	"bytes"
+	"context"
+	"crypto/subtle"
 	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
 	jwtware "github.com/gofiber/contrib/jwt"
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/compress"
@@ -12,11 +18,28 @@ import (
 	"github.com/gofiber/fiber/v2/middleware/helmet"
 	"github.com/gofiber/fiber/v2/middleware/keyauth"
 	"github.com/gofiber/fiber/v2/middleware/recover"
+	"github.com/gofiber/fiber/v2/utils"
+	"net"
+	"project-wraith/pkg/consts"
+	"project-wraith/pkg/internal/rules"
+	"project-wraith/pkg/modules/alchemy"
 	"project-wraith/pkg/modules/guard"
 	"project-wraith/pkg/modules/link"
 	"project-wraith/pkg/modules/logger"
+	"project-wraith/pkg/modules/metrics"
 	"project-wraith/pkg/modules/token"
+	"project-wraith/pkg/modules/tracing"
+	"runtime/debug"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
 )
 
 func Helmet() fiber.Handler {
@@ -30,13 +53,19 @@ func Helmet() fiber.Handler {
 func Compress() fiber.Handler {
 	cfg := compress.Config{
 		Next: func(c *fiber.Ctx) bool {
-			return c.Method() != fiber.MethodGet
+			return c.Method() != fiber.MethodGet || streaming(c)
 		},
 		Level: compress.LevelBestSpeed, // 1
 	}
 	return compress.New(cfg)
 }
 
+// streaming tells event streams apart, which must reach the client as they
+// are written instead of being buffered whole.
+func streaming(c *fiber.Ctx) bool {
+	return strings.Contains(c.Get(fiber.HeaderAccept), "text/event-stream")
+}
+
 func EncryptCookie(secret string) fiber.Handler {
 	cfg := encryptcookie.Config{
 		Key: base64.StdEncoding.EncodeToString([]byte(secret)),
@@ -47,15 +76,26 @@ func EncryptCookie(secret string) fiber.Handler {
 func ETag() fiber.Handler {
 	cfg := etag.Config{
 		Next: func(c *fiber.Ctx) bool {
-			return c.Method() != fiber.MethodGet
+			return c.Method() != fiber.MethodGet || streaming(c)
 		},
 		Weak: true,
 	}
 	return etag.New(cfg)
 }
 
-func Recover() fiber.Handler {
-	return recover.New()
+// Recover turns panics into 500s and logs them as errors with their stack and
+// a panic field, so the notifier relay raises them as critical alerts.
+func Recover(log logger.Logger) fiber.Handler {
+	cfg := recover.Config{
+		EnableStackTrace: true,
+		StackTraceHandler: func(c *fiber.Ctx, e interface{}) {
+			logger.FromContext(c.UserContext(), log).Errorw(
+				fmt.Sprintf("panic recovered: %v", e),
+				"panic", true,
+				"stack", string(debug.Stack()))
+		},
+	}
+	return recover.New(cfg)
 }
 
 func JwtWare(jwtSecret string, lookUp string) fiber.Handler {
@@ -69,20 +109,100 @@ func JwtWare(jwtSecret string, lookUp string) fiber.Handler {
 	return jwtware.New(cfg)
 }
 
-func KeyAuth(apiKey string) fiber.Handler {
+// KeyAuth lets in the server API key and the API keys of registered clients,
+// which select the client that hybrid encrypted responses are sealed for.
+func KeyAuth(apiKey string, clients rules.ClientRule) fiber.Handler {
 	cfg := keyauth.Config{
+		Next:      scraper,
 		KeyLookup: "header:x-access-token",
 		Validator: func(c *fiber.Ctx, s string) (bool, error) {
-			if apiKey != s {
-				return false, nil
+			if subtle.ConstantTimeCompare([]byte(apiKey), []byte(s)) == 1 {
+				return true, nil
 			}
 
-			return true, nil
+			return clients.Authenticate(c.UserContext(), s)
 		},
 	}
 	return keyauth.New(cfg)
 }
 
+// ScrapeToken lets Prometheus in with "Authorization: Bearer <token>" in place
+// of the API key and internals credentials. An empty token disables it.
+func ScrapeToken(token string) fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		bearer, found := strings.CutPrefix(ctx.Get(fiber.HeaderAuthorization), "Bearer ")
+		if token != "" && found && subtle.ConstantTimeCompare([]byte(bearer), []byte(token)) == 1 {
+			ctx.Locals(consts.ScraperLocal, true)
+		}
+
+		return ctx.Next()
+	}
+}
+
+func scraper(c *fiber.Ctx) bool {
+	scraped, _ := c.Locals(consts.ScraperLocal).(bool)
+	return scraped
+}
+
+// Metrics counts and times every request by its route template, so paths
+// with IDs share their series.
+func Metrics() fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		start := time.Now()
+		err := ctx.Next()
+
+		labels := []string{ctx.Route().Path, ctx.Method(), strconv.Itoa(responseStatus(ctx, err))}
+		metrics.HTTPRequests.WithLabelValues(labels...).Inc()
+		metrics.HTTPDuration.WithLabelValues(labels...).Observe(time.Since(start).Seconds())
+
+		return err
+	}
+}
+
+// Tracing opens a server span for every request, continuing the trace of an
+// incoming traceparent header, and hands it to the handlers in the user
+// context. Spans are named after the route template once it is matched.
+func Tracing() fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		carrier := propagation.HeaderCarrier(ctx.GetReqHeaders())
+		parent := otel.GetTextMapPropagator().Extract(ctx.UserContext(), carrier)
+
+		spanCtx, span := tracing.Start(parent, ctx.Method(),
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(attribute.String("http.request.method", ctx.Method())))
+		defer span.End()
+
+		ctx.SetUserContext(spanCtx)
+		err := ctx.Next()
+
+		status := responseStatus(ctx, err)
+		span.SetName(fmt.Sprintf("%s %s", ctx.Method(), ctx.Route().Path))
+		span.SetAttributes(
+			attribute.String("http.route", ctx.Route().Path),
+			attribute.Int("http.response.status_code", status))
+		if status >= fiber.StatusInternalServerError {
+			span.SetStatus(codes.Error, utils.StatusMessage(status))
+		}
+
+		return err
+	}
+}
+
+// responseStatus is the status the request is answered with, including the
+// one the error handler will pick for err.
+func responseStatus(ctx *fiber.Ctx, err error) int {
+	if err == nil {
+		return ctx.Response().StatusCode()
+	}
+
+	var fiberErr *fiber.Error
+	if errors.As(err, &fiberErr) {
+		return fiberErr.Code
+	}
+
+	return fiber.StatusInternalServerError
+}
+
 func CRSF() fiber.Handler {
 	cfg := csrf.Config{
 		Expiration: 15 * time.Minute,
@@ -91,12 +211,12 @@ func CRSF() fiber.Handler {
 	return csrf.New(cfg)
 }
 
-func CORS() fiber.Handler {
+func CORS(origins []string) fiber.Handler {
 	cfg := &cors.Config{
-		AllowOrigins:  "*",
-		AllowHeaders:  "Origin,Content-Type,Accept,X-Session-Token,X-Application-Key",
+		AllowOrigins:  strings.Join(origins, ","),
+		AllowHeaders:  "Origin,Content-Type,Accept,X-Session-Token,X-Application-Key,X-Client-Id,X-Request-ID,Accept-Encryption,Content-Encryption",
 		AllowMethods:  "GET,POST,PUT,DELETE",
-		ExposeHeaders: "Content-Length,Authorization",
+		ExposeHeaders: "Content-Length,Authorization,Content-Encryption,X-Request-ID",
 		MaxAge:        5600,
 	}
 	return cors.New(*cfg)
@@ -122,19 +242,224 @@ func ResetAuth(jwtSecret string) fiber.Handler {
 	}
 }
 
+// RequestID keeps a valid incoming X-Request-ID, or assigns a new one, echoes
+// it on the response and scopes a child of log to the request, so every entry
+// written while handling it carries the ID, and the trace ID when traced.
+func RequestID(log logger.Logger) fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		requestID := ctx.Get(fiber.HeaderXRequestID)
+		if !validRequestID(requestID) {
+			requestID = utils.UUIDv4()
+		}
+
+		ctx.Locals(consts.RequestIDLocal, requestID)
+		ctx.Set(fiber.HeaderXRequestID, requestID)
+
+		fields := []interface{}{"requestId", requestID, "method", ctx.Method(), "path", ctx.Path()}
+		if traceID := tracing.TraceID(ctx.UserContext()); traceID != "" {
+			fields = append(fields, "traceId", traceID)
+		}
+
+		scoped := log.With(fields...)
+		ctx.SetUserContext(logger.WithContext(ctx.UserContext(), scoped))
+
+		return ctx.Next()
+	}
+}
+
+// RequestContext bounds the user context of every request by timeout and
+// cancels it when the client hangs up, so the repositories stop working for a
+// request nobody waits for. Event streams are written after their handler
+// returns, so their connection is not watched. Server shutdown does not
+// cancel it; in-flight requests are drained instead.
+func RequestContext(timeout time.Duration) fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		requestCtx, cancel := context.WithTimeout(ctx.UserContext(), timeout)
+		defer cancel()
+
+		if !streaming(ctx) {
+			stop := watchHangUp(ctx.Context().Conn(), cancel)
+			defer stop()
+		}
+
+		ctx.SetUserContext(requestCtx)
+		return ctx.Next()
+	}
+}
+
+const (
+	// hangUpGrace is how long a request runs before its connection is
+	// watched; quicker requests never start a poll.
+	hangUpGrace = time.Second
+	// hangUpPoll is how often the connection of a running request is checked.
+	hangUpPoll = 250 * time.Millisecond
+)
+
+// watchHangUp calls cancel once the peer closes conn, polling from hangUpGrace
+// on. stop ends the watch and returns once any poll in progress has, so conn
+// is not touched after the request.
+func watchHangUp(conn net.Conn, cancel context.CancelFunc) (stop func()) {
+	var (
+		mu      sync.Mutex
+		stopped bool
+		timer   *time.Timer
+	)
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	timer = time.AfterFunc(hangUpGrace, func() {
+		mu.Lock()
+		defer mu.Unlock()
+
+		if stopped {
+			return
+		}
+		if hungUp(conn) {
+			cancel()
+			return
+		}
+		timer.Reset(hangUpPoll)
+	})
+
+	return func() {
+		mu.Lock()
+		defer mu.Unlock()
+
+		stopped = true
+		timer.Stop()
+	}
+}
+
+// validRequestID accepts IDs from upstream proxies as long as they are short
+// and cannot break a log line.
+func validRequestID(requestID string) bool {
+	if requestID == "" || len(requestID) > 128 {
+		return false
+	}
+
+	for _, r := range requestID {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+		case r == '-', r == '_', r == '.', r == ':':
+		default:
+			return false
+		}
+	}
+
+	return true
+}
+
 func ManticoreSight(manticore guard.Manticore, log logger.Logger) fiber.Handler {
 	return func(ctx *fiber.Ctx) error {
+		if scraper(ctx) {
+			return ctx.Next()
+		}
+
 		cred := guard.Credentials{
 			Username: ctx.FormValue("username"),
 			Password: ctx.FormValue("password"),
 		}
 
-		err := manticore.StingAndProwl(cred)
+		err := manticore.StingAndProwl(ctx.UserContext(), cred)
 		if err != nil {
-			log.Error("failed to validate token: %v", err)
+			logger.FromContext(ctx.UserContext(), log).Error("failed to validate token: %v", err)
 			return ctx.Status(fiber.StatusBadRequest).JSON(link.Response{Message: err.Error()})
 		}
 
 		return ctx.Next()
 	}
 }
+
+// EncryptResponse wraps every JSON response in a link.Envelope for clients
+// that ask for it with Accept-Encryption. alchemy.SharedAlg uses the shared
+// response secret, alchemy.HybridAlg seals the body for the calling client,
+// selected by X-Client-Id or its API key. Errors are rendered first so they
+// are encrypted too.
+func EncryptResponse(enabled bool, secret string, clients rules.ClientRule) fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		alg := ctx.Get("Accept-Encryption")
+		if !enabled || alg == "" {
+			return ctx.Next()
+		}
+
+		if alg != alchemy.SharedAlg && alg != alchemy.HybridAlg {
+			return ctx.Status(fiber.StatusNotAcceptable).JSON(link.Response{Message: "unsupported encryption"})
+		}
+
+		ctx.Vary("Accept-Encryption")
+
+		if err := ctx.Next(); err != nil {
+			if err := ctx.App().ErrorHandler(ctx, err); err != nil {
+				return err
+			}
+		}
+
+		contentType := ctx.Response().Header.ContentType()
+		if !bytes.HasPrefix(contentType, []byte(fiber.MIMEApplicationJSON)) {
+			return nil
+		}
+
+		body := json.RawMessage(append([]byte{}, ctx.Response().Body()...))
+		envelope := link.Envelope{Alg: alg}
+
+		var err error
+		if alg == alchemy.SharedAlg {
+			envelope.Kid = "shared"
+			envelope.Payload, err = alchemy.Encrypt(string(body), secret)
+		} else {
+			client := rules.Client{ID: ctx.Get("X-Client-Id"), ApiKey: ctx.Get("x-access-token")}
+			envelope.Kid, envelope.Payload, err = clients.Seal(ctx.UserContext(), client, body)
+		}
+
+		if err != nil {
+			return ctx.Status(fiber.StatusForbidden).JSON(link.Response{Message: "failed to encrypt response"})
+		}
+
+		ctx.Set("Content-Encryption", alg)
+		return ctx.JSON(envelope)
+	}
+}
+
+// DecryptRequest unwraps request bodies sent as a link.Envelope with a
+// Content-Encryption header, so handlers keep parsing plain JSON. Hybrid
+// payloads must be sealed for the server key (SECRET_HYBRID).
+func DecryptRequest(enabled bool, secret, hybridKey string) fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		alg := ctx.Get("Content-Encryption")
+		if alg == "" {
+			return ctx.Next()
+		}
+
+		if !enabled || (alg == alchemy.HybridAlg && hybridKey == "") ||
+			(alg != alchemy.SharedAlg && alg != alchemy.HybridAlg) {
+			return ctx.Status(fiber.StatusUnsupportedMediaType).JSON(link.Response{Message: "unsupported encryption"})
+		}
+
+		var envelope link.Envelope
+		if err := json.Unmarshal(ctx.Body(), &envelope); err != nil || envelope.Alg != alg {
+			return ctx.Status(fiber.StatusBadRequest).JSON(link.Response{Message: "invalid encrypted body"})
+		}
+
+		var plain []byte
+		if alg == alchemy.SharedAlg {
+			decrypted, err := alchemy.Decrypt(envelope.Payload, secret)
+			if err != nil {
+				return ctx.Status(fiber.StatusBadRequest).JSON(link.Response{Message: "invalid encrypted body"})
+			}
+			plain = []byte(decrypted)
+		} else {
+			opened, err := alchemy.OpenSealed(hybridKey, envelope.Payload)
+			if err != nil {
+				return ctx.Status(fiber.StatusBadRequest).JSON(link.Response{Message: "invalid encrypted body"})
+			}
+			plain = opened
+		}
+
+		ctx.Request().SetBody(plain)
+		ctx.Request().Header.SetContentType(fiber.MIMEApplicationJSON)
+		ctx.Request().Header.Del("Content-Encryption")
+
+		return ctx.Next()
+	}
+}
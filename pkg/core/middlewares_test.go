@@ -0,0 +1,543 @@
+package core_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"project-wraith/pkg/core"
+	"project-wraith/pkg/internal/domain"
+	"project-wraith/pkg/internal/rules"
+	"project-wraith/pkg/modules/alchemy"
+	"project-wraith/pkg/modules/apikey"
+	"project-wraith/pkg/modules/guard"
+	"project-wraith/pkg/modules/link"
+	"project-wraith/pkg/modules/logger"
+	"project-wraith/pkg/modules/metrics"
+	"project-wraith/pkg/modules/notifier"
+	"project-wraith/pkg/modules/tracing"
+)
+
+func TestEncryptResponse(t *testing.T) {
+	t.Parallel()
+
+	secret := "response_secret"
+
+	tests := []struct {
+		name           string
+		enabled        bool
+		accept         string
+		handlerErr     bool
+		expectedStatus int
+		expectEnvelope bool
+	}{
+		{name: "Disabled", enabled: false, accept: alchemy.SharedAlg, expectedStatus: fiber.StatusOK},
+		{name: "No opt in", enabled: true, expectedStatus: fiber.StatusOK},
+		{name: "Shared secret", enabled: true, accept: alchemy.SharedAlg, expectedStatus: fiber.StatusOK, expectEnvelope: true},
+		{name: "Client key", enabled: true, accept: alchemy.HybridAlg, expectedStatus: fiber.StatusOK, expectEnvelope: true},
+		{name: "Errors are encrypted", enabled: true, accept: alchemy.SharedAlg, handlerErr: true, expectedStatus: fiber.StatusNotFound, expectEnvelope: true},
+		{name: "Unknown algorithm", enabled: true, accept: "rot13", expectedStatus: fiber.StatusNotAcceptable},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			clients := new(rules.MockClientRule)
+			clients.On("Seal", mock.Anything, rules.Client{ID: "mobile"}, mock.Anything).Return("mobile", "sealed", nil)
+
+			app := fiber.New(fiber.Config{ErrorHandler: link.Error})
+			app.Use(core.EncryptResponse(tc.enabled, secret, clients))
+			app.Get("/", func(ctx *fiber.Ctx) error {
+				if tc.handlerErr {
+					return fiber.NewError(fiber.StatusNotFound, "not found")
+				}
+				return ctx.JSON(link.Response{Message: "hello"})
+			})
+
+			req := httptest.NewRequest("GET", "/", nil)
+			req.Header.Set("Accept-Encryption", tc.accept)
+			req.Header.Set("X-Client-Id", "mobile")
+
+			resp, err := app.Test(req, -1)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedStatus, resp.StatusCode)
+
+			body, err := io.ReadAll(resp.Body)
+			require.NoError(t, err)
+
+			if !tc.expectEnvelope {
+				assert.NotContains(t, string(body), "payload")
+				return
+			}
+
+			var envelope link.Envelope
+			require.NoError(t, json.Unmarshal(body, &envelope))
+			assert.Equal(t, tc.accept, envelope.Alg)
+			assert.Equal(t, tc.accept, resp.Header.Get("Content-Encryption"))
+
+			if tc.accept == alchemy.HybridAlg {
+				assert.Equal(t, "mobile", envelope.Kid)
+				assert.Equal(t, "sealed", envelope.Payload)
+				return
+			}
+
+			plain, err := alchemy.Decrypt(envelope.Payload, secret)
+			require.NoError(t, err)
+			var response link.Response
+			require.NoError(t, json.Unmarshal([]byte(plain), &response))
+			assert.NotEmpty(t, response.Message)
+		})
+	}
+}
+
+func TestKeyAuthClients(t *testing.T) {
+	t.Parallel()
+
+	mobilePublic, mobilePrivate, err := alchemy.GenerateHybridKeys()
+	require.NoError(t, err)
+	webPublic, webPrivate, err := alchemy.GenerateHybridKeys()
+	require.NoError(t, err)
+
+	repo := new(domain.MockClientRepository)
+	repo.On("Get", mock.Anything, domain.Client{ApiKey: apikey.CrateApiKey("mobile-key")}).
+		Return(&domain.Client{ID: "mobile", PublicKey: mobilePublic}, nil)
+	repo.On("Get", mock.Anything, domain.Client{ApiKey: apikey.CrateApiKey("web-key")}).
+		Return(&domain.Client{ID: "web", PublicKey: webPublic}, nil)
+	repo.On("Get", mock.Anything, mock.Anything).Return((*domain.Client)(nil), domain.ErrClientNotFound)
+	clients := rules.NewClientRule(repo)
+
+	app := fiber.New(fiber.Config{ErrorHandler: link.Error})
+	app.Use(core.KeyAuth("server-key", clients))
+	app.Use(core.EncryptResponse(true, "response_secret", clients))
+	app.Get("/", func(ctx *fiber.Ctx) error {
+		return ctx.JSON(link.Response{Message: "hello"})
+	})
+
+	tests := []struct {
+		name           string
+		apiKey         string
+		private        string
+		expectedStatus int
+		expectedKid    string
+	}{
+		{name: "Mobile client", apiKey: "mobile-key", private: mobilePrivate, expectedStatus: fiber.StatusOK, expectedKid: "mobile"},
+		{name: "Web client", apiKey: "web-key", private: webPrivate, expectedStatus: fiber.StatusOK, expectedKid: "web"},
+		{name: "Server key selects no client", apiKey: "server-key", expectedStatus: fiber.StatusForbidden},
+		{name: "Unknown key", apiKey: "other-key", expectedStatus: fiber.StatusUnauthorized},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			req := httptest.NewRequest("GET", "/", nil)
+			req.Header.Set("x-access-token", tc.apiKey)
+			req.Header.Set("Accept-Encryption", alchemy.HybridAlg)
+
+			resp, err := app.Test(req, -1)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedStatus, resp.StatusCode)
+			if tc.expectedKid == "" {
+				return
+			}
+
+			var envelope link.Envelope
+			require.NoError(t, json.NewDecoder(resp.Body).Decode(&envelope))
+			assert.Equal(t, tc.expectedKid, envelope.Kid)
+
+			var response link.Response
+			require.NoError(t, alchemy.OpenSealedStruct(tc.private, envelope.Payload, &response))
+			assert.Equal(t, "hello", response.Message)
+		})
+	}
+}
+
+func TestDecryptRequest(t *testing.T) {
+	t.Parallel()
+
+	secret := "response_secret"
+	serverPublic, serverPrivate, err := alchemy.GenerateHybridKeys()
+	require.NoError(t, err)
+
+	plain := []byte(`{"message":"hello"}`)
+
+	shared, err := alchemy.Encrypt(string(plain), secret)
+	require.NoError(t, err)
+	hybrid, err := alchemy.SealFor(serverPublic, plain)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name           string
+		enabled        bool
+		alg            string
+		envelope       link.Envelope
+		expectedStatus int
+	}{
+		{name: "Plain body", enabled: true, expectedStatus: fiber.StatusOK},
+		{name: "Shared secret", enabled: true, alg: alchemy.SharedAlg, envelope: link.Envelope{Alg: alchemy.SharedAlg, Payload: shared}, expectedStatus: fiber.StatusOK},
+		{name: "Server key", enabled: true, alg: alchemy.HybridAlg, envelope: link.Envelope{Alg: alchemy.HybridAlg, Payload: hybrid}, expectedStatus: fiber.StatusOK},
+		{name: "Tampered payload", enabled: true, alg: alchemy.SharedAlg, envelope: link.Envelope{Alg: alchemy.SharedAlg, Payload: shared[:len(shared)-2]}, expectedStatus: fiber.StatusBadRequest},
+		{name: "Algorithm mismatch", enabled: true, alg: alchemy.HybridAlg, envelope: link.Envelope{Alg: alchemy.SharedAlg, Payload: shared}, expectedStatus: fiber.StatusBadRequest},
+		{name: "Disabled", enabled: false, alg: alchemy.SharedAlg, envelope: link.Envelope{Alg: alchemy.SharedAlg, Payload: shared}, expectedStatus: fiber.StatusUnsupportedMediaType},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			app := fiber.New()
+			app.Use(core.DecryptRequest(tc.enabled, secret, serverPrivate))
+			app.Post("/", func(ctx *fiber.Ctx) error {
+				var response link.Response
+				if err := ctx.BodyParser(&response); err != nil {
+					return err
+				}
+				return ctx.JSON(response)
+			})
+
+			body := plain
+			if tc.alg != "" {
+				encoded, err := json.Marshal(tc.envelope)
+				require.NoError(t, err)
+				body = encoded
+			}
+
+			req := httptest.NewRequest("POST", "/", bytes.NewReader(body))
+			req.Header.Set("Content-Type", fiber.MIMEApplicationJSON)
+			req.Header.Set("Content-Encryption", tc.alg)
+
+			resp, err := app.Test(req, -1)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedStatus, resp.StatusCode)
+
+			if tc.expectedStatus != fiber.StatusOK {
+				return
+			}
+
+			var response link.Response
+			require.NoError(t, json.NewDecoder(resp.Body).Decode(&response))
+			assert.Equal(t, "hello", response.Message)
+		})
+	}
+}
+
+func TestRequestID(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		incoming   string
+		expectSame bool
+	}{
+		{name: "Propagated", incoming: "edge-7f3a.1", expectSame: true},
+		{name: "Assigned when missing", incoming: ""},
+		{name: "Replaced when unsafe", incoming: "bad id\nforged"},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			folder := t.TempDir()
+			log := logger.NewLogger(folder, false, logger.Rotation{}, logger.Redaction{}, "")
+			require.NoError(t, log.Initialize())
+
+			app := fiber.New()
+			app.Use(core.RequestID(log))
+			app.Get("/", func(ctx *fiber.Ctx) error {
+				logger.FromContext(ctx.UserContext(), log).Info("handled")
+				return ctx.SendStatus(fiber.StatusOK)
+			})
+
+			req := httptest.NewRequest("GET", "/", nil)
+			req.Header.Set(fiber.HeaderXRequestID, tc.incoming)
+
+			resp, err := app.Test(req, -1)
+			require.NoError(t, err)
+
+			requestID := resp.Header.Get(fiber.HeaderXRequestID)
+			require.NotEmpty(t, requestID)
+			if tc.expectSame {
+				assert.Equal(t, tc.incoming, requestID)
+			} else {
+				assert.NotEqual(t, tc.incoming, requestID)
+			}
+
+			entries, err := logger.ReadFile(filepath.Join(folder, "info.log"), "")
+			require.NoError(t, err)
+			require.Len(t, entries, 1)
+			assert.Equal(t, requestID, entries[0]["requestId"])
+			assert.Equal(t, "/", entries[0]["path"])
+		})
+	}
+}
+
+func TestRequestContext(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		timeout  time.Duration
+		hangUp   bool
+		halfOpen bool
+		stream   bool
+		expected error
+	}{
+		{
+			name:     "Deadline",
+			timeout:  50 * time.Millisecond,
+			expected: context.DeadlineExceeded,
+		},
+		{
+			name:     "Client hangs up",
+			timeout:  time.Minute,
+			hangUp:   true,
+			expected: context.Canceled,
+		},
+		{
+			name:     "Client half closes after its request",
+			timeout:  2 * time.Second,
+			halfOpen: true,
+			expected: context.DeadlineExceeded,
+		},
+		{
+			name:     "Event streams are not watched",
+			timeout:  2 * time.Second,
+			hangUp:   true,
+			stream:   true,
+			expected: context.DeadlineExceeded,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			seen := make(chan error, 1)
+			app := fiber.New(fiber.Config{DisableStartupMessage: true})
+			app.Use(core.RequestContext(tc.timeout))
+			app.Get("/", func(ctx *fiber.Ctx) error {
+				<-ctx.UserContext().Done()
+				seen <- ctx.UserContext().Err()
+				return ctx.SendStatus(fiber.StatusGatewayTimeout)
+			})
+
+			listener, err := net.Listen("tcp", "127.0.0.1:0")
+			require.NoError(t, err)
+			go func() { _ = app.Listener(listener) }()
+			defer func() { _ = app.ShutdownWithTimeout(time.Second) }()
+
+			conn, err := net.Dial("tcp", listener.Addr().String())
+			require.NoError(t, err)
+			defer conn.Close()
+
+			request := "GET / HTTP/1.1\r\nHost: wraith\r\n"
+			if tc.stream {
+				request += "Accept: text/event-stream\r\n"
+			}
+			_, err = io.WriteString(conn, request+"\r\n")
+			require.NoError(t, err)
+			if tc.hangUp {
+				// Without lingering the close resets the connection.
+				require.NoError(t, conn.(*net.TCPConn).SetLinger(0))
+				require.NoError(t, conn.Close())
+			}
+			if tc.halfOpen {
+				require.NoError(t, conn.(*net.TCPConn).CloseWrite())
+			}
+
+			select {
+			case err := <-seen:
+				assert.ErrorIs(t, err, tc.expected)
+			case <-time.After(5 * time.Second):
+				t.Fatal("request context was never done")
+			}
+		})
+	}
+}
+
+func TestRecover(t *testing.T) {
+	log := logger.NewLogger(t.TempDir(), false, logger.Rotation{}, logger.Redaction{}, "")
+	require.NoError(t, log.Initialize())
+
+	var events []logger.Event
+	require.NoError(t, log.AddHook("error", func(event logger.Event) {
+		events = append(events, event)
+	}))
+
+	var alerts []notifier.Message
+	notifierMock := &notifier.MockNotifier{}
+	notifierMock.On("Notify", mock.Anything).Run(func(args mock.Arguments) {
+		alerts = append(alerts, args.Get(0).(notifier.Message))
+	}).Return(nil)
+	relay := notifier.NewRelay(log, notifierMock, time.Hour)
+	require.NoError(t, log.AddHook("error", relay.Hook))
+
+	app := fiber.New()
+	app.Use(core.RequestID(log))
+	app.Use(core.Recover(log))
+	app.Get("/boom", func(ctx *fiber.Ctx) error {
+		panic("boom")
+	})
+
+	resp, err := app.Test(httptest.NewRequest("GET", "/boom", nil), -1)
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusInternalServerError, resp.StatusCode)
+
+	require.Len(t, events, 1)
+	assert.Equal(t, "panic recovered: boom", events[0].Message)
+	assert.Equal(t, resp.Header.Get("X-Request-ID"), events[0].Fields["requestId"])
+	assert.Contains(t, events[0].Fields["stack"], "runtime/debug.Stack")
+
+	stop := make(chan struct{})
+	close(stop)
+	relay.Run(stop)
+
+	require.NotEmpty(t, alerts)
+	assert.Equal(t, notifier.SeverityCritical, alerts[0].Severity)
+	assert.Equal(t, "panic recovered: boom", alerts[0].Title)
+}
+
+func TestMetrics(t *testing.T) {
+	app := fiber.New(fiber.Config{ErrorHandler: link.Error})
+	app.Use(core.Metrics())
+	app.Get("/metrics-test/:id", func(ctx *fiber.Ctx) error {
+		if ctx.Params("id") == "missing" {
+			return fiber.NewError(fiber.StatusNotFound, "not found")
+		}
+		return ctx.SendString("ok")
+	})
+
+	for _, id := range []string{"1", "2", "missing"} {
+		resp, err := app.Test(httptest.NewRequest("GET", "/metrics-test/"+id, nil), -1)
+		require.NoError(t, err)
+		require.NoError(t, resp.Body.Close())
+	}
+
+	assert.Equal(t, 2.0, testutil.ToFloat64(metrics.HTTPRequests.WithLabelValues("/metrics-test/:id", "GET", "200")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(metrics.HTTPRequests.WithLabelValues("/metrics-test/:id", "GET", "404")))
+}
+
+func TestScrapeToken(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name           string
+		token          string
+		headers        map[string]string
+		expectedStatus int
+	}{
+		{name: "Scrape token", token: "scrape", headers: map[string]string{"Authorization": "Bearer scrape"}, expectedStatus: fiber.StatusOK},
+		{name: "Wrong scrape token", token: "scrape", headers: map[string]string{"Authorization": "Bearer other"}, expectedStatus: fiber.StatusUnauthorized},
+		{name: "Token disabled", headers: map[string]string{"Authorization": "Bearer "}, expectedStatus: fiber.StatusUnauthorized},
+		{name: "API key without credentials", token: "scrape", headers: map[string]string{"x-access-token": "api-key"}, expectedStatus: fiber.StatusBadRequest},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			manticore := new(guard.MockManticore)
+			manticore.On("StingAndProwl", mock.Anything, mock.Anything).Return(errors.New("user not found"))
+
+			log := new(logger.MockLogger)
+			log.On("Error", mock.Anything).Return()
+
+			clients := new(rules.MockClientRule)
+			clients.On("Authenticate", mock.Anything, mock.Anything).Return(false, nil)
+
+			app := fiber.New()
+			app.Use(core.ScrapeToken(tc.token))
+			app.Use(core.KeyAuth("api-key", clients))
+			app.Use(core.ManticoreSight(manticore, log))
+			app.Get("/metrics", func(ctx *fiber.Ctx) error {
+				return ctx.SendString("ok")
+			})
+
+			req := httptest.NewRequest("GET", "/metrics", nil)
+			for key, value := range tc.headers {
+				req.Header.Set(key, value)
+			}
+
+			resp, err := app.Test(req, -1)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedStatus, resp.StatusCode)
+		})
+	}
+}
+
+func TestTracing(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	shutdown := tracing.Install(recorder, resource.Empty(), 1)
+	t.Cleanup(func() { _ = shutdown(context.Background()) })
+
+	log := logger.NewLogger(t.TempDir(), false, logger.Rotation{}, logger.Redaction{}, "")
+	require.NoError(t, log.Initialize())
+
+	var events []logger.Event
+	require.NoError(t, log.AddHook("error", func(event logger.Event) {
+		events = append(events, event)
+	}))
+
+	app := fiber.New(fiber.Config{ErrorHandler: link.Error})
+	app.Use(core.Tracing())
+	app.Use(core.RequestID(log))
+	app.Get("/traced/:id", func(ctx *fiber.Ctx) error {
+		_, span := tracing.Start(ctx.UserContext(), "lookup")
+		tracing.End(span, nil)
+
+		logger.FromContext(ctx.UserContext(), log).Error("lookup failed")
+		return fiber.NewError(fiber.StatusBadGateway, "upstream down")
+	})
+
+	parentTraceID := "4bf92f3577b34da6a3ce929d0e0e4736"
+	req := httptest.NewRequest("GET", "/traced/42", nil)
+	req.Header.Set("traceparent", "00-"+parentTraceID+"-00f067aa0ba902b7-01")
+
+	resp, err := app.Test(req, -1)
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusBadGateway, resp.StatusCode)
+
+	spans := map[string]sdktrace.ReadOnlySpan{}
+	for _, span := range recorder.Ended() {
+		spans[span.Name()] = span
+	}
+
+	server, ok := spans["GET /traced/:id"]
+	require.True(t, ok)
+	assert.Equal(t, parentTraceID, server.SpanContext().TraceID().String())
+	assert.Equal(t, "00f067aa0ba902b7", server.Parent().SpanID().String())
+	assert.Equal(t, codes.Error, server.Status().Code)
+	assert.Contains(t, server.Attributes(), attribute.Int("http.response.status_code", fiber.StatusBadGateway))
+	assert.Contains(t, server.Attributes(), attribute.String("http.route", "/traced/:id"))
+
+	child, ok := spans["lookup"]
+	require.True(t, ok)
+	assert.Equal(t, server.SpanContext().SpanID(), child.Parent().SpanID())
+
+	require.Len(t, events, 1)
+	assert.Equal(t, parentTraceID, events[0].Fields["traceId"])
+}
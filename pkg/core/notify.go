@@ -0,0 +1,60 @@
+package core
+
+import (
+	"fmt"
+	"project-wraith/pkg/config"
+	"project-wraith/pkg/modules/mail"
+	"project-wraith/pkg/modules/notifier"
+)
+
+// Notifiers routes notifications to every channel enabled in the ini notify
+// sections. Channels without their credentials are left out.
+func Notifiers(sct *config.Secrets, ini *config.Init, mailer mail.Mail) (notifier.Notifier, error) {
+	var routes []notifier.Route
+
+	add := func(name string, route config.NotifyRoute, channel notifier.Notifier) error {
+		severity, err := notifier.ParseSeverity(route.Severity)
+		if err != nil {
+			return fmt.Errorf("notify.%s: %w", name, err)
+		}
+
+		routes = append(routes, notifier.Route{
+			Name:       name,
+			Notifier:   channel,
+			Severity:   severity,
+			Categories: route.Categories,
+		})
+		return nil
+	}
+
+	telegram := ini.Notify.Telegram
+	if telegram.Enabled && sct.Notifiers.Bot.Token != "" && sct.Notifiers.Bot.Chat != "" {
+		bot := notifier.NewTelegramBot(telegram.BaseURL, sct.Notifiers.Bot.Token, sct.Notifiers.Bot.Chat)
+		if err := add("telegram", telegram.NotifyRoute, bot); err != nil {
+			return nil, err
+		}
+	}
+
+	slack := ini.Notify.Slack
+	if slack.Enabled && sct.Notifiers.Slack.Webhook != "" {
+		if err := add("slack", slack, notifier.NewSlackWebhook(sct.Notifiers.Slack.Webhook)); err != nil {
+			return nil, err
+		}
+	}
+
+	webhook := ini.Notify.Webhook
+	if webhook.Enabled && webhook.URL != "" {
+		if err := add("webhook", webhook.NotifyRoute, notifier.NewWebhook(webhook.URL, sct.Notifiers.Webhook.Secret)); err != nil {
+			return nil, err
+		}
+	}
+
+	email := ini.Notify.Email
+	if email.Enabled && len(email.To) > 0 {
+		if err := add("email", email.NotifyRoute, notifier.NewEmail(mailer, email.Template, email.To)); err != nil {
+			return nil, err
+		}
+	}
+
+	return notifier.NewRouter(routes...), nil
+}
@@ -0,0 +1,40 @@
+package core_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"project-wraith/pkg/config"
+	"project-wraith/pkg/core"
+	"project-wraith/pkg/modules/mail"
+	"project-wraith/pkg/modules/notifier"
+)
+
+func TestNotifiers(t *testing.T) {
+	sct := &config.Secrets{}
+	ini := &config.Init{}
+	ini.Notify.Telegram.Enabled = true // No bot token, left out
+	ini.Notify.Telegram.Severity = "error"
+	ini.Notify.Email.Enabled = true
+	ini.Notify.Email.Severity = "warning"
+	ini.Notify.Email.Categories = []string{notifier.CategoryOps}
+	ini.Notify.Email.To = []string{"ops@example.com"}
+	ini.Notify.Email.Template = "notification.html"
+
+	mailMock := &mail.MockMail{}
+	mailMock.On("Send", mock.Anything, "notification.html", mock.Anything, mock.Anything, []string{"ops@example.com"}).Return(nil)
+
+	notifiers, err := core.Notifiers(sct, ini, mailMock)
+	require.NoError(t, err)
+
+	require.NoError(t, notifiers.Notify(notifier.Message{Severity: notifier.SeverityCritical, Category: notifier.CategoryErrors}))
+	require.NoError(t, notifiers.Notify(notifier.Message{Severity: notifier.SeverityInfo, Category: notifier.CategoryOps}))
+	require.NoError(t, notifiers.Notify(notifier.Message{Severity: notifier.SeverityWarning, Category: notifier.CategoryOps}))
+	mailMock.AssertNumberOfCalls(t, "Send", 1)
+
+	ini.Notify.Email.Severity = "loud"
+	_, err = core.Notifiers(sct, ini, mailMock)
+	assert.ErrorContains(t, err, "notify.email")
+}
@@ -0,0 +1,191 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"project-wraith/pkg/config"
+	"project-wraith/pkg/consts"
+	"project-wraith/pkg/internal/domain"
+	"project-wraith/pkg/internal/rules"
+	"project-wraith/pkg/modules/db"
+	"project-wraith/pkg/modules/logger"
+	"project-wraith/pkg/modules/notifier"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	opsPingTimeout  = 3 * time.Second
+	opsErrorsLimit  = 10
+	opsErrorsMaxArg = 50
+)
+
+// OpsOffsetCheckpoint is the checkpoint id holding the next update the ops bot
+// asks Telegram for.
+const OpsOffsetCheckpoint = "ops:telegram"
+
+// OpsBot builds the Telegram ops bot answering from the ini ops
+// allowed_chats. databases are pinged by /status under their map keys. The
+// update offset is kept in checkpoints, so commands are not run again after
+// a restart.
+func OpsBot(
+	sct *config.Secrets,
+	ini *config.Init,
+	log logger.Logger,
+	users rules.UserRule,
+	checkpoints domain.CheckpointRepository,
+	databases map[string]db.Client,
+	logsPath,
+	logsKey string,
+	started time.Time) (notifier.OpsBot, error) {
+
+	if sct.Notifiers.Bot.Token == "" {
+		return nil, errors.New("ops bot needs NOTIFIER_TLG_BOT_TOKEN")
+	}
+	if len(ini.Ops.AllowedChats) == 0 {
+		return nil, errors.New("ops bot needs allowed_chats")
+	}
+
+	bot := notifier.NewTelegramBot(ini.Notify.Telegram.BaseURL, sct.Notifiers.Bot.Token, sct.Notifiers.Bot.Chat)
+	ops := notifier.NewOpsBot(log, bot, ini.Ops.AllowedChats, ini.Ops.PollTimeout, opsOffsets{checkpoints: checkpoints})
+
+	ops.Handle("status", "", func(args []string) (string, error) {
+		version := "unknown"
+		if consts.AppManifest != nil {
+			version = consts.AppManifest.Version
+		}
+
+		lines := []string{
+			fmt.Sprintf("%s %s", consts.ServerName, version),
+			fmt.Sprintf("uptime: %s", time.Since(started).Round(time.Second)),
+		}
+
+		names := make([]string, 0, len(databases))
+		for name := range databases {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			lines = append(lines, fmt.Sprintf("db %s: %s", name, ping(databases[name])))
+		}
+
+		return strings.Join(lines, "\n"), nil
+	})
+
+	ops.Handle("user", "<email>", func(args []string) (string, error) {
+		if len(args) != 1 {
+			return "", errors.New("usage: /user <email>")
+		}
+
+		user, err := users.Get(context.Background(), rules.User{Email: args[0]})
+		if err != nil {
+			return "", err
+		}
+
+		return fmt.Sprintf("id: %s\nusername: %s\nname: %s\nstatus: %s",
+			user.ID, user.Username, user.Name, user.Status()), nil
+	})
+
+	ops.Handle("lock", "<id>", func(args []string) (string, error) {
+		if len(args) != 1 {
+			return "", errors.New("usage: /lock <id>")
+		}
+
+		if err := users.Lock(context.Background(), rules.User{ID: args[0]}); err != nil {
+			return "", err
+		}
+
+		return fmt.Sprintf("user %s locked", args[0]), nil
+	})
+
+	ops.Handle("unlock", "<id>", func(args []string) (string, error) {
+		if len(args) != 1 {
+			return "", errors.New("usage: /unlock <id>")
+		}
+
+		if err := users.Unlock(context.Background(), rules.User{ID: args[0]}); err != nil {
+			return "", err
+		}
+
+		return fmt.Sprintf("user %s unlocked", args[0]), nil
+	})
+
+	ops.Handle("errors", "[count]", func(args []string) (string, error) {
+		limit := opsErrorsLimit
+		if len(args) > 0 {
+			n, err := strconv.Atoi(args[0])
+			if err != nil || n < 1 || n > opsErrorsMaxArg {
+				return "", fmt.Errorf("count must be between 1 and %d", opsErrorsMaxArg)
+			}
+			limit = n
+		}
+
+		page, err := logger.Search(logsPath, logsKey, logger.Query{Levels: []string{"error"}, Limit: limit})
+		if err != nil {
+			return "", err
+		}
+
+		if len(page.Entries) == 0 {
+			return "no errors logged", nil
+		}
+
+		lines := make([]string, 0, len(page.Entries))
+		for _, entry := range page.Entries {
+			lines = append(lines, fmt.Sprintf("%v %v\n%v", entry["ts"], entry["caller"], entry["message"]))
+		}
+
+		return strings.Join(lines, "\n\n"), nil
+	})
+
+	return ops, nil
+}
+
+// opsOffsets keeps the ops bot offset as a checkpoint.
+type opsOffsets struct {
+	checkpoints domain.CheckpointRepository
+}
+
+func (o opsOffsets) Load(ctx context.Context) (int64, error) {
+	checkpoint, err := o.checkpoints.Get(ctx, OpsOffsetCheckpoint)
+	if err != nil || checkpoint == nil {
+		return 0, err
+	}
+
+	switch offset := checkpoint.After.(type) {
+	case int64:
+		return offset, nil
+	case int32:
+		return int64(offset), nil
+	default:
+		return 0, fmt.Errorf("invalid ops bot offset %v", checkpoint.After)
+	}
+}
+
+func (o opsOffsets) Save(ctx context.Context, offset int64) error {
+	return o.checkpoints.Save(ctx, domain.Checkpoint{
+		ID:        OpsOffsetCheckpoint,
+		After:     offset,
+		UpdatedAt: time.Now().UTC(),
+	})
+}
+
+func ping(client db.Client) string {
+	mongoClient := client.Client()
+	if mongoClient == nil {
+		return "not connected"
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), opsPingTimeout)
+	defer cancel()
+
+	start := time.Now()
+	if err := mongoClient.Ping(ctx, nil); err != nil {
+		return fmt.Sprintf("error: %v", err)
+	}
+
+	return fmt.Sprintf("ok (%s)", time.Since(start).Round(time.Millisecond))
+}
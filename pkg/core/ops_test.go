@@ -0,0 +1,109 @@
+package core_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/mongo"
+	"project-wraith/pkg/config"
+	"project-wraith/pkg/core"
+	"project-wraith/pkg/internal/domain"
+	"project-wraith/pkg/internal/rules"
+	"project-wraith/pkg/modules/db"
+	"project-wraith/pkg/modules/logger"
+	"project-wraith/pkg/modules/status"
+)
+
+func TestOpsBot(t *testing.T) {
+	folder := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(folder, "error.log"), []byte(
+		`{"ts":"2026-10-19T10:00:00.000Z","caller":"user.go:52","message":"insert failed"}`+"\n"), 0644))
+
+	var once sync.Once
+	answers := make(chan string, 10)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/sendMessage") {
+			var body map[string]string
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+			answers <- body["text"]
+			_, _ = w.Write([]byte(`{"ok":true}`))
+			return
+		}
+
+		result := "[]"
+		once.Do(func() {
+			result = `[
+				{"update_id": 1, "message": {"chat": {"id": 42}, "text": "/user alice@example.com"}},
+				{"update_id": 2, "message": {"chat": {"id": 42}, "text": "/errors"}},
+				{"update_id": 3, "message": {"chat": {"id": 42}, "text": "/status"}}
+			]`
+		})
+		time.Sleep(5 * time.Millisecond)
+		_, _ = w.Write([]byte(`{"ok":true,"result":` + result + `}`))
+	}))
+	defer server.Close()
+
+	sct := &config.Secrets{}
+	ini := &config.Init{}
+	ini.Notify.Telegram.BaseURL = server.URL
+	ini.Ops.PollTimeout = time.Second
+
+	logMock := &logger.MockLogger{}
+	logMock.On("Infow", mock.Anything).Return()
+	logMock.On("Warnw", mock.Anything).Return()
+
+	userMock := &rules.MockUserRule{}
+	alice := rules.User{ID: "u1", Username: "alice", Name: "Alice"}.WithStatus(status.Locked)
+	userMock.On("Get", mock.Anything, rules.User{Email: "alice@example.com"}).Return(&alice, nil)
+
+	checkpoints := &domain.MockCheckpointRepository{}
+	checkpoints.On("Get", mock.Anything, core.OpsOffsetCheckpoint).
+		Return(&domain.Checkpoint{ID: core.OpsOffsetCheckpoint, After: int64(1)}, nil)
+	checkpoints.On("Save", mock.Anything, mock.MatchedBy(func(checkpoint domain.Checkpoint) bool {
+		return checkpoint.ID == core.OpsOffsetCheckpoint && checkpoint.After == int64(4)
+	})).Return(nil)
+
+	dbMock := db.NewMockClient()
+	dbMock.On("Client").Return((*mongo.Client)(nil))
+
+	_, err := core.OpsBot(sct, ini, logMock, userMock, checkpoints, nil, folder, "", time.Now())
+	assert.ErrorContains(t, err, "NOTIFIER_TLG_BOT_TOKEN")
+
+	sct.Notifiers.Bot.Token = "token"
+	_, err = core.OpsBot(sct, ini, logMock, userMock, checkpoints, nil, folder, "", time.Now())
+	assert.ErrorContains(t, err, "allowed_chats")
+
+	ini.Ops.AllowedChats = []int64{42}
+	ops, err := core.OpsBot(sct, ini, logMock, userMock, checkpoints, map[string]db.Client{"user": dbMock}, folder, "", time.Now())
+	require.NoError(t, err)
+
+	stop := make(chan struct{})
+	defer close(stop)
+	go ops.Run(stop)
+
+	var got []string
+	for len(got) < 3 {
+		select {
+		case text := <-answers:
+			got = append(got, text)
+		case <-time.After(2 * time.Second):
+			t.Fatalf("only %d answers", len(got))
+		}
+	}
+
+	assert.Equal(t, "id: u1\nusername: alice\nname: Alice\nstatus: locked", got[0])
+	assert.Equal(t, "2026-10-19T10:00:00.000Z user.go:52\ninsert failed", got[1])
+	assert.Contains(t, got[2], "uptime: ")
+	assert.Contains(t, got[2], "db user: not connected")
+	checkpoints.AssertExpectations(t)
+}
@@ -0,0 +1,46 @@
+package core
+
+import (
+	"project-wraith/pkg/config"
+	"project-wraith/pkg/internal/gateway"
+	"project-wraith/pkg/modules/logger"
+	"sync/atomic"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Swappable runs the handler last stored, so a middleware can be rebuilt
+// when the configuration is reloaded.
+type Swappable struct {
+	handler atomic.Pointer[fiber.Handler]
+}
+
+func NewSwappable(handler fiber.Handler) *Swappable {
+	swappable := &Swappable{}
+	swappable.Store(handler)
+	return swappable
+}
+
+func (s *Swappable) Store(handler fiber.Handler) {
+	s.handler.Store(&handler)
+}
+
+func (s *Swappable) Handle(ctx *fiber.Ctx) error {
+	return (*s.handler.Load())(ctx)
+}
+
+// Reloads subscribes the log level, the CORS origins and the controller
+// settings to watcher.
+func Reloads(watcher config.Watcher, log logger.Logger, cors *Swappable, settings *gateway.Settings) {
+	watcher.Subscribe("logger", func(conf *config.Config) error {
+		return log.SetLevel(conf.Setup.Logger.Level)
+	})
+	watcher.Subscribe("cors", func(conf *config.Config) error {
+		cors.Store(CORS(conf.Setup.Server.CorsOrigins))
+		return nil
+	})
+	watcher.Subscribe("gateway", func(conf *config.Config) error {
+		settings.Set(conf.Setup.Server.CookiesMinutesLife, conf.Setup.Redirects.ResetUrl)
+		return nil
+	})
+}
@@ -3,33 +3,56 @@ package core
 import (
 	"fmt"
 	"github.com/gofiber/fiber/v2"
-	"github.com/gofiber/fiber/v2/middleware/monitor"
+	"github.com/gofiber/fiber/v2/middleware/adaptor"
 	"github.com/gofiber/swagger"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"project-wraith/pkg/internal/gateway"
+	"project-wraith/pkg/internal/rules"
 	"project-wraith/pkg/modules/guard"
 	"project-wraith/pkg/modules/logger"
+	"project-wraith/pkg/modules/metrics"
+	"time"
 )
 
 func Middleware(
 	app *fiber.App,
 	log logger.Logger,
 	paths map[string]string,
+	requestTimeout time.Duration,
 	serverApiKey,
+	scrapeToken,
 	jwtSecret,
 	cookiesSecret string,
-	manticore guard.Manticore) {
+	manticore guard.Manticore,
+	encryptResponse bool,
+	responseSecret string,
+	hybridKey string,
+	clients rules.ClientRule,
+	cors fiber.Handler) {
 
-	app.Use(CORS())
+	app.Use(Tracing())
+	app.Use(RequestID(log))
+	app.Use(RequestContext(requestTimeout))
+	app.Use(Metrics())
+	app.Use(cors)
 	app.Use(Compress())
 	app.Use(ETag())
 	app.Use(Helmet())
-	app.Use(Recover())
+	app.Use(Recover(log))
 	app.Use(CRSF())
 	app.Use(EncryptCookie(cookiesSecret))
+	app.Use(EncryptResponse(encryptResponse, responseSecret, clients))
+	app.Use(DecryptRequest(encryptResponse, responseSecret, hybridKey))
 
 	for key, path := range paths {
-		if key != "hello" {
-			app.Use(path, KeyAuth(serverApiKey))
+		if key == "metrics" {
+			app.Use(path, ScrapeToken(scrapeToken))
+		}
+
+		switch key {
+		case "hello", "healthz", "readyz":
+		default:
+			app.Use(path, KeyAuth(serverApiKey, clients))
 		}
 
 		switch key {
@@ -43,6 +66,8 @@ func Middleware(
 			app.Use(path, ManticoreSight(manticore, log))
 		case "swagger":
 			app.Use(path, ManticoreSight(manticore, log))
+		case "clients":
+			app.Use(path, ManticoreSight(manticore, log))
 		default:
 			continue
 		}
@@ -55,18 +80,25 @@ func EnRoute(
 	user gateway.UserController,
 	auth gateway.AuthController,
 	reset gateway.ResetController,
-	statics gateway.StaticsController) {
+	statics gateway.StaticsController,
+	logs gateway.LogsController,
+	clients gateway.ClientController,
+	health gateway.HealthController) {
 
 	for key, path := range paths {
 		switch key {
 		case "hello":
 			app.Get(path, statics.HelloHuman)
+		case "healthz":
+			app.Get(path, health.Live)
+		case "readyz":
+			app.Get(path, health.Ready)
 		case "logs":
 			app.Get(path, statics.LogReport)
+			app.Get(fmt.Sprintf("%s/entries", path), logs.Search)
+			app.Get(fmt.Sprintf("%s/tail", path), logs.Tail)
 		case "metrics":
-			app.Get(paths["metrics"], monitor.New(monitor.Config{
-				Title: "project-wraith metrics",
-			}))
+			app.Get(path, adaptor.HTTPHandler(promhttp.HandlerFor(metrics.Registry, promhttp.HandlerOpts{})))
 		case "swagger":
 			app.Get(path, swagger.HandlerDefault)
 		case "auth":
@@ -83,6 +115,9 @@ func EnRoute(
 			usersGroup.Get("/detail/:id", user.Get)
 			usersGroup.Put("/edit", user.Edit)
 			usersGroup.Delete("/disable", user.Disable)
+		case "clients":
+			clientsGroup := app.Group(path)
+			clientsGroup.Post("/register", clients.Register)
 		default:
 			continue
 		}
@@ -0,0 +1,56 @@
+package core
+
+import (
+	"context"
+	"project-wraith/pkg/config"
+	"project-wraith/pkg/consts"
+	"project-wraith/pkg/modules/lifecycle"
+	"project-wraith/pkg/modules/logger"
+	"project-wraith/pkg/modules/tracing"
+	"time"
+
+	"go.opentelemetry.io/otel"
+)
+
+const tracingFlushTimeout = 5 * time.Second
+
+// TracingHook sets up tracing on start and flushes the pending spans on stop.
+func TracingHook(ini *config.Init, log logger.Logger) lifecycle.Hook {
+	var shutdown func(ctx context.Context) error
+
+	return lifecycle.Hook{
+		Name: "tracing",
+		Start: func(ctx context.Context) (err error) {
+			shutdown, err = SetupTracing(ini, log)
+			return err
+		},
+		Stop: func(ctx context.Context) error {
+			ctx, cancel := context.WithTimeout(ctx, tracingFlushTimeout)
+			defer cancel()
+
+			return shutdown(ctx)
+		},
+	}
+}
+
+// SetupTracing exports spans to the ini tracing endpoint over OTLP/HTTP.
+// Export failures are only warned about, so they never reach the error
+// notifiers.
+func SetupTracing(ini *config.Init, log logger.Logger) (func(ctx context.Context) error, error) {
+	version := "unknown"
+	if consts.AppManifest != nil {
+		version = consts.AppManifest.Version
+	}
+
+	otel.SetErrorHandler(otel.ErrorHandlerFunc(func(err error) {
+		log.Warn("tracing: %v", err)
+	}))
+
+	return tracing.Setup(tracing.Config{
+		Endpoint:    ini.Tracing.Endpoint,
+		Insecure:    ini.Tracing.Insecure,
+		SampleRatio: ini.Tracing.SampleRatio,
+		ServiceName: ini.Tracing.ServiceName,
+		Version:     version,
+	})
+}
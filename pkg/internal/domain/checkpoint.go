@@ -0,0 +1,67 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"project-wraith/pkg/modules/db"
+	"time"
+)
+
+type CheckpointRepository interface {
+	Get(ctx context.Context, id string) (*Checkpoint, error)
+	Save(ctx context.Context, checkpoint Checkpoint) error
+}
+
+type checkpointRepository struct {
+	collection *mongo.Collection
+	timeout    time.Duration
+}
+
+func NewCheckpointRepository(collection mongo.Collection, timeout time.Duration) CheckpointRepository {
+	return &checkpointRepository{
+		collection: &collection,
+		timeout:    timeout,
+	}
+}
+
+// Get returns the checkpoint with the given id, or nil when none was saved yet.
+func (r *checkpointRepository) Get(ctx context.Context, id string) (*Checkpoint, error) {
+	ctx, cancel := db.Operation(ctx, r.timeout)
+	defer cancel()
+
+	var checkpoint Checkpoint
+	done := observe(ctx, r.collection, "get")
+	err := r.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&checkpoint)
+	err = done(err)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read checkpoint: %w", err)
+	}
+
+	return &checkpoint, nil
+}
+
+func (r *checkpointRepository) Save(ctx context.Context, checkpoint Checkpoint) error {
+	ctx, cancel := db.Operation(ctx, r.timeout)
+	defer cancel()
+
+	done := observe(ctx, r.collection, "save")
+	_, err := r.collection.ReplaceOne(
+		ctx,
+		bson.M{"_id": checkpoint.ID},
+		checkpoint,
+		options.Replace().SetUpsert(true),
+	)
+	err = done(err)
+	if err != nil {
+		return fmt.Errorf("failed to save checkpoint: %w", err)
+	}
+
+	return nil
+}
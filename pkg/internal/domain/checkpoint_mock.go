@@ -0,0 +1,19 @@
+package domain
+
+import (
+	"context"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockCheckpointRepository struct {
+	mock.Mock
+}
+
+func (m *MockCheckpointRepository) Get(ctx context.Context, id string) (*Checkpoint, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(*Checkpoint), args.Error(1)
+}
+
+func (m *MockCheckpointRepository) Save(ctx context.Context, checkpoint Checkpoint) error {
+	return m.Called(ctx, checkpoint).Error(0)
+}
@@ -0,0 +1,97 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"project-wraith/pkg/modules/db"
+	"time"
+)
+
+var ErrClientNotFound = errors.New("client not found")
+
+type ClientRepository interface {
+	Get(ctx context.Context, client Client) (*Client, error)
+	Save(ctx context.Context, client Client) error
+}
+
+type clientRepository struct {
+	collection *mongo.Collection
+	timeout    time.Duration
+}
+
+func NewClientRepository(collection mongo.Collection, timeout time.Duration) ClientRepository {
+	return &clientRepository{
+		collection: &collection,
+		timeout:    timeout,
+	}
+}
+
+// Get looks a client up by ID, or by API key when no ID is given.
+func (r *clientRepository) Get(ctx context.Context, client Client) (*Client, error) {
+	filter := bson.M{}
+
+	switch {
+	case client.ID != "":
+		filter["_id"] = client.ID
+	case client.ApiKey != "":
+		filter["apiKey"] = client.ApiKey
+	default:
+		return nil, errors.New("client ID or API key is required")
+	}
+
+	ctx, cancel := db.Operation(ctx, r.timeout)
+	defer cancel()
+
+	done := observe(ctx, r.collection, "get")
+	err := r.collection.FindOne(ctx, filter).Decode(&client)
+	err = done(err)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrClientNotFound
+		}
+		return nil, err
+	}
+
+	return &client, nil
+}
+
+// Save creates the client or replaces its name, key and API key, keeping the
+// original creation date.
+func (r *clientRepository) Save(ctx context.Context, client Client) error {
+	if client.ID == "" {
+		return errors.New("client ID is required")
+	}
+
+	update := bson.M{
+		"$set": bson.M{
+			"name":      client.Name,
+			"apiKey":    client.ApiKey,
+			"publicKey": client.PublicKey,
+			"updatedAt": client.UpdatedAt,
+		},
+		"$setOnInsert": bson.M{
+			"createdAt": client.CreatedAt,
+		},
+	}
+
+	ctx, cancel := db.Operation(ctx, r.timeout)
+	defer cancel()
+
+	done := observe(ctx, r.collection, "save")
+	_, err := r.collection.UpdateOne(
+		ctx,
+		bson.M{"_id": client.ID},
+		update,
+		options.Update().SetUpsert(true),
+	)
+	err = done(err)
+	if err != nil {
+		return fmt.Errorf("failed to save client: %w", err)
+	}
+
+	return nil
+}
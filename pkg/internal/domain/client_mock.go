@@ -0,0 +1,19 @@
+package domain
+
+import (
+	"context"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockClientRepository struct {
+	mock.Mock
+}
+
+func (m *MockClientRepository) Get(ctx context.Context, client Client) (*Client, error) {
+	args := m.Called(ctx, client)
+	return args.Get(0).(*Client), args.Error(1)
+}
+
+func (m *MockClientRepository) Save(ctx context.Context, client Client) error {
+	return m.Called(ctx, client).Error(0)
+}
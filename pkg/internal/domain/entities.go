@@ -2,15 +2,46 @@ package domain
 
 import "time"
 
+// User is the stored user document. The alchemy tags declare which fields are
+// protected when encrypt_db_data is enabled: index fields are encrypted
+// deterministically so they can still be used in lookups.
 type User struct {
-	ID        string
-	Username  string
-	Email     string
-	Name      string
-	Phone     string
-	Password  string
+	ID        string `alchemy:"-"`
+	Username  string `alchemy:"index"`
+	Email     string `alchemy:"index"`
+	Name      string `alchemy:"encrypt"`
+	Phone     string `alchemy:"index"`
+	Password  string `alchemy:"-"`
 	CreatedAt time.Time
 	UpdatedAt time.Time
-	Status    string
-	Meta      map[string]interface{}
+	Status    string                 `alchemy:"-"`
+	Meta      map[string]interface{} `alchemy:"encrypt"`
+}
+
+// UserRecord is a stored user together with its raw document key, used by
+// maintenance jobs that walk the whole collection.
+type UserRecord struct {
+	Key  interface{} `bson:"_id" alchemy:"-"`
+	User `bson:",inline"`
+}
+
+// Checkpoint records how far a resumable maintenance job got.
+type Checkpoint struct {
+	ID        string      `bson:"_id"`
+	Target    string      `bson:"target"`
+	After     interface{} `bson:"after"`
+	Scanned   int         `bson:"scanned"`
+	Converted int         `bson:"converted"`
+	Done      bool        `bson:"done"`
+	UpdatedAt time.Time   `bson:"updatedAt"`
+}
+
+// Client is an API consumer that registered a key to receive sealed responses.
+type Client struct {
+	ID        string    `bson:"_id"`
+	Name      string    `bson:"name"`
+	ApiKey    string    `bson:"apiKey,omitempty"`
+	PublicKey string    `bson:"publicKey"`
+	CreatedAt time.Time `bson:"createdAt"`
+	UpdatedAt time.Time `bson:"updatedAt"`
 }
@@ -0,0 +1,59 @@
+package domain
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// DuplicateError is returned when a write runs into a unique index. Fields
+// names the duplicated document fields, id for the document key.
+type DuplicateError struct {
+	Fields []string
+}
+
+func (e *DuplicateError) Error() string {
+	return "duplicated " + strings.Join(e.Fields, ", ")
+}
+
+var dupKey = regexp.MustCompile(`dup key: \{ ?([A-Za-z_.]+):`)
+
+// duplicateError reads the duplicated fields from the keyPattern of the write
+// errors, or from their message on servers that do not send it.
+func duplicateError(err error) *DuplicateError {
+	var writeErr mongo.WriteException
+	if !errors.As(err, &writeErr) {
+		return &DuplicateError{}
+	}
+
+	var fields []string
+	for _, we := range writeErr.WriteErrors {
+		if we.Code != 11000 {
+			continue
+		}
+
+		if pattern, ok := we.Raw.Lookup("keyPattern").DocumentOK(); ok {
+			elements, _ := pattern.Elements()
+			for _, element := range elements {
+				fields = append(fields, field(element.Key()))
+			}
+			continue
+		}
+
+		if match := dupKey.FindStringSubmatch(we.Message); match != nil {
+			fields = append(fields, field(match[1]))
+		}
+	}
+
+	return &DuplicateError{Fields: fields}
+}
+
+func field(key string) string {
+	if key == "_id" {
+		return "id"
+	}
+
+	return key
+}
@@ -0,0 +1,36 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"project-wraith/pkg/modules/db"
+	"project-wraith/pkg/modules/metrics"
+	"project-wraith/pkg/modules/tracing"
+
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// observe times a repository operation as a metric and as a span under the
+// one in ctx; call the returned func with the operation error, which it
+// returns marked by db.Interrupted.
+func observe(ctx context.Context, collection *mongo.Collection, operation string) func(err error) error {
+	_, span := tracing.Start(ctx, "mongo "+collection.Name()+"."+operation,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("db.system", "mongodb"),
+			attribute.String("db.collection.name", collection.Name()),
+			attribute.String("db.operation.name", operation)))
+	done := metrics.Mongo(collection.Name(), operation)
+
+	return func(err error) error {
+		done(err)
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			tracing.End(span, nil)
+			return err
+		}
+		tracing.End(span, err)
+		return db.Interrupted(err)
+	}
+}
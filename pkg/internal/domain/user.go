@@ -7,29 +7,35 @@ import (
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
+	"project-wraith/pkg/modules/db"
+	"strings"
+	"time"
 )
 
 type UserRepository interface {
-	Get(user User) (*User, error)
-	Create(user User) error
-	Update(user User) error
-	Delete(id string) error
-	Duplicated(user User) ([]User, error)
+	Get(ctx context.Context, user User) (*User, error)
+	Create(ctx context.Context, user User) error
+	Update(ctx context.Context, user User) error
+	Delete(ctx context.Context, id string) error
+	Conflicts(ctx context.Context, user User) ([]string, error)
+	Transaction(ctx context.Context, fn func(ctx context.Context) error) error
+	Batch(ctx context.Context, after interface{}, size int) ([]UserRecord, error)
+	Rewrite(ctx context.Context, record UserRecord) error
 }
 
 type userRepository struct {
 	collection *mongo.Collection
-	ctx        context.Context
+	timeout    time.Duration
 }
 
-func NewUserRepository(collection mongo.Collection, ctx context.Context) UserRepository {
+func NewUserRepository(collection mongo.Collection, timeout time.Duration) UserRepository {
 	return &userRepository{
 		collection: &collection,
-		ctx:        ctx,
+		timeout:    timeout,
 	}
 }
 
-func (r *userRepository) Get(user User) (*User, error) {
+func (r *userRepository) Get(ctx context.Context, user User) (*User, error) {
 	filter := bson.M{}
 
 	if user.ID != "" {
@@ -45,7 +51,12 @@ func (r *userRepository) Get(user User) (*User, error) {
 		filter["phone"] = user.Phone
 	}
 
-	err := r.collection.FindOne(r.ctx, filter).Decode(&user)
+	ctx, cancel := db.Operation(ctx, r.timeout)
+	defer cancel()
+
+	done := observe(ctx, r.collection, "get")
+	err := r.collection.FindOne(ctx, filter).Decode(&user)
+	err = done(err)
 	if err != nil {
 		if errors.Is(err, mongo.ErrNoDocuments) {
 			return nil, fmt.Errorf("user not found (%e)", err)
@@ -56,12 +67,20 @@ func (r *userRepository) Get(user User) (*User, error) {
 	return &user, nil
 }
 
-func (r *userRepository) Create(user User) error {
-	_, err := r.collection.InsertOne(r.ctx, user)
+func (r *userRepository) Create(ctx context.Context, user User) error {
+	ctx, cancel := db.Operation(ctx, r.timeout)
+	defer cancel()
+
+	done := observe(ctx, r.collection, "create")
+	_, err := r.collection.InsertOne(ctx, user)
+	err = done(err)
+	if mongo.IsDuplicateKeyError(err) {
+		return duplicateError(err)
+	}
 	return err
 }
 
-func (r *userRepository) Update(user User) error {
+func (r *userRepository) Update(ctx context.Context, user User) error {
 	if user.ID == "" {
 		return errors.New("user ID is required")
 	}
@@ -105,14 +124,22 @@ func (r *userRepository) Update(user User) error {
 		"$set": toUpdate,
 	}
 
+	ctx, cancel := db.Operation(ctx, r.timeout)
+	defer cancel()
+
 	// Perform the update operation
+	done := observe(ctx, r.collection, "update")
 	_, err := r.collection.UpdateOne(
-		r.ctx,
+		ctx,
 		filter,
 		update,
 		options.Update().SetUpsert(false),
 	)
+	err = done(err)
 
+	if mongo.IsDuplicateKeyError(err) {
+		return duplicateError(err)
+	}
 	if err != nil {
 		return fmt.Errorf("failed to update user: %w", err)
 	}
@@ -120,10 +147,15 @@ func (r *userRepository) Update(user User) error {
 	return nil
 }
 
-func (r *userRepository) Delete(id string) error {
+func (r *userRepository) Delete(ctx context.Context, id string) error {
 	filter := bson.M{"_id": id}
 
-	result, err := r.collection.DeleteOne(r.ctx, filter)
+	ctx, cancel := db.Operation(ctx, r.timeout)
+	defer cancel()
+
+	done := observe(ctx, r.collection, "delete")
+	result, err := r.collection.DeleteOne(ctx, filter)
+	err = done(err)
 	if err != nil {
 		return err
 	}
@@ -134,51 +166,139 @@ func (r *userRepository) Delete(id string) error {
 	return nil
 }
 
-func (r *userRepository) Duplicated(user User) ([]User, error) {
-	filter := bson.M{}
-
-	// Add filters based on provided user details
-	if user.Username != "" {
-		filter["username"] = user.Username
+// Conflicts returns the identifiers of user, among username, email and
+// phone, that another user already has. Each one is checked on its own.
+func (r *userRepository) Conflicts(ctx context.Context, user User) ([]string, error) {
+	identifiers := bson.D{
+		{Key: "username", Value: user.Username},
+		{Key: "email", Value: user.Email},
+		{Key: "phone", Value: user.Phone},
 	}
-	if user.Email != "" {
-		filter["email"] = user.Email
+
+	var or bson.A
+	for _, identifier := range identifiers {
+		if identifier.Value != "" {
+			or = append(or, bson.D{identifier})
+		}
 	}
-	if user.Phone != "" {
-		filter["phone"] = user.Phone
+
+	if len(or) == 0 {
+		return nil, nil
 	}
 
-	// Use Find to retrieve potential duplicates
-	cursor, err := r.collection.Find(r.ctx, filter)
+	opts := options.Find().SetProjection(bson.M{"username": 1, "email": 1, "phone": 1})
+
+	ctx, cancel := db.Operation(ctx, r.timeout)
+	defer cancel()
+
+	done := observe(ctx, r.collection, "conflicts")
+	cursor, err := r.collection.Find(ctx, bson.M{"$or": or}, opts)
+	err = done(err)
 	if err != nil {
-		return nil, fmt.Errorf("failed to find duplicates: %w", err)
+		return nil, fmt.Errorf("failed to find conflicts: %w", err)
 	}
 
-	var duplicates []User
+	var existing []User
+	if err := cursor.All(ctx, &existing); err != nil {
+		return nil, fmt.Errorf("failed to decode conflicts: %w", db.Interrupted(err))
+	}
 
-	// Decode the results into the duplicates slice
-	for cursor.Next(r.ctx) {
-		var existingUser User
-		if err := cursor.Decode(&existingUser); err != nil {
-			return nil, fmt.Errorf("failed to decode user: %w", err)
+	var conflicts []string
+	for _, identifier := range identifiers {
+		for _, other := range existing {
+			value := map[string]string{"username": other.Username, "email": other.Email, "phone": other.Phone}
+			if identifier.Value != "" && value[identifier.Key] == identifier.Value {
+				conflicts = append(conflicts, identifier.Key)
+				break
+			}
 		}
-		duplicates = append(duplicates, existingUser)
 	}
 
-	// Check for any cursor error
-	if err := cursor.Err(); err != nil {
-		return nil, fmt.Errorf("cursor error: %w", err)
+	return conflicts, nil
+}
+
+// Transaction runs fn in a session transaction, retried by the driver on
+// transient errors; fn must use the context it is given. Standalone servers
+// have no transactions, so there fn runs on its own and the unique indexes
+// remain the only guard.
+func (r *userRepository) Transaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	session, err := r.collection.Database().Client().StartSession()
+	if err != nil {
+		return err
+	}
+	defer session.EndSession(ctx)
+
+	_, err = session.WithTransaction(ctx, func(sessionCtx mongo.SessionContext) (interface{}, error) {
+		return nil, fn(sessionCtx)
+	})
+	if transactionsUnsupported(err) {
+		return fn(ctx)
+	}
+
+	return err
+}
+
+func transactionsUnsupported(err error) bool {
+	var commandErr mongo.CommandError
+	return errors.As(err, &commandErr) &&
+		commandErr.Code == 20 &&
+		strings.Contains(commandErr.Message, "Transaction numbers are only allowed")
+}
+
+func (r *userRepository) Batch(ctx context.Context, after interface{}, size int) ([]UserRecord, error) {
+	filter := bson.M{}
+	if after != nil {
+		filter["_id"] = bson.M{"$gt": after}
+	}
+
+	opts := options.Find().
+		SetSort(bson.D{{Key: "_id", Value: 1}}).
+		SetLimit(int64(size))
+
+	ctx, cancel := db.Operation(ctx, r.timeout)
+	defer cancel()
+
+	done := observe(ctx, r.collection, "batch")
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	err = done(err)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read batch: %w", err)
+	}
+
+	var records []UserRecord
+	if err := cursor.All(ctx, &records); err != nil {
+		return nil, fmt.Errorf("failed to decode batch: %w", db.Interrupted(err))
+	}
+
+	return records, nil
+}
+
+func (r *userRepository) Rewrite(ctx context.Context, record UserRecord) error {
+	if record.Key == nil {
+		return errors.New("document key is required")
 	}
 
-	// Return an error if duplicates are found
-	if len(duplicates) > 0 {
-		return duplicates, nil
+	// Only the fields alchemy may touch are rewritten, so a concurrent edit
+	// of anything else is not clobbered.
+	update := bson.M{
+		"$set": bson.M{
+			"username": record.Username,
+			"email":    record.Email,
+			"name":     record.Name,
+			"phone":    record.Phone,
+			"meta":     record.Meta,
+		},
 	}
 
-	err = cursor.Close(r.ctx)
+	ctx, cancel := db.Operation(ctx, r.timeout)
+	defer cancel()
+
+	done := observe(ctx, r.collection, "rewrite")
+	_, err := r.collection.UpdateOne(ctx, bson.M{"_id": record.Key}, update)
+	err = done(err)
 	if err != nil {
-		return nil, fmt.Errorf("failed to close cursor: %w", err)
+		return fmt.Errorf("failed to rewrite user: %w", err)
 	}
 
-	return nil, nil // No duplicates found
+	return nil
 }
@@ -1,6 +1,8 @@
 package domain
 
 import (
+	"context"
+
 	"github.com/stretchr/testify/mock"
 )
 
@@ -8,24 +10,39 @@ type MockUserRepository struct {
 	mock.Mock
 }
 
-func (m *MockUserRepository) Duplicated(user User) ([]User, error) {
-	args := m.Called(user)
-	return args.Get(0).([]User), args.Error(1)
+func (m *MockUserRepository) Conflicts(ctx context.Context, user User) ([]string, error) {
+	args := m.Called(ctx, user)
+	return args.Get(0).([]string), args.Error(1)
+}
+
+// Transaction runs fn right away, as a standalone server would.
+func (m *MockUserRepository) Transaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	m.Called(ctx)
+	return fn(ctx)
 }
 
-func (m *MockUserRepository) Get(user User) (*User, error) {
-	args := m.Called(user)
+func (m *MockUserRepository) Get(ctx context.Context, user User) (*User, error) {
+	args := m.Called(ctx, user)
 	return args.Get(0).(*User), args.Error(1)
 }
 
-func (m *MockUserRepository) Create(user User) error {
-	return m.Called(user).Error(0)
+func (m *MockUserRepository) Create(ctx context.Context, user User) error {
+	return m.Called(ctx, user).Error(0)
+}
+
+func (m *MockUserRepository) Update(ctx context.Context, user User) error {
+	return m.Called(ctx, user).Error(0)
+}
+
+func (m *MockUserRepository) Delete(ctx context.Context, id string) error {
+	return m.Called(ctx, id).Error(0)
 }
 
-func (m *MockUserRepository) Update(user User) error {
-	return m.Called(user).Error(0)
+func (m *MockUserRepository) Batch(ctx context.Context, after interface{}, size int) ([]UserRecord, error) {
+	args := m.Called(ctx, after, size)
+	return args.Get(0).([]UserRecord), args.Error(1)
 }
 
-func (m *MockUserRepository) Delete(id string) error {
-	return m.Called(id).Error(0)
+func (m *MockUserRepository) Rewrite(ctx context.Context, record UserRecord) error {
+	return m.Called(ctx, record).Error(0)
 }
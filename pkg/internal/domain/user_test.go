@@ -6,7 +6,9 @@ import (
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
 	"project-wraith/pkg/internal/domain"
+	"project-wraith/pkg/modules/db"
 	"testing"
+	"time"
 )
 
 func TestUserRepository(test *testing.T) {
@@ -72,12 +74,12 @@ func TestUserRepository(test *testing.T) {
 			mongoTest.Parallel()
 
 			collection := mongoTest.Coll
-			repo := domain.NewUserRepository(*collection, context.TODO())
+			repo := domain.NewUserRepository(*collection, time.Second)
 
 			switch tc.action {
 			case "create":
 				mongoTest.AddMockResponses(mtest.CreateSuccessResponse())
-				err := repo.Create(tc.user)
+				err := repo.Create(context.Background(), tc.user)
 				assert.Equal(test, tc.expectedErr, err)
 
 			case "get":
@@ -86,22 +88,157 @@ func TestUserRepository(test *testing.T) {
 					{"username", tc.user.Username},
 					{"email", tc.user.Email},
 				}))
-				result, err := repo.Get(tc.query)
+				result, err := repo.Get(context.Background(), tc.query)
 				assert.Equal(test, tc.expectedErr, err)
 				assert.Equal(test, &tc.user, result)
 
 			case "update":
 				mongoTest.AddMockResponses(mtest.CreateSuccessResponse())
-				err := repo.Update(tc.query)
+				err := repo.Update(context.Background(), tc.query)
 				assert.Equal(test, tc.expectedErr, err)
 
 			case "delete":
 				mongoTest.AddMockResponses(mtest.CreateSuccessResponse(
 					bson.E{Key: "n", Value: int32(1)},
 				))
-				err := repo.Delete(tc.query.ID)
+				err := repo.Delete(context.Background(), tc.query.ID)
 				assert.Equal(test, tc.expectedErr, err)
 			}
 		})
 	}
 }
+
+func TestUserRepositoryConflicts(test *testing.T) {
+	mt := mtest.New(test, mtest.NewOptions().ClientType(mtest.Mock))
+
+	mt.Run("Conflicts are reported per identifier", func(mongoTest *mtest.T) {
+		repo := domain.NewUserRepository(*mongoTest.Coll, time.Second)
+
+		mongoTest.AddMockResponses(mtest.CreateCursorResponse(0, "db.users", mtest.FirstBatch,
+			bson.D{{Key: "username", Value: "other"}, {Key: "email", Value: "taken@example.com"}},
+			bson.D{{Key: "username", Value: "someone"}, {Key: "phone", Value: "555"}},
+		))
+
+		conflicts, err := repo.Conflicts(context.Background(), domain.User{
+			Username: "free",
+			Email:    "taken@example.com",
+			Phone:    "555",
+		})
+		assert.NoError(mongoTest, err)
+		assert.Equal(mongoTest, []string{"email", "phone"}, conflicts)
+
+		filter := mongoTest.GetStartedEvent().Command.Lookup("filter", "$or").Array()
+		values, _ := filter.Values()
+		assert.Len(mongoTest, values, 3)
+	})
+
+	mt.Run("Duplicate key on create", func(mongoTest *mtest.T) {
+		repo := domain.NewUserRepository(*mongoTest.Coll, time.Second)
+
+		mongoTest.AddMockResponses(mtest.CreateWriteErrorsResponse(mtest.WriteError{
+			Index:   0,
+			Code:    11000,
+			Message: `E11000 duplicate key error collection: db.users index: users_email_unique dup key: { email: "taken@example.com" }`,
+		}))
+
+		err := repo.Create(context.Background(), domain.User{Email: "taken@example.com"})
+		assert.Equal(mongoTest, &domain.DuplicateError{Fields: []string{"email"}}, err)
+	})
+
+	mt.Run("Transaction falls back on standalone servers", func(mongoTest *mtest.T) {
+		repo := domain.NewUserRepository(*mongoTest.Coll, time.Second)
+
+		mongoTest.AddMockResponses(
+			mtest.CreateCommandErrorResponse(mtest.CommandError{
+				Code:    20,
+				Name:    "IllegalOperation",
+				Message: "Transaction numbers are only allowed on a replica set member or mongos",
+			}),
+			mtest.CreateSuccessResponse(), // abortTransaction
+			mtest.CreateSuccessResponse(),
+		)
+
+		calls := 0
+		err := repo.Transaction(context.Background(), func(ctx context.Context) error {
+			calls++
+			return repo.Create(ctx, domain.User{Username: "new"})
+		})
+		assert.NoError(mongoTest, err)
+		assert.Equal(mongoTest, 2, calls)
+	})
+}
+
+func TestUserRepositoryInterrupted(test *testing.T) {
+	mt := mtest.New(test, mtest.NewOptions().ClientType(mtest.Mock))
+
+	mt.Run("Canceled request", func(mongoTest *mtest.T) {
+		repo := domain.NewUserRepository(*mongoTest.Coll, time.Second)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		_, err := repo.Get(ctx, domain.User{ID: "123"})
+		assert.ErrorIs(mongoTest, err, db.ErrCanceled)
+		assert.ErrorIs(mongoTest, err, context.Canceled)
+	})
+
+	mt.Run("Every query uses the caller context", func(mongoTest *mtest.T) {
+		repo := domain.NewUserRepository(*mongoTest.Coll, time.Second)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		calls := map[string]func() error{
+			"Get": func() error {
+				_, err := repo.Get(ctx, domain.User{ID: "123"})
+				return err
+			},
+			"Create": func() error {
+				return repo.Create(ctx, domain.User{ID: "123"})
+			},
+			"Update": func() error {
+				return repo.Update(ctx, domain.User{ID: "123", Name: "new"})
+			},
+			"Delete": func() error {
+				return repo.Delete(ctx, "123")
+			},
+			"Conflicts": func() error {
+				_, err := repo.Conflicts(ctx, domain.User{Username: "taken"})
+				return err
+			},
+			"Batch": func() error {
+				_, err := repo.Batch(ctx, nil, 10)
+				return err
+			},
+			"Rewrite": func() error {
+				return repo.Rewrite(ctx, domain.UserRecord{Key: "123"})
+			},
+		}
+
+		for name, call := range calls {
+			assert.ErrorIs(mongoTest, call(), context.Canceled, name)
+		}
+	})
+
+	mt.Run("Operation timeout", func(mongoTest *mtest.T) {
+		repo := domain.NewUserRepository(*mongoTest.Coll, time.Nanosecond)
+
+		err := repo.Update(context.Background(), domain.User{ID: "123", Name: "slow"})
+		assert.ErrorIs(mongoTest, err, db.ErrTimeout)
+		assert.NotErrorIs(mongoTest, err, db.ErrCanceled)
+	})
+
+	mt.Run("Failures are not interruptions", func(mongoTest *mtest.T) {
+		repo := domain.NewUserRepository(*mongoTest.Coll, time.Second)
+
+		mongoTest.AddMockResponses(mtest.CreateCommandErrorResponse(mtest.CommandError{
+			Code:    8000,
+			Message: "boom",
+		}))
+
+		err := repo.Delete(context.Background(), "123")
+		assert.Error(mongoTest, err)
+		assert.NotErrorIs(mongoTest, err, db.ErrCanceled)
+		assert.NotErrorIs(mongoTest, err, db.ErrTimeout)
+	})
+}
@@ -5,6 +5,7 @@ import (
 	"project-wraith/pkg/internal/rules"
 	"project-wraith/pkg/modules/link"
 	"project-wraith/pkg/modules/logger"
+	"project-wraith/pkg/modules/metrics"
 	"project-wraith/pkg/modules/token"
 	"time"
 )
@@ -15,23 +16,23 @@ type AuthController interface {
 }
 
 type authController struct {
-	log                logger.Logger
-	rules              rules.UserRule
-	jwtSecret          string
-	cookiesMinutesLife time.Duration
+	log       logger.Logger
+	rules     rules.UserRule
+	jwtSecret string
+	settings  *Settings
 }
 
 func NewAuthController(
 	log logger.Logger,
 	rules rules.UserRule,
 	jwtSecret string,
-	cookiesMinutesLife int,
+	settings *Settings,
 ) AuthController {
 	return &authController{
-		log:                log,
-		rules:              rules,
-		jwtSecret:          jwtSecret,
-		cookiesMinutesLife: time.Duration(cookiesMinutesLife) * time.Minute,
+		log:       log,
+		rules:     rules,
+		jwtSecret: jwtSecret,
+		settings:  settings,
 	}
 }
 
@@ -49,9 +50,11 @@ func NewAuthController(
 // @Failure 500 {object} error "Internal server error"
 // @Security ApiKeyAuth
 func (ac authController) Login(ctx *fiber.Ctx) error {
+	log := requestLog(ctx, ac.log)
+
 	req := User{}
 	if err := ctx.BodyParser(&req); err != nil {
-		ac.log.Error("failed to parse request: %v", err)
+		log.Error("failed to parse request: %v", err)
 		return ctx.Status(fiber.StatusBadRequest).JSON(link.Response{
 			Message: "failed to parse request",
 		})
@@ -66,18 +69,24 @@ func (ac authController) Login(ctx *fiber.Ctx) error {
 		Password: req.Password,
 	}
 
-	res, err := ac.rules.Login(actor)
+	res, err := ac.rules.Login(ctx.UserContext(), actor)
+	metrics.Logins.WithLabelValues(metrics.Outcome(err)).Inc()
+	if interrupted(err) {
+		log.Warn("failed to login: %v", err)
+		return interruptedResponse(ctx, err)
+	}
 	if err != nil {
-		ac.log.Error("failed to login: %v", err)
+		log.Error("failed to login: %v", err)
 		return ctx.Status(fiber.StatusUnauthorized).JSON(link.Response{
 			Message: err.Error(),
 		})
 	}
 
+	cookiesLife := ac.settings.CookiesLife()
 	userSession, err := token.CreateJwtToken(
-		ac.jwtSecret, ac.cookiesMinutesLife, res)
+		ac.jwtSecret, cookiesLife, res)
 	if err != nil {
-		ac.log.Error("failed to create token token: %v", err)
+		log.Error("failed to create token token: %v", err)
 		return ctx.Status(fiber.StatusInternalServerError).JSON(link.Response{
 			Message: err.Error(),
 		})
@@ -86,12 +95,12 @@ func (ac authController) Login(ctx *fiber.Ctx) error {
 	ctx.Cookie(&fiber.Cookie{
 		Name:     "user_session",
 		Value:    userSession,
-		Expires:  time.Now().Add(ac.cookiesMinutesLife),
+		Expires:  time.Now().Add(cookiesLife),
 		HTTPOnly: true,
 		Secure:   true,
 	})
 
-	ac.log.Info("action done: login successful")
+	log.Info("action done: login successful")
 	return ctx.Status(fiber.StatusOK).JSON(link.Response{
 		Message: "login successful",
 	})
@@ -109,10 +118,12 @@ func (ac authController) Login(ctx *fiber.Ctx) error {
 // @Failure 500 {object} error "Failed to expire session"
 // @Security ApiKeyAuth
 func (ac authController) Exit(ctx *fiber.Ctx) error {
+	log := requestLog(ctx, ac.log)
+
 	userSession := ctx.Cookies("user_session")
 
 	if userSession == "" {
-		ac.log.Error("no session found")
+		log.Error("no session found")
 		return ctx.Status(fiber.StatusUnauthorized).JSON(link.Response{
 			Message: "no session found",
 		})
@@ -120,7 +131,7 @@ func (ac authController) Exit(ctx *fiber.Ctx) error {
 
 	expiredToken, err := token.ExpireJwtToken(ac.jwtSecret, time.Hour, nil)
 	if err != nil {
-		ac.log.Error("failed to expire session: %v", err)
+		log.Error("failed to expire session: %v", err)
 		return ctx.Status(fiber.StatusInternalServerError).JSON(link.Response{
 			Message: "failed to expire session",
 		})
@@ -135,7 +146,7 @@ func (ac authController) Exit(ctx *fiber.Ctx) error {
 		SameSite: "Strict",
 	})
 
-	ac.log.Info("action successful: logout user")
+	log.Info("action successful: logout user")
 	return ctx.Status(fiber.StatusOK).JSON(link.Response{
 		Message: "logout successful",
 	})
@@ -27,7 +27,7 @@ func TestAuth(test *testing.T) {
 		logMock,
 		ruleMock,
 		"super_secret_key",
-		60,
+		gateway.NewSettings(60, "http://example.com"),
 	)
 
 	tests := []struct {
@@ -64,7 +64,7 @@ func TestAuth(test *testing.T) {
 			switch tc.action {
 			case "login":
 				// Mock the Login method to return the expected response
-				ruleMock.On("Login", mock.Anything).Return(&rules.User{ID: "1"}, nil).Once()
+				ruleMock.On("Login", mock.Anything, mock.Anything).Return(&rules.User{ID: "1"}, nil).Once()
 				app.Post("/login", authCtrl.Login)
 
 				// Convert input to JSON for the request body
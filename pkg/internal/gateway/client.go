@@ -0,0 +1,71 @@
+package gateway
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"project-wraith/pkg/internal/rules"
+	"project-wraith/pkg/modules/link"
+	"project-wraith/pkg/modules/logger"
+)
+
+type ClientController interface {
+	Register(ctx *fiber.Ctx) error
+}
+
+type clientController struct {
+	log   logger.Logger
+	rules rules.ClientRule
+}
+
+func NewClientController(log logger.Logger, rules rules.ClientRule) ClientController {
+	return &clientController{
+		log:   log,
+		rules: rules,
+	}
+}
+
+// Register
+// @Summary Client key registration
+// @Description Registers or replaces the X25519 public key used to seal responses for a client.
+// @Tags Client
+// @Accept json
+// @Produce json
+// @Router /clients/register [post]
+// @Param request body Client true "Client id, name, optional API key and base64 public key"
+// @Success 200 {object} map[string]string "Client registered"
+// @Failure 400 {object} error "Failed to parse request or invalid key"
+// @Security ApiKeyAuth
+func (cc clientController) Register(ctx *fiber.Ctx) error {
+	log := requestLog(ctx, cc.log)
+
+	req := Client{}
+	if err := ctx.BodyParser(&req); err != nil {
+		log.Error("failed to parse request: %v", err)
+		return ctx.Status(fiber.StatusBadRequest).JSON(link.Response{
+			Message: "failed to parse request",
+		})
+	}
+
+	model := rules.Client{
+		ID:        req.ID,
+		Name:      req.Name,
+		ApiKey:    req.ApiKey,
+		PublicKey: req.PublicKey,
+	}
+
+	err := cc.rules.Register(ctx.UserContext(), model)
+	if interrupted(err) {
+		log.Warn("failed to register client: %v", err)
+		return interruptedResponse(ctx, err)
+	}
+	if err != nil {
+		log.Error("failed to register client: %v", err)
+		return ctx.Status(fiber.StatusBadRequest).JSON(link.Response{
+			Message: err.Error(),
+		})
+	}
+
+	log.Info("action done: client registered")
+	return ctx.Status(fiber.StatusOK).JSON(link.Response{
+		Message: "client registered",
+	})
+}
@@ -0,0 +1,66 @@
+package gateway_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"project-wraith/pkg/internal/gateway"
+	"project-wraith/pkg/internal/rules"
+	"project-wraith/pkg/modules/logger"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClientController(test *testing.T) {
+	test.Parallel()
+
+	tests := []struct {
+		name           string
+		request        interface{}
+		ruleErr        error
+		expectedStatus int
+	}{
+		{
+			name:           "Test Register - Successful",
+			request:        gateway.Client{ID: "mobile", PublicKey: "key"},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "Test Register - Rejected",
+			request:        gateway.Client{ID: "mobile", PublicKey: "bad"},
+			ruleErr:        errors.New("invalid public key"),
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tc := range tests {
+		test.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			logMock := &logger.MockLogger{}
+			logMock.On("Error", mock.Anything).Return(nil)
+			logMock.On("Info", mock.Anything).Return(nil)
+
+			clientMock := &rules.MockClientRule{}
+			clientMock.On("Register", mock.Anything, mock.Anything).Return(tc.ruleErr)
+
+			app := fiber.New()
+			app.Post("/clients/register", gateway.NewClientController(logMock, clientMock).Register)
+
+			body, err := json.Marshal(tc.request)
+			require.NoError(t, err)
+			req := httptest.NewRequest("POST", "/clients/register", bytes.NewBuffer(body))
+			req.Header.Set("Content-Type", "application/json")
+
+			resp, err := app.Test(req, -1)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedStatus, resp.StatusCode)
+		})
+	}
+}
@@ -0,0 +1,64 @@
+package gateway
+
+import (
+	"errors"
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt/v5"
+	"project-wraith/pkg/consts"
+	"project-wraith/pkg/modules/db"
+	"project-wraith/pkg/modules/link"
+	"project-wraith/pkg/modules/logger"
+)
+
+// StatusClientClosedRequest answers a request canceled before it was done,
+// because the client hung up or the server is shutting down.
+const StatusClientClosedRequest = 499
+
+// requestLog returns the logger the request middleware scoped to this
+// request, with the matched route and, once authenticated, the user subject.
+func requestLog(ctx *fiber.Ctx, log logger.Logger) logger.Logger {
+	fields := []interface{}{"route", ctx.Route().Path}
+	if subject := sessionSubject(ctx); subject != "" {
+		fields = append(fields, "subject", subject)
+	}
+
+	return logger.FromContext(ctx.UserContext(), log).With(fields...)
+}
+
+// sessionSubject reads the user ID from the session token validated by the
+// jwt middleware, which keeps the rules.User it was issued for under data.
+func sessionSubject(ctx *fiber.Ctx) string {
+	session, ok := ctx.Locals(consts.SessionLocal).(*jwt.Token)
+	if !ok {
+		return ""
+	}
+
+	claims, ok := session.Claims.(jwt.MapClaims)
+	if !ok {
+		return ""
+	}
+
+	data, ok := claims["data"].(map[string]interface{})
+	if !ok {
+		return ""
+	}
+
+	subject, _ := data["ID"].(string)
+	return subject
+}
+
+// interrupted tells whether err comes from database work cut short by the
+// request deadline or cancellation, rather than from a failing database.
+func interrupted(err error) bool {
+	return errors.Is(err, db.ErrTimeout) || errors.Is(err, db.ErrCanceled)
+}
+
+// interruptedResponse answers 504 when the request ran out of time and
+// StatusClientClosedRequest when it was canceled.
+func interruptedResponse(ctx *fiber.Ctx, err error) error {
+	if errors.Is(err, db.ErrTimeout) {
+		return ctx.Status(fiber.StatusGatewayTimeout).JSON(link.Response{Message: "request timed out"})
+	}
+
+	return ctx.Status(StatusClientClosedRequest).JSON(link.Response{Message: "request canceled"})
+}
@@ -0,0 +1,53 @@
+package gateway
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"project-wraith/pkg/modules/health"
+)
+
+type HealthController interface {
+	Live(ctx *fiber.Ctx) error
+	Ready(ctx *fiber.Ctx) error
+}
+
+type healthController struct {
+	readiness health.Checker
+}
+
+func NewHealthController(readiness health.Checker) HealthController {
+	return &healthController{
+		readiness: readiness,
+	}
+}
+
+// Live
+// @Summary Liveness probe
+// @Description Answers as long as the server is serving requests.
+// @Tags Health
+// @Produce json
+// @Router /healthz [get]
+// @Success 200 {object} health.Report "Server is up"
+func (hc healthController) Live(ctx *fiber.Ctx) error {
+	ctx.Set(fiber.HeaderCacheControl, "no-store")
+	return ctx.Status(fiber.StatusOK).JSON(health.Report{Status: health.StatusUp})
+}
+
+// Ready
+// @Summary Readiness probe
+// @Description Checks the databases, SMTP, storage and license, with the latency of each check. Results are cached for a few seconds.
+// @Tags Health
+// @Produce json
+// @Router /readyz [get]
+// @Success 200 {object} health.Report "Every dependency is up"
+// @Failure 503 {object} health.Report "At least one dependency is down"
+func (hc healthController) Ready(ctx *fiber.Ctx) error {
+	report := hc.readiness.Ready(ctx.UserContext())
+
+	status := fiber.StatusOK
+	if report.Status != health.StatusUp {
+		status = fiber.StatusServiceUnavailable
+	}
+
+	ctx.Set(fiber.HeaderCacheControl, "no-store")
+	return ctx.Status(status).JSON(report)
+}
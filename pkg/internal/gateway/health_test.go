@@ -0,0 +1,79 @@
+package gateway_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"project-wraith/pkg/internal/gateway"
+	"project-wraith/pkg/modules/health"
+	"project-wraith/pkg/modules/logger"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHealthController(test *testing.T) {
+	test.Parallel()
+
+	tests := []struct {
+		name           string
+		path           string
+		checkErr       error
+		expectedStatus int
+		expectedReport string
+	}{
+		{
+			name:           "Test Live",
+			path:           "/healthz",
+			checkErr:       errors.New("down"),
+			expectedStatus: http.StatusOK,
+			expectedReport: health.StatusUp,
+		},
+		{
+			name:           "Test Ready - Up",
+			path:           "/readyz",
+			expectedStatus: http.StatusOK,
+			expectedReport: health.StatusUp,
+		},
+		{
+			name:           "Test Ready - Down",
+			path:           "/readyz",
+			checkErr:       errors.New("connection refused"),
+			expectedStatus: http.StatusServiceUnavailable,
+			expectedReport: health.StatusDown,
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		test.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			logMock := &logger.MockLogger{}
+			logMock.On("Warn", mock.Anything).Return(nil)
+
+			readiness := health.NewChecker(logMock, time.Second, time.Second)
+			readiness.Add("db.user", func(ctx context.Context) error { return tc.checkErr })
+
+			controller := gateway.NewHealthController(readiness)
+			app := fiber.New()
+			app.Get("/healthz", controller.Live)
+			app.Get("/readyz", controller.Ready)
+
+			res, err := app.Test(httptest.NewRequest(http.MethodGet, tc.path, nil))
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedStatus, res.StatusCode)
+			assert.Equal(t, "no-store", res.Header.Get(fiber.HeaderCacheControl))
+
+			var report health.Report
+			require.NoError(t, json.NewDecoder(res.Body).Decode(&report))
+			assert.Equal(t, tc.expectedReport, report.Status)
+		})
+	}
+}
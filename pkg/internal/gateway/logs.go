@@ -0,0 +1,219 @@
+package gateway
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/utils"
+	"project-wraith/pkg/consts"
+	"project-wraith/pkg/modules/link"
+	"project-wraith/pkg/modules/logger"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	tailInterval  = time.Second
+	tailKeepAlive = 15 * time.Second
+)
+
+// searchableLevels are the level files a query may name; anything else is
+// rejected before it gets near a file path.
+var searchableLevels = map[string]bool{"debug": true, "info": true, "warn": true, "error": true}
+
+type LogsController interface {
+	Search(ctx *fiber.Ctx) error
+	Tail(ctx *fiber.Ctx) error
+}
+
+type logsController struct {
+	log      logger.Logger
+	logsPath string
+	logsKey  string
+}
+
+// NewLogsController is a function constructor for LogsController.
+// logsKey opens log files sealed at rest; it is empty when they are plain.
+func NewLogsController(log logger.Logger, logsPath, logsKey string) LogsController {
+	return &logsController{
+		log:      log,
+		logsPath: logsPath,
+		logsKey:  logsKey,
+	}
+}
+
+// Search
+// @Summary Search logs
+// @Description Returns a page of log entries, newest first, across the level files and their rotated segments.
+// @Tags Static
+// @Produce json
+// @Router /logs/entries [get]
+// @Param level query string false "Comma separated levels: debug, info, warn, error"
+// @Param from query string false "RFC3339 lower time bound"
+// @Param to query string false "RFC3339 upper time bound"
+// @Param caller query string false "Caller substring"
+// @Param q query string false "Message substring"
+// @Param requestId query string false "Request ID"
+// @Param cursor query string false "Cursor of the next page"
+// @Param limit query int false "Page size"
+// @Success 200 {object} logger.Page "Log entries"
+// @Failure 400 {object} link.Response "Invalid filters or cursor"
+// @Security ApiKeyAuth
+func (lc *logsController) Search(ctx *fiber.Ctx) error {
+	log := requestLog(ctx, lc.log)
+
+	query, err := logQuery(ctx)
+	if err != nil {
+		log.Warn("invalid log query: %v", err)
+		return ctx.Status(fiber.StatusBadRequest).JSON(link.Response{
+			Message: err.Error(),
+		})
+	}
+
+	page, err := logger.Search(lc.logsPath, lc.logsKey, query)
+	if errors.Is(err, logger.ErrInvalidCursor) {
+		log.Warn("invalid log cursor")
+		return ctx.Status(fiber.StatusBadRequest).JSON(link.Response{
+			Message: err.Error(),
+		})
+	}
+	if err != nil {
+		log.Error("failed to search logs: %v", err)
+		return err
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(page)
+}
+
+// Tail
+// @Summary Tail logs
+// @Description Streams new log entries matching the filters as server-sent events named log.
+// @Tags Static
+// @Produce text/event-stream
+// @Router /logs/tail [get]
+// @Param level query string false "Comma separated levels: debug, info, warn, error"
+// @Param caller query string false "Caller substring"
+// @Param q query string false "Message substring"
+// @Param requestId query string false "Request ID"
+// @Success 200 {string} string "Event stream"
+// @Failure 400 {object} link.Response "Invalid filters"
+// @Security ApiKeyAuth
+func (lc *logsController) Tail(ctx *fiber.Ctx) error {
+	log := requestLog(ctx, lc.log)
+
+	query, err := logQuery(ctx)
+	if err != nil {
+		log.Warn("invalid log query: %v", err)
+		return ctx.Status(fiber.StatusBadRequest).JSON(link.Response{
+			Message: err.Error(),
+		})
+	}
+	query.Cursor = ""
+
+	log.Info("tailing logs")
+
+	ctx.Set(fiber.HeaderContentType, "text/event-stream")
+	ctx.Set(fiber.HeaderCacheControl, "no-cache")
+	ctx.Set(fiber.HeaderConnection, "keep-alive")
+	ctx.Set("X-Accel-Buffering", "no")
+
+	folder, secret := lc.logsPath, lc.logsKey
+	ctx.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		stream, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		var mu sync.Mutex
+		send := func(chunk string) error {
+			mu.Lock()
+			defer mu.Unlock()
+
+			if _, err := w.WriteString(chunk); err != nil {
+				return err
+			}
+			return w.Flush()
+		}
+
+		if err := send(": tailing\n\n"); err != nil {
+			return
+		}
+
+		// Comments keep proxies from closing an idle stream and find out
+		// when the client went away.
+		go func() {
+			ticker := time.NewTicker(tailKeepAlive)
+			defer ticker.Stop()
+
+			for {
+				select {
+				case <-stream.Done():
+					return
+				case <-ticker.C:
+					if err := send(": keep-alive\n\n"); err != nil {
+						cancel()
+						return
+					}
+				}
+			}
+		}()
+
+		err := logger.Follow(stream, folder, secret, query, tailInterval, func(entry map[string]interface{}) error {
+			data, err := json.Marshal(entry)
+			if err != nil {
+				return err
+			}
+
+			return send(fmt.Sprintf("event: log\ndata: %s\n\n", data))
+		})
+		if err != nil && !errors.Is(err, context.Canceled) {
+			log.Warn("log tail stopped: %v", err)
+		}
+	})
+
+	return nil
+}
+
+func logQuery(ctx *fiber.Ctx) (logger.Query, error) {
+	query := logger.Query{
+		Caller:    utils.CopyString(ctx.Query("caller")),
+		Text:      utils.CopyString(ctx.Query("q")),
+		RequestID: utils.CopyString(ctx.Query("requestId")),
+		Cursor:    utils.CopyString(ctx.Query("cursor")),
+		Limit:     logger.DefaultPageSize,
+	}
+
+	if levels := ctx.Query("level"); levels != "" {
+		for _, level := range strings.Split(levels, ",") {
+			level = strings.ToLower(strings.TrimSpace(level))
+			if !searchableLevels[level] {
+				return query, fmt.Errorf("invalid level %q", level)
+			}
+			query.Levels = append(query.Levels, level)
+		}
+	}
+
+	var err error
+	if from := ctx.Query("from"); from != "" {
+		if query.From, err = time.Parse(time.RFC3339, from); err != nil {
+			return query, fmt.Errorf("invalid from: %v", err)
+		}
+	}
+	if to := ctx.Query("to"); to != "" {
+		if query.To, err = time.Parse(time.RFC3339, to); err != nil {
+			return query, fmt.Errorf("invalid to: %v", err)
+		}
+	}
+
+	if limit := ctx.Query("limit"); limit != "" {
+		query.Limit, err = strconv.Atoi(limit)
+		if err != nil || query.Limit < 1 || query.Limit > consts.LogReportLimit {
+			return query, fmt.Errorf("limit must be between 1 and %d", consts.LogReportLimit)
+		}
+	}
+
+	return query, nil
+}
@@ -0,0 +1,102 @@
+package gateway_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"project-wraith/pkg/internal/gateway"
+	"project-wraith/pkg/modules/logger"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLogsController(test *testing.T) {
+	test.Parallel()
+
+	folder := test.TempDir()
+	require.NoError(test, os.WriteFile(filepath.Join(folder, "info.log"), []byte(
+		`{"ts":"2026-10-19T10:00:00.000Z","message":"one"}`+"\n"+
+			`{"ts":"2026-10-19T11:00:00.000Z","message":"two"}`+"\n"), 0644))
+
+	tests := []struct {
+		name             string
+		query            string
+		expectedStatus   int
+		expectedMessages []string
+		expectedNext     bool
+	}{
+		{
+			name:             "Test Search - Everything",
+			query:            "",
+			expectedStatus:   http.StatusOK,
+			expectedMessages: []string{"two", "one"},
+		},
+		{
+			name:             "Test Search - Paged",
+			query:            "?level=info&limit=1",
+			expectedStatus:   http.StatusOK,
+			expectedMessages: []string{"two"},
+			expectedNext:     true,
+		},
+		{
+			name:             "Test Search - Time range",
+			query:            "?from=2026-10-19T10:30:00Z",
+			expectedStatus:   http.StatusOK,
+			expectedMessages: []string{"two"},
+		},
+		{
+			name:           "Test Search - Unknown level",
+			query:          "?level=../secrets",
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "Test Search - Limit too big",
+			query:          "?limit=100000",
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "Test Search - Invalid cursor",
+			query:          "?cursor=nope",
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		test.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			logMock := &logger.MockLogger{}
+			logMock.On("Warn", mock.Anything).Return(nil)
+			logMock.On("Error", mock.Anything).Return(nil)
+
+			app := fiber.New()
+			app.Get("/logs/entries", gateway.NewLogsController(logMock, folder, "").Search)
+
+			req := httptest.NewRequest("GET", "/logs/entries"+tc.query, nil)
+			resp, err := app.Test(req, -1)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedStatus, resp.StatusCode)
+
+			if tc.expectedStatus != http.StatusOK {
+				return
+			}
+
+			page := logger.Page{}
+			require.NoError(t, json.NewDecoder(resp.Body).Decode(&page))
+
+			var messages []string
+			for _, entry := range page.Entries {
+				messages = append(messages, entry["message"].(string))
+			}
+			assert.Equal(t, tc.expectedMessages, messages)
+			assert.Equal(t, tc.expectedNext, page.Next != "")
+		})
+	}
+}
@@ -20,3 +20,10 @@ type Outcome struct {
 	Token    string `json:"token"`
 	ResetUrl string `json:"resetUrl"`
 }
+
+type Client struct {
+	ID        string `json:"id"`
+	Name      string `json:"name"`
+	ApiKey    string `json:"apiKey,omitempty"`
+	PublicKey string `json:"publicKey"`
+}
@@ -3,11 +3,11 @@ package gateway
 import (
 	"fmt"
 	"github.com/gofiber/fiber/v2"
-	"github.com/gofiber/fiber/v2/log"
 	"project-wraith/pkg/internal/rules"
 	"project-wraith/pkg/modules/link"
 	"project-wraith/pkg/modules/logger"
 	"project-wraith/pkg/modules/mail"
+	"project-wraith/pkg/modules/metrics"
 	"project-wraith/pkg/modules/sms"
 	"time"
 )
@@ -25,7 +25,7 @@ type resetController struct {
 	cookieExpiration time.Duration
 	mailer           mail.Mail
 	smsSender        sms.Twilio
-	webUrl           string
+	settings         *Settings
 }
 
 func NewResetController(
@@ -36,7 +36,7 @@ func NewResetController(
 	cookieExpiration int,
 	mailer mail.Mail,
 	smsSender sms.Twilio,
-	webUrl string,
+	settings *Settings,
 ) ResetController {
 	return &resetController{
 		log:              log,
@@ -46,7 +46,7 @@ func NewResetController(
 		cookieExpiration: time.Duration(cookieExpiration) * time.Hour,
 		mailer:           mailer,
 		smsSender:        smsSender,
-		webUrl:           webUrl,
+		settings:         settings,
 	}
 }
 
@@ -62,9 +62,11 @@ func NewResetController(
 // @Failure 400 {object} error "Failed to parse request or send notifications"
 // @Security ApiKeyAuth
 func (rc resetController) Start(ctx *fiber.Ctx) error {
+	log := requestLog(ctx, rc.log)
+
 	req := Reset{}
 	if err := ctx.BodyParser(&req); err != nil {
-		rc.log.Error("failed to parse request: %v", err)
+		log.Error("failed to parse request: %v", err)
 		return ctx.Status(fiber.StatusBadRequest).JSON(link.Response{
 			Message: "failed to parse request",
 		})
@@ -76,9 +78,14 @@ func (rc resetController) Start(ctx *fiber.Ctx) error {
 		Phone:    req.Phone,
 	}
 
-	entity, err := rc.reset.Start(model)
+	entity, err := rc.reset.Start(ctx.UserContext(), model)
+	metrics.ResetStarts.WithLabelValues(metrics.Outcome(err)).Inc()
+	if interrupted(err) {
+		log.Warn("failed to get user: %v", err)
+		return interruptedResponse(ctx, err)
+	}
 	if err != nil {
-		rc.log.Error("failed to get user: %v", err)
+		log.Error("failed to get user: %v", err)
 		return ctx.Status(fiber.StatusBadRequest).JSON(link.Response{
 			Message: err.Error(),
 		})
@@ -97,29 +104,31 @@ func (rc resetController) Start(ctx *fiber.Ctx) error {
 	}
 
 	err = rc.mailer.Send(
+		ctx.UserContext(),
 		"./templates/email.html",
 		bindStruct,
 		"Reset Password",
 		[]string{entity.Email})
 	if err != nil {
-		rc.log.Error("failed to send mail: %v", err)
+		log.Error("failed to send mail: %v", err)
 		return ctx.Status(fiber.StatusBadRequest).JSON(link.Response{
 			Message: err.Error(),
 		})
 	}
 
-	resetWebUrl := fmt.Sprintf("%s/%s", rc.webUrl, entity.Token)
+	resetWebUrl := fmt.Sprintf("%s/%s", rc.settings.ResetUrl(), entity.Token)
 
 	res, err := rc.smsSender.SendSMSTwilio(
+		ctx.UserContext(),
 		entity.Phone, true, resetWebUrl)
 	if err != nil {
-		rc.log.Error("failed to send sms: %v", err)
+		log.Error("failed to send sms: %v", err)
 		return ctx.Status(fiber.StatusBadRequest).JSON(link.Response{
 			Message: err.Error(),
 		})
 	}
 	if res == "" {
-		rc.log.Error("failed to send sms: %v", err)
+		log.Error("failed to send sms: %v", err)
 		return ctx.Status(fiber.StatusBadRequest).JSON(link.Response{
 			Message: "failed to send sms",
 		})
@@ -147,6 +156,8 @@ func (rc resetController) Start(ctx *fiber.Ctx) error {
 // @Failure 400 {object} error "Failed to reset password"
 // @Security ApiKeyAuth
 func (rc resetController) Modify(ctx *fiber.Ctx) error {
+	log := requestLog(ctx, rc.log)
+
 	tkn := ctx.Get("X-Reset-Token")
 	if tkn == "" {
 		log.Error("parameter not found: {key: X-Reset-Token, value: %v}", tkn)
@@ -156,7 +167,11 @@ func (rc resetController) Modify(ctx *fiber.Ctx) error {
 	reset := rules.Reset{
 		Token: tkn,
 	}
-	res, err := rc.reset.Validate(reset)
+	res, err := rc.reset.Validate(ctx.UserContext(), reset)
+	if interrupted(err) {
+		log.Warn("failed to validate: %v", err)
+		return interruptedResponse(ctx, err)
+	}
 	if err != nil {
 		log.Error("failed to validate: %v", err)
 		return ctx.Status(fiber.StatusBadRequest).JSON(link.Response{Message: err.Error()})
@@ -172,9 +187,13 @@ func (rc resetController) Modify(ctx *fiber.Ctx) error {
 		ID:       res.ID,
 		Password: req.NewPassword,
 	}
-	err = rc.user.Edit(model)
+	err = rc.user.Edit(ctx.UserContext(), model)
+	if interrupted(err) {
+		log.Warn("failed to reset password: %v", err)
+		return interruptedResponse(ctx, err)
+	}
 	if err != nil {
-		rc.log.Error("failed to reset password: %v", err)
+		log.Error("failed to reset password: %v", err)
 		return ctx.Status(fiber.StatusBadRequest).JSON(link.Response{
 			Message: err.Error(),
 		})
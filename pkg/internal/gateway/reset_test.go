@@ -37,7 +37,7 @@ func TestResetController(test *testing.T) {
 		60,
 		mailMock,
 		smsMock,
-		"http://example.com", // Mock web URL
+		gateway.NewSettings(60, "http://example.com"),
 	)
 
 	tests := []struct {
@@ -59,15 +59,15 @@ func TestResetController(test *testing.T) {
 			},
 			expectCode: fiber.StatusAccepted,
 			setupMocks: func() {
-				resetMock.On("Start", mock.Anything).Return(&rules.Reset{
+				resetMock.On("Start", mock.Anything, mock.Anything).Return(&rules.Reset{
 					Username: "testuser",
 					Email:    "test@example.com",
 					Phone:    "1234567890",
 					Token:    "mock_token",
 				}, nil).Once()
 
-				mailMock.On("Send", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil).Once()
-				smsMock.On("SendSMSTwilio", mock.Anything, mock.Anything, mock.Anything).Return("success", nil).Once()
+				mailMock.On("Send", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil).Once()
+				smsMock.On("SendSMSTwilio", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return("success", nil).Once()
 			},
 		},
 		{
@@ -89,12 +89,12 @@ func TestResetController(test *testing.T) {
 			},
 			expectCode: fiber.StatusOK,
 			setupMocks: func() {
-				resetMock.On("Validate", mock.Anything).Return(&rules.Reset{
+				resetMock.On("Validate", mock.Anything, mock.Anything).Return(&rules.Reset{
 					ID:    "1",
 					Token: "mock_token",
 				}, nil).Once()
 
-				userMock.On("Edit", mock.Anything).Return(nil).Once()
+				userMock.On("Edit", mock.Anything, mock.Anything).Return(nil).Once()
 			},
 		},
 		{
@@ -106,7 +106,7 @@ func TestResetController(test *testing.T) {
 			},
 			expectCode: fiber.StatusBadRequest,
 			setupMocks: func() {
-				resetMock.On("Validate", mock.Anything).Return(nil, fmt.Errorf("invalid token")).Once()
+				resetMock.On("Validate", mock.Anything, mock.Anything).Return(nil, fmt.Errorf("invalid token")).Once()
 			},
 		},
 	}
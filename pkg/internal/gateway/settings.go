@@ -0,0 +1,41 @@
+package gateway
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// Settings holds the values controllers read on every request, so a
+// configuration reload can change them while the server runs.
+type Settings struct {
+	values atomic.Pointer[settingsValues]
+}
+
+type settingsValues struct {
+	cookiesLife time.Duration
+	resetUrl    string
+}
+
+func NewSettings(cookiesMinutesLife int, resetUrl string) *Settings {
+	settings := &Settings{}
+	settings.Set(cookiesMinutesLife, resetUrl)
+	return settings
+}
+
+// Set replaces both values at once.
+func (s *Settings) Set(cookiesMinutesLife int, resetUrl string) {
+	s.values.Store(&settingsValues{
+		cookiesLife: time.Duration(cookiesMinutesLife) * time.Minute,
+		resetUrl:    resetUrl,
+	})
+}
+
+// CookiesLife is how long session cookies and tokens last.
+func (s *Settings) CookiesLife() time.Duration {
+	return s.values.Load().cookiesLife
+}
+
+// ResetUrl is the web page the reset links point to.
+func (s *Settings) ResetUrl() string {
+	return s.values.Load().resetUrl
+}
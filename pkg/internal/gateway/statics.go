@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/utils"
+	"project-wraith/pkg/consts"
 	"project-wraith/pkg/modules/logger"
 )
 
@@ -14,18 +15,16 @@ type StaticsController interface {
 }
 
 type staticsController struct {
-	log         logger.Logger
-	logsPath    string
-	basePath    string
-	version     string
-	encryptLogs bool
+	log      logger.Logger
+	basePath string
+	version  string
 }
 
-func NewStaticsController(log logger.Logger, version, logsPath, basePath string) StaticsController {
+// NewStaticsController is a function constructor for StaticsController.
+func NewStaticsController(log logger.Logger, version, basePath string) StaticsController {
 	return &staticsController{
 		log:      log,
 		version:  version,
-		logsPath: logsPath,
 		basePath: basePath,
 	}
 }
@@ -41,7 +40,7 @@ func NewStaticsController(log logger.Logger, version, logsPath, basePath string)
 // @Failure 500 {object} error "Internal server error"
 // @Security ApiKeyAuth
 func (sc *staticsController) HelloHuman(ctx *fiber.Ctx) error {
-	sc.log.Info("Get Application Welcome")
+	requestLog(ctx, sc.log).Info("Get Application Welcome")
 
 	return ctx.Render("index", fiber.Map{
 		"Version": sc.version,
@@ -50,41 +49,22 @@ func (sc *staticsController) HelloHuman(ctx *fiber.Ctx) error {
 
 // LogReport
 // @Summary Get Log Report
-// @Description Returns the log report in the response rendered page.
+// @Description Returns the log viewer page, which searches and tails the logs through the logs API.
 // @Tags Static
 // @Accept json
 // @Produce html
-// @Router /log [get]
-// @Success 200 {string} string "HTML page with application version"
+// @Router /logs [get]
+// @Success 200 {string} string "HTML log viewer"
 // @Failure 500 {object} error "Internal server error"
 // @Security ApiKeyAuth
 func (sc *staticsController) LogReport(ctx *fiber.Ctx) error {
-	sc.log.Info("Get Log Report")
-
-	infoLogsPath := fmt.Sprintf("%s/info.log", sc.logsPath)
-	errLogsPath := fmt.Sprintf("%s/error.log", sc.logsPath)
-	warnLogsPath := fmt.Sprintf("%s/warn.log", sc.logsPath)
-
-	infoLogsContent, err := logger.ReadFile(infoLogsPath)
-	if err != nil {
-		return err
-	}
-
-	errLogsContent, err := logger.ReadFile(errLogsPath)
-	if err != nil {
-		return err
-	}
-
-	warnLogsContent, err := logger.ReadFile(warnLogsPath)
-	if err != nil {
-		return err
-	}
+	requestLog(ctx, sc.log).Info("Get Log Report")
 
 	return ctx.Render("logs", fiber.Map{
-		"Version":   sc.version,
-		"InfoLogs":  infoLogsContent,
-		"ErrorLogs": errLogsContent,
-		"WarnLogs":  warnLogsContent,
+		"Version":     sc.version,
+		"EntriesPath": fmt.Sprintf("%s/logs/entries", sc.basePath),
+		"TailPath":    fmt.Sprintf("%s/logs/tail", sc.basePath),
+		"PageLimit":   consts.LogReportLimit,
 	})
 }
 
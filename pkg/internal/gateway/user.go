@@ -1,9 +1,9 @@
 package gateway
 
 import (
+	"errors"
 	"github.com/gofiber/fiber/v2"
 	"project-wraith/pkg/internal/rules"
-	"project-wraith/pkg/modules/alchemy"
 	"project-wraith/pkg/modules/link"
 	"project-wraith/pkg/modules/logger"
 	"time"
@@ -20,8 +20,6 @@ type userController struct {
 	log                logger.Logger
 	rules              rules.UserRule
 	jwtSecret          string
-	encryptResponse    bool
-	responseSecret     string
 	cookiesMinutesLife time.Duration
 }
 
@@ -29,16 +27,12 @@ func NewUserController(
 	log logger.Logger,
 	rules rules.UserRule,
 	jwtSecret string,
-	encryptResponse bool,
-	responseSecret string,
 	cookiesMinutesLife int,
 ) UserController {
 	return &userController{
 		log:                log,
 		rules:              rules,
 		jwtSecret:          jwtSecret,
-		encryptResponse:    encryptResponse,
-		responseSecret:     responseSecret,
 		cookiesMinutesLife: time.Duration(cookiesMinutesLife) * time.Minute,
 	}
 }
@@ -53,11 +47,14 @@ func NewUserController(
 // @Param request body User true "User registration details"
 // @Success 200 {object} map[string]string "Registration successful"
 // @Failure 400 {object} error "Failed to parse request or registration error"
+// @Failure 409 {object} link.Response "Username, email or phone taken, listed in content.fields"
 // @Security ApiKeyAuth
 func (uc userController) Register(ctx *fiber.Ctx) error {
+	log := requestLog(ctx, uc.log)
+
 	req := User{}
 	if err := ctx.BodyParser(&req); err != nil {
-		uc.log.Error("failed to parse request: %v", err)
+		log.Error("failed to parse request: %v", err)
 		return ctx.Status(fiber.StatusBadRequest).JSON(link.Response{
 			Message: "failed to parse request",
 		})
@@ -72,15 +69,23 @@ func (uc userController) Register(ctx *fiber.Ctx) error {
 		Password: req.Password,
 	}
 
-	res, err := uc.rules.Register(actor)
+	res, err := uc.rules.Register(ctx.UserContext(), actor)
+	if interrupted(err) {
+		log.Warn("failed to register: %v", err)
+		return interruptedResponse(ctx, err)
+	}
+	if conflict := new(rules.ConflictError); errors.As(err, &conflict) {
+		log.Warn("failed to register: %v", err)
+		return conflictResponse(ctx, conflict)
+	}
 	if err != nil {
-		uc.log.Error("failed to register: %v", err)
+		log.Error("failed to register: %v", err)
 		return ctx.Status(fiber.StatusInternalServerError).JSON(link.Response{
 			Message: err.Error(),
 		})
 	}
 
-	uc.log.Info("action done: register successful %v", res)
+	log.Infow("action done: register successful", "id", res.ID)
 	return ctx.Status(fiber.StatusOK).JSON(link.Response{
 		Message: "register successful",
 	})
@@ -99,9 +104,11 @@ func (uc userController) Register(ctx *fiber.Ctx) error {
 // @Failure 404 {object} error "User not found"
 // @Security ApiKeyAuth
 func (uc userController) Get(ctx *fiber.Ctx) error {
+	log := requestLog(ctx, uc.log)
+
 	id := ctx.Params("id")
 	if id == "" {
-		uc.log.Error("parameter not found: {key: id, value: %v}", id)
+		log.Error("parameter not found: {key: id, value: %v}", id)
 		return ctx.Status(fiber.StatusBadRequest).JSON(link.Response{
 			Message: "id is required",
 		})
@@ -109,16 +116,20 @@ func (uc userController) Get(ctx *fiber.Ctx) error {
 
 	actor := rules.User{ID: id}
 
-	user, err := uc.rules.Get(actor)
+	user, err := uc.rules.Get(ctx.UserContext(), actor)
+	if interrupted(err) {
+		log.Warn("failed to get user: %v", err)
+		return interruptedResponse(ctx, err)
+	}
 	if err != nil {
-		uc.log.Warn("failed to get user: %v", err)
+		log.Warn("failed to get user: %v", err)
 		return ctx.Status(fiber.StatusOK).JSON(link.Response{
 			Message: err.Error(),
 		})
 	}
 
 	if user == nil {
-		uc.log.Warn("empty data: user not found")
+		log.Warn("empty data: user not found")
 		return ctx.Status(fiber.StatusOK).JSON(link.Response{
 			Message: "user not found",
 		})
@@ -132,18 +143,7 @@ func (uc userController) Get(ctx *fiber.Ctx) error {
 		Phone:    user.Phone,
 	}
 
-	uc.log.Info("action done: get user")
-
-	if uc.encryptResponse {
-		structString, err := alchemy.StructIntoString(&res, uc.responseSecret)
-		if err != nil {
-			return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-				"error": err.Error(),
-			})
-		}
-
-		return ctx.Status(fiber.StatusOK).JSON(structString)
-	}
+	log.Info("action done: get user")
 
 	return ctx.Status(fiber.StatusOK).JSON(link.Response{
 		Content: res,
@@ -160,11 +160,14 @@ func (uc userController) Get(ctx *fiber.Ctx) error {
 // @Param request body User true "Updated user details"
 // @Success 200 {object} map[string]string "User details updated successfully"
 // @Failure 400 {object} error "Failed to parse request or update error"
+// @Failure 409 {object} link.Response "Username, email or phone taken, listed in content.fields"
 // @Security ApiKeyAuth
 func (uc userController) Edit(ctx *fiber.Ctx) error {
+	log := requestLog(ctx, uc.log)
+
 	req := User{}
 	if err := ctx.BodyParser(&req); err != nil {
-		uc.log.Error("failed to parse request: %v", err)
+		log.Error("failed to parse request: %v", err)
 		return ctx.Status(fiber.StatusBadRequest).JSON(link.Response{
 			Message: "failed to parse request",
 		})
@@ -179,15 +182,23 @@ func (uc userController) Edit(ctx *fiber.Ctx) error {
 		Password: req.Password,
 	}
 
-	err := uc.rules.Edit(actor)
+	err := uc.rules.Edit(ctx.UserContext(), actor)
+	if interrupted(err) {
+		log.Warn("failed to edit user: %v", err)
+		return interruptedResponse(ctx, err)
+	}
+	if conflict := new(rules.ConflictError); errors.As(err, &conflict) {
+		log.Warn("failed to edit user: %v", err)
+		return conflictResponse(ctx, conflict)
+	}
 	if err != nil {
-		uc.log.Error("failed to edit user: %v", err)
+		log.Error("failed to edit user: %v", err)
 		return ctx.Status(fiber.StatusInternalServerError).JSON(link.Response{
 			Message: err.Error(),
 		})
 	}
 
-	uc.log.Info("action successful: edit user")
+	log.Info("action successful: edit user")
 	return ctx.Status(fiber.StatusOK).JSON(link.Response{
 		Message: "edit successful",
 	})
@@ -205,9 +216,11 @@ func (uc userController) Edit(ctx *fiber.Ctx) error {
 // @Failure 400 {object} error "Failed to parse request or removal error"
 // @Security ApiKeyAuth
 func (uc userController) Disable(ctx *fiber.Ctx) error {
+	log := requestLog(ctx, uc.log)
+
 	req := User{}
 	if err := ctx.BodyParser(&req); err != nil {
-		uc.log.Error("failed to parse request: %v", err)
+		log.Error("failed to parse request: %v", err)
 		return ctx.Status(fiber.StatusBadRequest).JSON(link.Response{
 			Message: "failed to parse request",
 		})
@@ -222,16 +235,28 @@ func (uc userController) Disable(ctx *fiber.Ctx) error {
 		Password: req.Password,
 	}
 
-	err := uc.rules.Disable(actor)
+	err := uc.rules.Disable(ctx.UserContext(), actor)
+	if interrupted(err) {
+		log.Warn("failed to remove user: %v", err)
+		return interruptedResponse(ctx, err)
+	}
 	if err != nil {
-		uc.log.Error("failed to remove user: %v", err)
+		log.Error("failed to remove user: %v", err)
 		return ctx.Status(fiber.StatusBadRequest).JSON(link.Response{
 			Message: err.Error(),
 		})
 	}
 
-	uc.log.Info("action successful: remove user")
+	log.Info("action successful: remove user")
 	return ctx.Status(fiber.StatusOK).JSON(link.Response{
 		Message: "remove successful",
 	})
 }
+
+// conflictResponse answers 409 with the fields another user already has.
+func conflictResponse(ctx *fiber.Ctx, conflict *rules.ConflictError) error {
+	return ctx.Status(fiber.StatusConflict).JSON(link.Response{
+		Message: "user already exists",
+		Content: fiber.Map{"fields": conflict.Fields},
+	})
+}
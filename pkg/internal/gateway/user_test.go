@@ -2,17 +2,22 @@ package gateway_test
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"project-wraith/pkg/internal/gateway"
 	"project-wraith/pkg/internal/rules"
+	"project-wraith/pkg/modules/db"
 	"project-wraith/pkg/modules/logger"
 	"testing"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 )
 
 func TestUserController(test *testing.T) {
@@ -22,10 +27,11 @@ func TestUserController(test *testing.T) {
 	userMock := &rules.MockUserRule{}
 
 	logMock.On("Error", mock.Anything).Return(nil)
+	logMock.On("Infow", mock.Anything).Return(nil)
 	logMock.On("Info", mock.Anything).Return(nil)
 	logMock.On("Warn", mock.Anything).Return(nil)
 
-	controller := gateway.NewUserController(logMock, userMock, "secret", false, "responseSecret", 60)
+	controller := gateway.NewUserController(logMock, userMock, "secret", 60)
 
 	tests := []struct {
 		name             string
@@ -49,7 +55,7 @@ func TestUserController(test *testing.T) {
 				"message": "register successful",
 			},
 			setupMocks: func() {
-				userMock.On("Register", mock.Anything).Return(&rules.User{
+				userMock.On("Register", mock.Anything, mock.Anything).Return(&rules.User{
 					Username: "newuser",
 				}, nil).Once()
 			},
@@ -67,7 +73,7 @@ func TestUserController(test *testing.T) {
 					"username": "newuser",
 				}},
 			setupMocks: func() {
-				userMock.On("Get", mock.Anything).Return(&rules.User{
+				userMock.On("Get", mock.Anything, mock.Anything).Return(&rules.User{
 					ID:       "newuser",
 					Username: "newuser",
 				}, nil).Once()
@@ -85,7 +91,7 @@ func TestUserController(test *testing.T) {
 				"message": "edit successful",
 			},
 			setupMocks: func() {
-				userMock.On("Edit", mock.Anything).Return(nil).Once()
+				userMock.On("Edit", mock.Anything, mock.Anything).Return(nil).Once()
 			},
 		},
 		{
@@ -100,7 +106,7 @@ func TestUserController(test *testing.T) {
 				"message": "remove successful",
 			},
 			setupMocks: func() {
-				userMock.On("Disable", mock.Anything).Return(nil).Once()
+				userMock.On("Disable", mock.Anything, mock.Anything).Return(nil).Once()
 			},
 		},
 	}
@@ -153,3 +159,152 @@ func TestUserController(test *testing.T) {
 		})
 	}
 }
+
+func TestUserControllerConflict(test *testing.T) {
+	test.Parallel()
+
+	tests := []struct {
+		name   string
+		method string
+		err    error
+		status int
+		fields []interface{}
+	}{
+		{
+			name:   "Test Register - Conflicting fields",
+			method: "POST",
+			err:    &rules.ConflictError{Fields: []string{"email", "phone"}},
+			status: http.StatusConflict,
+			fields: []interface{}{"email", "phone"},
+		},
+		{
+			name:   "Test Edit - Conflicting fields",
+			method: "PUT",
+			err:    &rules.ConflictError{Fields: []string{"username"}},
+			status: http.StatusConflict,
+			fields: []interface{}{"username"},
+		},
+		{
+			name:   "Test Register - Other errors",
+			method: "POST",
+			err:    errors.New("boom"),
+			status: http.StatusInternalServerError,
+		},
+	}
+
+	for _, testCase := range tests {
+		test.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			logMock := &logger.MockLogger{}
+			logMock.On("Warn", mock.Anything).Return(nil)
+			logMock.On("Error", mock.Anything).Return(nil)
+
+			userMock := &rules.MockUserRule{}
+			userMock.On("Register", mock.Anything, mock.Anything).Return(nil, testCase.err)
+			userMock.On("Edit", mock.Anything, mock.Anything).Return(testCase.err)
+
+			controller := gateway.NewUserController(logMock, userMock, "secret", 60)
+			app := fiber.New()
+			app.Post("/user/register", controller.Register)
+			app.Put("/user/edit", controller.Edit)
+
+			url := "/user/register"
+			if testCase.method == "PUT" {
+				url = "/user/edit"
+			}
+
+			payload, _ := json.Marshal(rules.User{Username: "taken", Email: "taken@example.com"})
+			req := httptest.NewRequest(testCase.method, url, bytes.NewBuffer(payload))
+			req.Header.Set("Content-Type", "application/json")
+
+			resp, err := app.Test(req)
+			require.NoError(t, err)
+			assert.Equal(t, testCase.status, resp.StatusCode)
+
+			var response map[string]interface{}
+			require.NoError(t, json.NewDecoder(resp.Body).Decode(&response))
+			if testCase.fields == nil {
+				assert.Nil(t, response["content"])
+				return
+			}
+
+			assert.Equal(t, "user already exists", response["message"])
+			assert.Equal(t, map[string]interface{}{"fields": testCase.fields}, response["content"])
+		})
+	}
+}
+
+func TestUserControllerInterrupted(test *testing.T) {
+	test.Parallel()
+
+	timedOut := fmt.Errorf("%w: %w", db.ErrTimeout, context.DeadlineExceeded)
+	canceled := fmt.Errorf("%w: %w", db.ErrCanceled, context.Canceled)
+
+	tests := []struct {
+		name    string
+		method  string
+		url     string
+		err     error
+		status  int
+		message string
+	}{
+		{
+			name:    "Test Register - Timed out",
+			method:  "POST",
+			url:     "/user/register",
+			err:     timedOut,
+			status:  http.StatusGatewayTimeout,
+			message: "request timed out",
+		},
+		{
+			name:    "Test Get - Canceled",
+			method:  "GET",
+			url:     "/user/123",
+			err:     canceled,
+			status:  gateway.StatusClientClosedRequest,
+			message: "request canceled",
+		},
+		{
+			name:    "Test Edit - Canceled inside a conflict check",
+			method:  "PUT",
+			url:     "/user/edit",
+			err:     fmt.Errorf("failed to find conflicts: %w", canceled),
+			status:  gateway.StatusClientClosedRequest,
+			message: "request canceled",
+		},
+	}
+
+	for _, testCase := range tests {
+		test.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			logMock := &logger.MockLogger{}
+			logMock.On("Warn", mock.Anything).Return(nil)
+
+			userMock := &rules.MockUserRule{}
+			userMock.On("Register", mock.Anything, mock.Anything).Return(nil, testCase.err)
+			userMock.On("Get", mock.Anything, mock.Anything).Return(nil, testCase.err)
+			userMock.On("Edit", mock.Anything, mock.Anything).Return(testCase.err)
+
+			controller := gateway.NewUserController(logMock, userMock, "secret", 60)
+			app := fiber.New()
+			app.Post("/user/register", controller.Register)
+			app.Get("/user/:id", controller.Get)
+			app.Put("/user/edit", controller.Edit)
+
+			payload, _ := json.Marshal(rules.User{Username: "someone"})
+			req := httptest.NewRequest(testCase.method, testCase.url, bytes.NewBuffer(payload))
+			req.Header.Set("Content-Type", "application/json")
+
+			resp, err := app.Test(req)
+			require.NoError(t, err)
+			assert.Equal(t, testCase.status, resp.StatusCode)
+
+			var response map[string]interface{}
+			require.NoError(t, json.NewDecoder(resp.Body).Decode(&response))
+			assert.Equal(t, testCase.message, response["message"])
+			logMock.AssertNotCalled(t, "Error", mock.Anything)
+		})
+	}
+}
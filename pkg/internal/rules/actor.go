@@ -10,6 +10,17 @@ type User struct {
 	status   string
 }
 
+// Status is the account status, set by Login and Get.
+func (u User) Status() string {
+	return u.status
+}
+
+// WithStatus returns a copy of u with its status set, for tests and mocks.
+func (u User) WithStatus(status string) User {
+	u.status = status
+	return u
+}
+
 type Reset struct {
 	ID          string
 	Username    string
@@ -18,3 +29,10 @@ type Reset struct {
 	NewPassword string
 	Token       string
 }
+
+type Client struct {
+	ID        string
+	Name      string
+	ApiKey    string
+	PublicKey string
+}
@@ -0,0 +1,104 @@
+package rules
+
+import (
+	"context"
+	"errors"
+	"project-wraith/pkg/internal/domain"
+	"project-wraith/pkg/modules/alchemy"
+	"project-wraith/pkg/modules/apikey"
+	"project-wraith/pkg/modules/tracing"
+	"time"
+)
+
+type ClientRule interface {
+	Register(ctx context.Context, model Client) error
+	Seal(ctx context.Context, model Client, entity interface{}) (string, string, error)
+	// Authenticate tells whether apiKey belongs to a registered client.
+	Authenticate(ctx context.Context, apiKey string) (bool, error)
+}
+
+type clientRule struct {
+	repo domain.ClientRepository
+}
+
+func NewClientRule(repo domain.ClientRepository) ClientRule {
+	return &clientRule{
+		repo: repo,
+	}
+}
+
+// Register stores the X25519 public key of a client. API keys are only kept
+// hashed.
+func (r clientRule) Register(ctx context.Context, model Client) (err error) {
+	ctx, span := tracing.Start(ctx, "ClientRule.Register")
+	defer func() { tracing.End(span, err) }()
+
+	if model.ID == "" {
+		return errors.New("client id is required")
+	}
+
+	if _, err := alchemy.ParseHybridPublicKey(model.PublicKey); err != nil {
+		return errors.New("invalid public key")
+	}
+
+	entity := domain.Client{
+		ID:        model.ID,
+		Name:      model.Name,
+		PublicKey: model.PublicKey,
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+
+	if model.ApiKey != "" {
+		entity.ApiKey = apikey.CrateApiKey(model.ApiKey)
+	}
+
+	return r.repo.Save(ctx, entity)
+}
+
+func (r clientRule) Authenticate(ctx context.Context, apiKey string) (_ bool, err error) {
+	ctx, span := tracing.Start(ctx, "ClientRule.Authenticate")
+	defer func() { tracing.End(span, err) }()
+
+	if apiKey == "" {
+		return false, nil
+	}
+
+	_, err = r.repo.Get(ctx, domain.Client{ApiKey: apikey.CrateApiKey(apiKey)})
+	if errors.Is(err, domain.ErrClientNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// Seal encrypts entity for the client selected by ID or, failing that, by the
+// API key it called with. It returns the client ID and the sealed payload.
+func (r clientRule) Seal(ctx context.Context, model Client, entity interface{}) (_ string, _ string, err error) {
+	ctx, span := tracing.Start(ctx, "ClientRule.Seal")
+	defer func() { tracing.End(span, err) }()
+
+	query := domain.Client{ID: model.ID}
+	if query.ID == "" && model.ApiKey != "" {
+		query.ApiKey = apikey.CrateApiKey(model.ApiKey)
+	}
+
+	client, err := r.repo.Get(ctx, query)
+	if err != nil {
+		return "", "", err
+	}
+
+	if client == nil {
+		return "", "", errors.New("client not found")
+	}
+
+	sealed, err := alchemy.StructSealedFor(entity, client.PublicKey)
+	if err != nil {
+		return "", "", err
+	}
+
+	return client.ID, sealed, nil
+}
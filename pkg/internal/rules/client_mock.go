@@ -0,0 +1,26 @@
+package rules
+
+import (
+	"context"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type MockClientRule struct {
+	mock.Mock
+}
+
+func (m *MockClientRule) Register(ctx context.Context, model Client) error {
+	args := m.Called(ctx, model)
+	return args.Error(0)
+}
+
+func (m *MockClientRule) Seal(ctx context.Context, model Client, entity interface{}) (string, string, error) {
+	args := m.Called(ctx, model, entity)
+	return args.String(0), args.String(1), args.Error(2)
+}
+
+func (m *MockClientRule) Authenticate(ctx context.Context, apiKey string) (bool, error) {
+	args := m.Called(ctx, apiKey)
+	return args.Bool(0), args.Error(1)
+}
@@ -0,0 +1,149 @@
+package rules_test
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"project-wraith/pkg/internal/domain"
+	"project-wraith/pkg/internal/rules"
+	"project-wraith/pkg/modules/alchemy"
+	"project-wraith/pkg/modules/apikey"
+	"testing"
+)
+
+func TestClientRule(test *testing.T) {
+	test.Parallel()
+
+	public, private, err := alchemy.GenerateHybridKeys()
+	require.NoError(test, err)
+
+	testCases := []struct {
+		name          string
+		method        string
+		input         rules.Client
+		expectedQuery domain.Client
+		repoReturn    *domain.Client
+		repoErr       error
+		expectErr     bool
+	}{
+		{
+			name:   "Register Success",
+			method: "Register",
+			input:  rules.Client{ID: "mobile", Name: "Mobile app", ApiKey: "key", PublicKey: public},
+		},
+		{
+			name:      "Register Invalid Key",
+			method:    "Register",
+			input:     rules.Client{ID: "mobile", PublicKey: "bm90LWEta2V5"},
+			expectErr: true,
+		},
+		{
+			name:          "Seal By ID",
+			method:        "Seal",
+			input:         rules.Client{ID: "mobile", ApiKey: "key"},
+			expectedQuery: domain.Client{ID: "mobile"},
+			repoReturn:    &domain.Client{ID: "mobile", PublicKey: public},
+		},
+		{
+			name:          "Seal By API Key",
+			method:        "Seal",
+			input:         rules.Client{ApiKey: "key"},
+			expectedQuery: domain.Client{ApiKey: apikey.CrateApiKey("key")},
+			repoReturn:    &domain.Client{ID: "mobile", PublicKey: public},
+		},
+		{
+			name:          "Seal Unknown Client",
+			method:        "Seal",
+			input:         rules.Client{ID: "unknown"},
+			expectedQuery: domain.Client{ID: "unknown"},
+			repoReturn:    nil,
+			repoErr:       errors.New("client not found"),
+			expectErr:     true,
+		},
+	}
+
+	for _, tc := range testCases {
+		test.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			mockRepo := new(domain.MockClientRepository)
+			rule := rules.NewClientRule(mockRepo)
+
+			switch tc.method {
+			case "Register":
+				mockRepo.On("Save", mock.Anything, mock.Anything).Return(nil)
+
+				err := rule.Register(context.Background(), tc.input)
+				if tc.expectErr {
+					assert.Error(t, err)
+					mockRepo.AssertNotCalled(t, "Save", mock.Anything, mock.Anything)
+					return
+				}
+
+				require.NoError(t, err)
+				saved := mockRepo.Calls[0].Arguments.Get(1).(domain.Client)
+				assert.Equal(t, apikey.CrateApiKey(tc.input.ApiKey), saved.ApiKey)
+				assert.Equal(t, tc.input.PublicKey, saved.PublicKey)
+
+			case "Seal":
+				mockRepo.On("Get", mock.Anything, tc.expectedQuery).Return(tc.repoReturn, tc.repoErr)
+
+				kid, sealed, err := rule.Seal(context.Background(), tc.input, map[string]string{"id": "1"})
+				if tc.expectErr {
+					assert.Error(t, err)
+					return
+				}
+
+				require.NoError(t, err)
+				assert.Equal(t, tc.repoReturn.ID, kid)
+				var opened map[string]string
+				require.NoError(t, alchemy.OpenSealedStruct(private, sealed, &opened))
+				assert.Equal(t, "1", opened["id"])
+			}
+		})
+	}
+}
+
+func TestClientRuleAuthenticate(test *testing.T) {
+	test.Parallel()
+
+	testCases := []struct {
+		name      string
+		apiKey    string
+		repoErr   error
+		expected  bool
+		expectErr bool
+	}{
+		{name: "Registered Key", apiKey: "key", expected: true},
+		{name: "Unknown Key", apiKey: "other", repoErr: domain.ErrClientNotFound},
+		{name: "Empty Key", apiKey: ""},
+		{name: "Repository Error", apiKey: "key", repoErr: errors.New("no primary"), expectErr: true},
+	}
+
+	for _, tc := range testCases {
+		test.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			mockRepo := new(domain.MockClientRepository)
+			var found *domain.Client
+			if tc.repoErr == nil {
+				found = &domain.Client{ID: "mobile"}
+			}
+			mockRepo.On("Get", mock.Anything, domain.Client{ApiKey: apikey.CrateApiKey(tc.apiKey)}).Return(found, tc.repoErr)
+
+			ok, err := rules.NewClientRule(mockRepo).Authenticate(context.Background(), tc.apiKey)
+			if tc.expectErr {
+				assert.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, ok)
+			if tc.apiKey == "" {
+				mockRepo.AssertNotCalled(t, "Get", mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
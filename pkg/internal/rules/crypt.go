@@ -0,0 +1,222 @@
+package rules
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"project-wraith/pkg/internal/domain"
+	"project-wraith/pkg/modules/alchemy"
+	"project-wraith/pkg/modules/tracing"
+	"time"
+)
+
+// CryptCheckpoint is the checkpoint id used by the encrypt_db_data migration.
+const CryptCheckpoint = "crypt:users"
+
+// RotateCheckpoint is the checkpoint id used by the key rotation.
+const RotateCheckpoint = "crypt:rotate"
+
+type CryptReport struct {
+	Target    string
+	DryRun    bool
+	Resumed   bool
+	Scanned   int
+	Plain     int
+	Sealed    int
+	Mixed     int
+	Empty     int
+	Converted int
+}
+
+func (cr CryptReport) String() string {
+	verb := "converted"
+	if cr.DryRun {
+		verb = "to convert"
+	}
+
+	return fmt.Sprintf(
+		"target: %s, resumed: %t, scanned: %d (plain: %d, sealed: %d, mixed: %d, empty: %d), %s: %d",
+		cr.Target, cr.Resumed, cr.Scanned, cr.Plain, cr.Sealed, cr.Mixed, cr.Empty, verb, cr.Converted)
+}
+
+type CryptRule interface {
+	Migrate(ctx context.Context, encrypt bool, dryRun bool) (*CryptReport, error)
+	Rotate(ctx context.Context, next string, dryRun bool) (*CryptReport, error)
+}
+
+type cryptRule struct {
+	repo        domain.UserRepository
+	checkpoints domain.CheckpointRepository
+	secret      string
+	batchSize   int
+}
+
+func NewCryptRule(
+	repo domain.UserRepository,
+	checkpoints domain.CheckpointRepository,
+	secret string,
+	batchSize int) CryptRule {
+	return &cryptRule{
+		repo:        repo,
+		checkpoints: checkpoints,
+		secret:      secret,
+		batchSize:   batchSize,
+	}
+}
+
+// Migrate walks every user document and brings its protected fields into the
+// target form. Each document is inspected on its own, so partially migrated
+// collections and repeated runs are safe. Progress is checkpointed after every
+// batch and an unfinished run for the same target is resumed. A dry run only
+// reports what would change.
+func (r cryptRule) Migrate(ctx context.Context, encrypt bool, dryRun bool) (_ *CryptReport, err error) {
+	ctx, span := tracing.Start(ctx, "CryptRule.Migrate")
+	defer func() { tracing.End(span, err) }()
+
+	target := alchemy.StatePlain.String()
+	if encrypt {
+		target = alchemy.StateSealed.String()
+	}
+
+	return r.run(ctx, CryptCheckpoint, target, dryRun, func(user *domain.User) (int, error) {
+		if encrypt {
+			return alchemy.Seal(user, r.secret)
+		}
+		return alchemy.Unseal(user, r.secret)
+	})
+}
+
+// Rotate re-encrypts the sealed fields of every user from the current secret
+// to next, and seals the plain ones. Fields already sealed with next are left
+// alone, so it resumes and repeats like Migrate. The target names a hash of
+// next, never next itself.
+func (r cryptRule) Rotate(ctx context.Context, next string, dryRun bool) (_ *CryptReport, err error) {
+	ctx, span := tracing.Start(ctx, "CryptRule.Rotate")
+	defer func() { tracing.End(span, err) }()
+
+	if next == "" || next == r.secret {
+		return nil, fmt.Errorf("the new secret must be set and differ from the current one")
+	}
+
+	sum := sha256.Sum256([]byte(next))
+	target := alchemy.StateSealed.String() + ":" + hex.EncodeToString(sum[:4])
+
+	return r.run(ctx, RotateCheckpoint, target, dryRun, func(user *domain.User) (int, error) {
+		opened, err := alchemy.Unseal(user, r.secret)
+		if err != nil {
+			return 0, err
+		}
+
+		sealed, err := alchemy.Seal(user, next)
+		return max(opened, sealed), err
+	})
+}
+
+// run converts every user document with convert, checkpointing under id.
+func (r cryptRule) run(
+	ctx context.Context,
+	id string,
+	target string,
+	dryRun bool,
+	convert func(user *domain.User) (int, error)) (*CryptReport, error) {
+	report := &CryptReport{Target: target, DryRun: dryRun}
+	checkpoint := domain.Checkpoint{ID: id, Target: target}
+	var scanned, converted int
+
+	if !dryRun {
+		saved, err := r.checkpoints.Get(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+
+		if saved != nil && saved.Target == target && !saved.Done {
+			checkpoint = *saved
+			scanned, converted = saved.Scanned, saved.Converted
+			report.Resumed = true
+		}
+	}
+
+	for {
+		batch, err := r.repo.Batch(ctx, checkpoint.After, r.batchSize)
+		if err != nil {
+			return report, err
+		}
+
+		if len(batch) == 0 {
+			break
+		}
+
+		for _, record := range batch {
+			err := r.convert(ctx, record, convert, dryRun, report)
+			if err != nil {
+				return report, fmt.Errorf("document %v: %w", record.Key, err)
+			}
+		}
+
+		checkpoint.After = batch[len(batch)-1].Key
+		if dryRun {
+			continue
+		}
+
+		checkpoint.Scanned = scanned + report.Scanned
+		checkpoint.Converted = converted + report.Converted
+		checkpoint.UpdatedAt = time.Now()
+		if err := r.checkpoints.Save(ctx, checkpoint); err != nil {
+			return report, err
+		}
+	}
+
+	if !dryRun {
+		checkpoint.Scanned = scanned + report.Scanned
+		checkpoint.Converted = converted + report.Converted
+		checkpoint.Done = true
+		checkpoint.UpdatedAt = time.Now()
+		if err := r.checkpoints.Save(ctx, checkpoint); err != nil {
+			return report, err
+		}
+	}
+
+	return report, nil
+}
+
+func (r cryptRule) convert(
+	ctx context.Context,
+	record domain.UserRecord,
+	convert func(user *domain.User) (int, error),
+	dryRun bool,
+	report *CryptReport) error {
+	report.Scanned++
+
+	state, err := alchemy.Inspect(&record.User, r.secret)
+	if err != nil {
+		return err
+	}
+
+	switch state {
+	case alchemy.StatePlain:
+		report.Plain++
+	case alchemy.StateSealed:
+		report.Sealed++
+	case alchemy.StateMixed:
+		report.Mixed++
+	default:
+		report.Empty++
+	}
+
+	changed, err := convert(&record.User)
+	if err != nil {
+		return err
+	}
+
+	if changed == 0 {
+		return nil
+	}
+
+	report.Converted++
+	if dryRun {
+		return nil
+	}
+
+	return r.repo.Rewrite(ctx, record)
+}
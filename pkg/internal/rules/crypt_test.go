@@ -0,0 +1,155 @@
+package rules_test
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"project-wraith/pkg/internal/domain"
+	"project-wraith/pkg/internal/rules"
+	"project-wraith/pkg/modules/alchemy"
+	"testing"
+)
+
+func TestCryptRule(test *testing.T) {
+	test.Parallel()
+
+	secret := "db_secret"
+
+	sealed := domain.User{Username: "sealed", Email: "sealed@example.com"}
+	require.NoError(test, alchemy.Transmutation(&sealed, secret))
+
+	records := func() []domain.UserRecord {
+		return []domain.UserRecord{
+			{Key: 1, User: domain.User{Username: "plain", Email: "plain@example.com"}},
+			{Key: 2, User: sealed},
+			{Key: 3, User: domain.User{ID: "only-id"}},
+		}
+	}
+
+	testCases := []struct {
+		name       string
+		encrypt    bool
+		dryRun     bool
+		checkpoint *domain.Checkpoint
+		expected   rules.CryptReport
+		rewrites   int
+	}{
+		{
+			name:    "Encrypt converts only plain documents",
+			encrypt: true,
+			expected: rules.CryptReport{
+				Target: "sealed", Scanned: 3, Plain: 1, Sealed: 1, Empty: 1, Converted: 1,
+			},
+			rewrites: 1,
+		},
+		{
+			name:    "Decrypt converts only sealed documents",
+			encrypt: false,
+			expected: rules.CryptReport{
+				Target: "plain", Scanned: 3, Plain: 1, Sealed: 1, Empty: 1, Converted: 1,
+			},
+			rewrites: 1,
+		},
+		{
+			name:    "Dry run reports without writing",
+			encrypt: true,
+			dryRun:  true,
+			expected: rules.CryptReport{
+				Target: "sealed", DryRun: true, Scanned: 3, Plain: 1, Sealed: 1, Empty: 1, Converted: 1,
+			},
+			rewrites: 0,
+		},
+		{
+			name:       "Unfinished checkpoint is resumed",
+			encrypt:    true,
+			checkpoint: &domain.Checkpoint{ID: rules.CryptCheckpoint, Target: "sealed", After: 1, Scanned: 1},
+			expected: rules.CryptReport{
+				Target: "sealed", Resumed: true, Scanned: 2, Sealed: 1, Empty: 1,
+			},
+			rewrites: 0,
+		},
+		{
+			name:       "Checkpoint for another target is ignored",
+			encrypt:    true,
+			checkpoint: &domain.Checkpoint{ID: rules.CryptCheckpoint, Target: "plain", After: 2},
+			expected: rules.CryptReport{
+				Target: "sealed", Scanned: 3, Plain: 1, Sealed: 1, Empty: 1, Converted: 1,
+			},
+			rewrites: 1,
+		},
+	}
+
+	for _, tc := range testCases {
+		test.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			repo := new(domain.MockUserRepository)
+			checkpoints := new(domain.MockCheckpointRepository)
+
+			all := records()
+			repo.On("Batch", mock.Anything, nil, 2).Return(all[0:2], nil)
+			repo.On("Batch", mock.Anything, 1, 2).Return(all[1:3], nil)
+			repo.On("Batch", mock.Anything, 2, 2).Return(all[2:3], nil)
+			repo.On("Batch", mock.Anything, 3, 2).Return([]domain.UserRecord{}, nil)
+			repo.On("Rewrite", mock.Anything, mock.Anything).Return(nil)
+			checkpoints.On("Get", mock.Anything, rules.CryptCheckpoint).Return(tc.checkpoint, nil)
+			checkpoints.On("Save", mock.Anything, mock.Anything).Return(nil)
+
+			rule := rules.NewCryptRule(repo, checkpoints, secret, 2)
+			report, err := rule.Migrate(context.Background(), tc.encrypt, tc.dryRun)
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, *report)
+			repo.AssertNumberOfCalls(t, "Rewrite", tc.rewrites)
+
+			if tc.dryRun {
+				checkpoints.AssertNotCalled(t, "Save", mock.Anything, mock.Anything)
+				return
+			}
+
+			last := checkpoints.Calls[len(checkpoints.Calls)-1].Arguments.Get(1).(domain.Checkpoint)
+			assert.True(t, last.Done)
+			assert.Equal(t, 3, last.Scanned)
+		})
+	}
+}
+
+func TestCryptRuleRotate(test *testing.T) {
+	test.Parallel()
+
+	secret, next := "db_secret", "next_secret"
+
+	old := domain.User{Username: "old", Email: "old@example.com"}
+	require.NoError(test, alchemy.Transmutation(&old, secret))
+	rotated := domain.User{Username: "rotated", Email: "rotated@example.com"}
+	require.NoError(test, alchemy.Transmutation(&rotated, next))
+
+	repo := new(domain.MockUserRepository)
+	checkpoints := new(domain.MockCheckpointRepository)
+
+	repo.On("Batch", mock.Anything, nil, 2).Return([]domain.UserRecord{
+		{Key: 1, User: old},
+		{Key: 2, User: rotated},
+	}, nil)
+	repo.On("Batch", mock.Anything, 2, 2).Return([]domain.UserRecord{}, nil)
+	repo.On("Rewrite", mock.Anything, mock.Anything).Return(nil)
+	checkpoints.On("Get", mock.Anything, rules.RotateCheckpoint).Return((*domain.Checkpoint)(nil), nil)
+	checkpoints.On("Save", mock.Anything, mock.Anything).Return(nil)
+
+	rule := rules.NewCryptRule(repo, checkpoints, secret, 2)
+	report, err := rule.Rotate(context.Background(), next, false)
+
+	require.NoError(test, err)
+	assert.Equal(test, 2, report.Scanned)
+	assert.Equal(test, 1, report.Converted)
+	assert.NotContains(test, report.Target, next)
+	repo.AssertNumberOfCalls(test, "Rewrite", 1)
+
+	record := repo.Calls[1].Arguments.Get(1).(domain.UserRecord)
+	assert.NoError(test, alchemy.Revert(&record.User, next))
+	assert.Equal(test, "old", record.User.Username)
+
+	_, err = rule.Rotate(context.Background(), secret, false)
+	assert.Error(test, err)
+}
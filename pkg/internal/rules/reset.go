@@ -1,17 +1,19 @@
 package rules
 
 import (
+	"context"
 	"errors"
 	"github.com/golang-jwt/jwt/v5"
 	"project-wraith/pkg/internal/domain"
 	"project-wraith/pkg/modules/token"
 	"project-wraith/pkg/modules/tools"
+	"project-wraith/pkg/modules/tracing"
 	"time"
 )
 
 type ResetRule interface {
-	Start(reset Reset) (*Reset, error)
-	Validate(reset Reset) (*Reset, error)
+	Start(ctx context.Context, reset Reset) (*Reset, error)
+	Validate(ctx context.Context, reset Reset) (*Reset, error)
 }
 
 type resetRule struct {
@@ -26,13 +28,16 @@ func NewResetRule(repository domain.UserRepository, jwtSecret string) ResetRule
 	}
 }
 
-func (rr resetRule) Start(reset Reset) (*Reset, error) {
+func (rr resetRule) Start(ctx context.Context, reset Reset) (_ *Reset, err error) {
+	ctx, span := tracing.Start(ctx, "ResetRule.Start")
+	defer func() { tracing.End(span, err) }()
+
 	entity := domain.User{
 		Email: reset.Email,
 		Phone: reset.Phone,
 	}
 
-	response, err := rr.repo.Get(entity)
+	response, err := rr.repo.Get(ctx, entity)
 	if err != nil {
 		return nil, err
 	}
@@ -64,7 +69,10 @@ func (rr resetRule) Start(reset Reset) (*Reset, error) {
 	return result, nil
 }
 
-func (rr resetRule) Validate(reset Reset) (*Reset, error) {
+func (rr resetRule) Validate(ctx context.Context, reset Reset) (_ *Reset, err error) {
+	ctx, span := tracing.Start(ctx, "ResetRule.Validate")
+	defer func() { tracing.End(span, err) }()
+
 	res := &Reset{}
 
 	extraValidation := func(claims jwt.MapClaims) error {
@@ -79,7 +87,7 @@ func (rr resetRule) Validate(reset Reset) (*Reset, error) {
 			Phone: data["phone"].(string),
 		}
 
-		response, err := rr.repo.Get(entity)
+		response, err := rr.repo.Get(ctx, entity)
 		if err != nil {
 			return err
 		}
@@ -1,21 +1,25 @@
 package rules
 
-import "github.com/stretchr/testify/mock"
+import (
+	"context"
+
+	"github.com/stretchr/testify/mock"
+)
 
 type MockResetRule struct {
 	mock.Mock
 }
 
-func (m *MockResetRule) Validate(reset Reset) (*Reset, error) {
-	args := m.Called(reset)
+func (m *MockResetRule) Validate(ctx context.Context, reset Reset) (*Reset, error) {
+	args := m.Called(ctx, reset)
 	if args.Get(0) != nil {
 		return args.Get(0).(*Reset), args.Error(1)
 	}
 	return nil, args.Error(1)
 }
 
-func (m *MockResetRule) Start(reset Reset) (*Reset, error) {
-	args := m.Called(reset)
+func (m *MockResetRule) Start(ctx context.Context, reset Reset) (*Reset, error) {
+	args := m.Called(ctx, reset)
 	if args.Get(0) != nil {
 		return args.Get(0).(*Reset), args.Error(1)
 	}
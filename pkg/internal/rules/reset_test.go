@@ -1,6 +1,7 @@
 package rules_test
 
 import (
+	"context"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"project-wraith/pkg/internal/domain"
@@ -75,9 +76,9 @@ func TestResetRule(test *testing.T) {
 			// Set up mock behavior for repository based on method
 			switch tc.method {
 			case "Start":
-				mockRepo.On("Get", mock.Anything).Return(tc.repoReturn, tc.repoErr)
+				mockRepo.On("Get", mock.Anything, mock.Anything).Return(tc.repoReturn, tc.repoErr)
 			case "Validate":
-				mockRepo.On("Get", mock.Anything).Return(tc.repoReturn, tc.repoErr)
+				mockRepo.On("Get", mock.Anything, mock.Anything).Return(tc.repoReturn, tc.repoErr)
 			}
 
 			// Execute the method under test
@@ -86,7 +87,7 @@ func TestResetRule(test *testing.T) {
 
 			switch tc.method {
 			case "Start":
-				result, err = rule.Start(tc.input)
+				result, err = rule.Start(context.Background(), tc.input)
 				// Check that the result is as expected
 				assert.NotNil(t, result)
 				assert.Equal(t, tc.expectedResult.ID, result.ID)
@@ -94,11 +95,11 @@ func TestResetRule(test *testing.T) {
 
 			case "Validate":
 				// Call Start to get a token, then validate
-				result, err = rule.Start(tc.input)
+				result, err = rule.Start(context.Background(), tc.input)
 				assert.NoError(t, err)
 
 				tc.input.Token = result.Token
-				result, err = rule.Validate(tc.input)
+				result, err = rule.Validate(context.Background(), tc.input)
 				assert.NotNil(t, result)
 				assert.NotEmpty(t, result.Token)
 				assert.NoError(t, err)
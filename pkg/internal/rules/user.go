@@ -1,20 +1,25 @@
 package rules
 
 import (
+	"context"
 	"errors"
 	"project-wraith/pkg/internal/domain"
 	"project-wraith/pkg/modules/alchemy"
 	"project-wraith/pkg/modules/status"
 	"project-wraith/pkg/modules/tools"
+	"project-wraith/pkg/modules/tracing"
+	"strings"
 	"time"
 )
 
 type UserRule interface {
-	Login(model User) (*User, error)
-	Register(model User) (*User, error)
-	Edit(model User) error
-	Get(model User) (*User, error)
-	Disable(model User) error
+	Login(ctx context.Context, model User) (*User, error)
+	Register(ctx context.Context, model User) (*User, error)
+	Edit(ctx context.Context, model User) error
+	Get(ctx context.Context, model User) (*User, error)
+	Disable(ctx context.Context, model User) error
+	Lock(ctx context.Context, model User) error
+	Unlock(ctx context.Context, model User) error
 }
 
 type userRule struct {
@@ -37,7 +42,10 @@ func NewUserRule(
 	}
 }
 
-func (r userRule) Login(model User) (*User, error) {
+func (r userRule) Login(ctx context.Context, model User) (_ *User, err error) {
+	ctx, span := tracing.Start(ctx, "UserRule.Login")
+	defer func() { tracing.End(span, err) }()
+
 	entity := domain.User{
 		ID:       model.ID,
 		Username: model.Username,
@@ -54,7 +62,7 @@ func (r userRule) Login(model User) (*User, error) {
 		}
 	}
 
-	response, err := r.repo.Get(entity)
+	response, err := r.repo.Get(ctx, entity)
 	if err != nil {
 		return nil, err
 	}
@@ -64,7 +72,7 @@ func (r userRule) Login(model User) (*User, error) {
 	}
 
 	if r.encryptDbData {
-		err = alchemy.Revert(&response, r.dbDataSecret)
+		err = alchemy.Revert(response, r.dbDataSecret)
 		if err != nil {
 			return nil, err
 		}
@@ -74,9 +82,13 @@ func (r userRule) Login(model User) (*User, error) {
 		return nil, errors.New("password incorrect")
 	}
 
+	if response.Status == status.Locked {
+		return nil, errors.New("user locked")
+	}
+
 	if response.Status != status.Locked && response.Status != status.Active {
 		toUpdate := domain.User{ID: response.ID, Status: status.Active}
-		err := r.repo.Update(toUpdate)
+		err := r.repo.Update(ctx, toUpdate)
 		if err != nil {
 			return nil, err
 		}
@@ -95,7 +107,10 @@ func (r userRule) Login(model User) (*User, error) {
 	return result, nil
 }
 
-func (r userRule) Register(model User) (*User, error) {
+func (r userRule) Register(ctx context.Context, model User) (_ *User, err error) {
+	ctx, span := tracing.Start(ctx, "UserRule.Register")
+	defer func() { tracing.End(span, err) }()
+
 	entity := domain.User{
 		ID:        model.ID,
 		Username:  model.Username,
@@ -107,15 +122,6 @@ func (r userRule) Register(model User) (*User, error) {
 		UpdatedAt: time.Now(),
 	}
 
-	duplicates, err := r.repo.Duplicated(entity)
-	if err != nil {
-		return nil, err
-	}
-
-	if len(duplicates) > 0 {
-		return nil, errors.New("user already exists")
-	}
-
 	if r.encryptDbData {
 		err := alchemy.Transmutation(&entity, r.dbDataSecret)
 		if err != nil {
@@ -123,15 +129,32 @@ func (r userRule) Register(model User) (*User, error) {
 		}
 	}
 
-	err = r.repo.Create(entity)
+	// Identifiers are compared in their stored form, so the check also holds
+	// with encrypt_db_data. The unique indexes catch what a concurrent
+	// registration slips past it.
+	err = r.repo.Transaction(ctx, func(ctx context.Context) error {
+		conflicts, err := r.repo.Conflicts(ctx, entity)
+		if err != nil {
+			return err
+		}
+
+		if len(conflicts) > 0 {
+			return &ConflictError{Fields: conflicts}
+		}
+
+		return r.repo.Create(ctx, entity)
+	})
 	if err != nil {
-		return nil, err
+		return nil, conflict(err)
 	}
 
 	return &model, nil
 }
 
-func (r userRule) Edit(model User) error {
+func (r userRule) Edit(ctx context.Context, model User) (err error) {
+	ctx, span := tracing.Start(ctx, "UserRule.Edit")
+	defer func() { tracing.End(span, err) }()
+
 	entity := domain.User{
 		ID:        model.ID,
 		Username:  model.Username,
@@ -149,15 +172,18 @@ func (r userRule) Edit(model User) error {
 		}
 	}
 
-	err := r.repo.Update(entity)
+	err = r.repo.Update(ctx, entity)
 	if err != nil {
-		return err
+		return conflict(err)
 	}
 
 	return nil
 }
 
-func (r userRule) Get(model User) (*User, error) {
+func (r userRule) Get(ctx context.Context, model User) (_ *User, err error) {
+	ctx, span := tracing.Start(ctx, "UserRule.Get")
+	defer func() { tracing.End(span, err) }()
+
 	entity := domain.User{
 		ID:       model.ID,
 		Username: model.Username,
@@ -172,7 +198,7 @@ func (r userRule) Get(model User) (*User, error) {
 		}
 	}
 
-	response, err := r.repo.Get(entity)
+	response, err := r.repo.Get(ctx, entity)
 	if err != nil {
 		return nil, err
 	}
@@ -181,6 +207,13 @@ func (r userRule) Get(model User) (*User, error) {
 		return nil, errors.New("user not found")
 	}
 
+	if r.encryptDbData {
+		err = alchemy.Revert(response, r.dbDataSecret)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	result := &User{
 		ID:       response.ID,
 		Username: response.Username,
@@ -188,19 +221,16 @@ func (r userRule) Get(model User) (*User, error) {
 		Name:     response.Name,
 		Phone:    response.Phone,
 		Password: response.Password,
-	}
-
-	if r.encryptDbData {
-		err = alchemy.Revert(&result, r.dbDataSecret)
-		if err != nil {
-			return nil, err
-		}
+		status:   response.Status,
 	}
 
 	return result, nil
 }
 
-func (r userRule) Disable(model User) error {
+func (r userRule) Disable(ctx context.Context, model User) (err error) {
+	ctx, span := tracing.Start(ctx, "UserRule.Disable")
+	defer func() { tracing.End(span, err) }()
+
 	entity := domain.User{
 		ID:       model.ID,
 		Username: model.Username,
@@ -216,9 +246,9 @@ func (r userRule) Disable(model User) error {
 		}
 	}
 
-	response, err := r.repo.Get(entity)
+	response, err := r.repo.Get(ctx, entity)
 	if err != nil {
-		return nil
+		return err
 	}
 
 	if response == nil {
@@ -226,7 +256,7 @@ func (r userRule) Disable(model User) error {
 	}
 
 	if r.encryptDbData {
-		err = alchemy.Revert(&response, r.dbDataSecret)
+		err = alchemy.Revert(response, r.dbDataSecret)
 		if err != nil {
 			return err
 		}
@@ -237,10 +267,62 @@ func (r userRule) Disable(model User) error {
 	}
 
 	toUpdate := domain.User{ID: response.ID, Status: status.Disabled}
-	err = r.repo.Update(toUpdate)
+	err = r.repo.Update(ctx, toUpdate)
 	if err != nil {
 		return err
 	}
 
 	return nil
 }
+
+// Lock keeps the user with model.ID from logging in until Unlock.
+func (r userRule) Lock(ctx context.Context, model User) (err error) {
+	ctx, span := tracing.Start(ctx, "UserRule.Lock")
+	defer func() { tracing.End(span, err) }()
+
+	return r.setStatus(ctx, model.ID, status.Locked)
+}
+
+func (r userRule) Unlock(ctx context.Context, model User) (err error) {
+	ctx, span := tracing.Start(ctx, "UserRule.Unlock")
+	defer func() { tracing.End(span, err) }()
+
+	return r.setStatus(ctx, model.ID, status.Active)
+}
+
+func (r userRule) setStatus(ctx context.Context, id, to string) error {
+	if id == "" {
+		return errors.New("user ID is required")
+	}
+
+	response, err := r.repo.Get(ctx, domain.User{ID: id})
+	if err != nil {
+		return err
+	}
+
+	if response == nil {
+		return errors.New("user not found")
+	}
+
+	return r.repo.Update(ctx, domain.User{ID: response.ID, Status: to, UpdatedAt: time.Now()})
+}
+
+// ConflictError is returned when a user would share its username, email or
+// phone with another user. Fields names the conflicting ones.
+type ConflictError struct {
+	Fields []string
+}
+
+func (e *ConflictError) Error() string {
+	return "user already exists with the same " + strings.Join(e.Fields, ", ")
+}
+
+// conflict turns a unique index violation into a ConflictError.
+func conflict(err error) error {
+	var duplicate *domain.DuplicateError
+	if errors.As(err, &duplicate) {
+		return &ConflictError{Fields: duplicate.Fields}
+	}
+
+	return err
+}
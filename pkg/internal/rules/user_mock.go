@@ -1,6 +1,8 @@
 package rules
 
 import (
+	"context"
+
 	"github.com/stretchr/testify/mock"
 )
 
@@ -8,36 +10,46 @@ type MockUserRule struct {
 	mock.Mock
 }
 
-func (m *MockUserRule) Login(model User) (*User, error) {
-	args := m.Called(model)
+func (m *MockUserRule) Login(ctx context.Context, model User) (*User, error) {
+	args := m.Called(ctx, model)
 	if args.Get(0) != nil {
 		return args.Get(0).(*User), args.Error(1)
 	}
 	return nil, args.Error(1)
 }
 
-func (m *MockUserRule) Register(model User) (*User, error) {
-	args := m.Called(model)
+func (m *MockUserRule) Register(ctx context.Context, model User) (*User, error) {
+	args := m.Called(ctx, model)
 	if args.Get(0) != nil {
 		return args.Get(0).(*User), args.Error(1)
 	}
 	return nil, args.Error(1)
 }
 
-func (m *MockUserRule) Edit(model User) error {
-	args := m.Called(model)
+func (m *MockUserRule) Edit(ctx context.Context, model User) error {
+	args := m.Called(ctx, model)
 	return args.Error(0)
 }
 
-func (m *MockUserRule) Get(model User) (*User, error) {
-	args := m.Called(model)
+func (m *MockUserRule) Get(ctx context.Context, model User) (*User, error) {
+	args := m.Called(ctx, model)
 	if args.Get(0) != nil {
 		return args.Get(0).(*User), args.Error(1)
 	}
 	return nil, args.Error(1)
 }
 
-func (m *MockUserRule) Disable(model User) error {
-	args := m.Called(model)
+func (m *MockUserRule) Disable(ctx context.Context, model User) error {
+	args := m.Called(ctx, model)
+	return args.Error(0)
+}
+
+func (m *MockUserRule) Lock(ctx context.Context, model User) error {
+	args := m.Called(ctx, model)
+	return args.Error(0)
+}
+
+func (m *MockUserRule) Unlock(ctx context.Context, model User) error {
+	args := m.Called(ctx, model)
 	return args.Error(0)
 }
@@ -1,10 +1,13 @@
 package rules_test
 
 import (
+	"context"
+	"errors"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"project-wraith/pkg/internal/domain"
 	"project-wraith/pkg/internal/rules"
+	"project-wraith/pkg/modules/status"
 	"project-wraith/pkg/modules/tools"
 	"testing"
 )
@@ -106,6 +109,55 @@ func TestUserRule(test *testing.T) {
 			expectedError:  nil,
 			method:         "Disable",
 		},
+
+		{
+			name: "Login Locked",
+			input: rules.User{
+				ID:       "123",
+				Password: "password",
+			},
+			repoReturn: &domain.User{
+				ID:       "123",
+				Password: tools.Sha512("secret", "password"),
+				Status:   status.Locked,
+			},
+			repoErr:        nil,
+			encryptData:    false,
+			expectedResult: nil,
+			expectedError:  errors.New("user locked"),
+			method:         "LoginDenied",
+		},
+
+		{
+			name: "Lock Success",
+			input: rules.User{
+				ID: "123",
+			},
+			repoReturn: &domain.User{
+				ID: "123",
+			},
+			repoErr:        nil,
+			encryptData:    false,
+			expectedResult: nil,
+			expectedError:  nil,
+			method:         "Lock",
+		},
+
+		{
+			name: "Unlock Success",
+			input: rules.User{
+				ID: "123",
+			},
+			repoReturn: &domain.User{
+				ID:     "123",
+				Status: status.Locked,
+			},
+			repoErr:        nil,
+			encryptData:    false,
+			expectedResult: nil,
+			expectedError:  nil,
+			method:         "Unlock",
+		},
 	}
 
 	for _, tc := range testCases {
@@ -118,18 +170,27 @@ func TestUserRule(test *testing.T) {
 			// Set up mock behavior
 			switch tc.method {
 			case "Login":
-				mockRepo.On("Update", mock.Anything).Return(tc.repoErr)
-				mockRepo.On("Get", mock.Anything).Return(tc.repoReturn, tc.repoErr)
+				mockRepo.On("Update", mock.Anything, mock.Anything).Return(tc.repoErr)
+				mockRepo.On("Get", mock.Anything, mock.Anything).Return(tc.repoReturn, tc.repoErr)
 			case "Register":
-				mockRepo.On("Duplicated", mock.Anything).Return([]domain.User{}, tc.repoErr)
-				mockRepo.On("Create", mock.Anything).Return(tc.repoErr)
+				mockRepo.On("Transaction", mock.Anything).Return(nil)
+				mockRepo.On("Conflicts", mock.Anything, mock.Anything).Return([]string{}, tc.repoErr)
+				mockRepo.On("Create", mock.Anything, mock.Anything).Return(tc.repoErr)
 			case "Edit":
-				mockRepo.On("Update", mock.Anything).Return(tc.repoErr)
+				mockRepo.On("Update", mock.Anything, mock.Anything).Return(tc.repoErr)
 			case "Get":
-				mockRepo.On("Get", mock.Anything).Return(tc.repoReturn, tc.repoErr)
+				mockRepo.On("Get", mock.Anything, mock.Anything).Return(tc.repoReturn, tc.repoErr)
 			case "Disable":
-				mockRepo.On("Get", mock.Anything).Return(tc.repoReturn, tc.repoErr)
-				mockRepo.On("Update", mock.Anything).Return(tc.repoErr)
+				mockRepo.On("Get", mock.Anything, mock.Anything).Return(tc.repoReturn, tc.repoErr)
+				mockRepo.On("Update", mock.Anything, mock.Anything).Return(tc.repoErr)
+			case "LoginDenied":
+				mockRepo.On("Get", mock.Anything, mock.Anything).Return(tc.repoReturn, tc.repoErr)
+			case "Lock":
+				mockRepo.On("Get", mock.Anything, domain.User{ID: "123"}).Return(tc.repoReturn, tc.repoErr)
+				mockRepo.On("Update", mock.Anything, mock.MatchedBy(func(u domain.User) bool { return u.ID == "123" && u.Status == status.Locked })).Return(tc.repoErr)
+			case "Unlock":
+				mockRepo.On("Get", mock.Anything, domain.User{ID: "123"}).Return(tc.repoReturn, tc.repoErr)
+				mockRepo.On("Update", mock.Anything, mock.MatchedBy(func(u domain.User) bool { return u.ID == "123" && u.Status == status.Active })).Return(tc.repoErr)
 			}
 
 			// Run the method under test
@@ -138,33 +199,98 @@ func TestUserRule(test *testing.T) {
 
 			switch tc.method {
 			case "Login":
-				result, err = rule.Login(tc.input)
+				result, err = rule.Login(context.Background(), tc.input)
 				// Move assertions outside the switch block
 				assert.Equal(t, tc.expectedResult, result)
 				assert.Equal(t, tc.expectedError, err)
 			case "Register":
-				result, err = rule.Register(tc.input)
+				result, err = rule.Register(context.Background(), tc.input)
 				// Move assertions outside the switch block
 				assert.Equal(t, tc.expectedResult.ID, result.ID)
 				assert.Equal(t, tc.expectedError, err)
 			case "Edit":
-				err = rule.Edit(tc.input)
+				err = rule.Edit(context.Background(), tc.input)
 				// Move assertions outside the switch block
 				assert.Equal(t, tc.expectedResult, result)
 				assert.Equal(t, tc.expectedError, err)
 			case "Get":
-				result, err = rule.Get(tc.input)
+				result, err = rule.Get(context.Background(), tc.input)
 				// Move assertions outside the switch block
 				assert.Equal(t, tc.expectedResult, result)
 				assert.Equal(t, tc.expectedError, err)
 			case "Disable":
-				err = rule.Disable(tc.input)
+				err = rule.Disable(context.Background(), tc.input)
 				// Move assertions outside the switch block
 				assert.Equal(t, tc.expectedResult, result)
 				assert.Equal(t, tc.expectedError, err)
+			case "LoginDenied":
+				result, err = rule.Login(context.Background(), tc.input)
+				assert.Equal(t, tc.expectedResult, result)
+				assert.Equal(t, tc.expectedError, err)
+			case "Lock":
+				err = rule.Lock(context.Background(), tc.input)
+				assert.Equal(t, tc.expectedError, err)
+			case "Unlock":
+				err = rule.Unlock(context.Background(), tc.input)
+				assert.Equal(t, tc.expectedError, err)
 			}
 
 			mockRepo.AssertExpectations(t)
 		})
 	}
 }
+
+func TestUserRuleRegisterConflicts(test *testing.T) {
+	test.Parallel()
+
+	testCases := []struct {
+		name      string
+		conflicts []string
+		createErr error
+		expected  error
+	}{
+		{
+			name:      "Each identifier is checked on its own",
+			conflicts: []string{"email"},
+			expected:  &rules.ConflictError{Fields: []string{"email"}},
+		},
+		{
+			name:      "Unique index violation",
+			conflicts: []string{},
+			createErr: &domain.DuplicateError{Fields: []string{"username", "phone"}},
+			expected:  &rules.ConflictError{Fields: []string{"username", "phone"}},
+		},
+		{
+			name:      "Other errors pass through",
+			conflicts: []string{},
+			createErr: errors.New("boom"),
+			expected:  errors.New("boom"),
+		},
+	}
+
+	for _, tc := range testCases {
+		test.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			mockRepo := new(domain.MockUserRepository)
+			mockRepo.On("Transaction", mock.Anything).Return(nil)
+			mockRepo.On("Conflicts", mock.Anything, mock.MatchedBy(func(u domain.User) bool {
+				return u.Username == "taken" && u.Email == "taken@example.com"
+			})).Return(tc.conflicts, nil)
+			mockRepo.On("Create", mock.Anything, mock.Anything).Return(tc.createErr)
+
+			rule := rules.NewUserRule(mockRepo, false, "", "secret")
+			result, err := rule.Register(context.Background(), rules.User{
+				Username: "taken",
+				Email:    "taken@example.com",
+				Password: "password",
+			})
+
+			assert.Nil(t, result)
+			assert.Equal(t, tc.expected, err)
+			if len(tc.conflicts) > 0 {
+				mockRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
@@ -3,6 +3,7 @@ package alchemy
 import (
 	"crypto/aes"
 	"crypto/cipher"
+	"crypto/hmac"
 	"crypto/rand"
 	"crypto/sha256"
 	"encoding/hex"
@@ -11,19 +12,37 @@ import (
 	"io"
 )
 
+// SharedAlg names the scheme used by Encrypt: AES-256-GCM with a key hashed
+// from a shared secret, hex encoded nonce and ciphertext.
+const SharedAlg = "SHA256-A256GCM"
+
 func GenerateKey(secret string) []byte {
 	hash := sha256.Sum256([]byte(secret))
 	return hash[:]
 }
 
 func Encrypt(plaintext string, secret string) (string, error) {
-	block, err := aes.NewCipher(GenerateKey(secret))
-	if err != nil {
+	nonce := make([]byte, 12)
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
 		return "", err
 	}
 
-	nonce := make([]byte, 12)
-	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+	return seal(plaintext, secret, nonce)
+}
+
+// EncryptIndex encrypts plaintext deterministically: the nonce is derived from
+// the plaintext itself, so equal inputs produce equal ciphertexts and the
+// result can be used in equality lookups. The output is readable by Decrypt.
+func EncryptIndex(plaintext string, secret string) (string, error) {
+	mac := hmac.New(sha256.New, GenerateKey("index:"+secret))
+	mac.Write([]byte(plaintext))
+
+	return seal(plaintext, secret, mac.Sum(nil)[:12])
+}
+
+func seal(plaintext string, secret string, nonce []byte) (string, error) {
+	block, err := aes.NewCipher(GenerateKey(secret))
+	if err != nil {
 		return "", err
 	}
 
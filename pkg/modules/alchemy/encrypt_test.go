@@ -89,3 +89,51 @@ func TestDecrypt(t *testing.T) {
 		})
 	}
 }
+
+func TestEncryptIndex(t *testing.T) {
+	secret := "supersecretkey"
+
+	tests := []struct {
+		name  string
+		left  string
+		right string
+		equal bool
+	}{
+		{
+			name:  "Same plaintext gives same ciphertext",
+			left:  "alice@example.com",
+			right: "alice@example.com",
+			equal: true,
+		},
+		{
+			name:  "Different plaintext gives different ciphertext",
+			left:  "alice@example.com",
+			right: "bob@example.com",
+			equal: false,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			left, err := alchemy.EncryptIndex(tc.left, secret)
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			right, err := alchemy.EncryptIndex(tc.right, secret)
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			if (left == right) != tc.equal {
+				t.Errorf("expected equal=%v, got %v and %v", tc.equal, left, right)
+			}
+
+			decrypted, err := alchemy.Decrypt(left, secret)
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			if decrypted != tc.left {
+				t.Errorf("expected %v, got %v", tc.left, decrypted)
+			}
+		})
+	}
+}
@@ -0,0 +1,162 @@
+package alchemy
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdh"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"golang.org/x/crypto/hkdf"
+)
+
+// HybridAlg names the scheme used by SealFor: an ephemeral X25519 key
+// agreement with the recipient key, HKDF-SHA256 to derive the key and
+// AES-256-GCM for the payload. The sealed form is
+// base64(ephemeral public key | nonce | ciphertext).
+const HybridAlg = "X25519-HKDF-SHA256-A256GCM"
+
+const hybridInfo = "project-wraith hybrid v1"
+
+var ErrHybridMalformed = errors.New("alchemy: malformed sealed payload")
+
+// GenerateHybridKeys returns a new base64 encoded X25519 key pair. The public
+// key is what a client registers, the private key never leaves the client.
+func GenerateHybridKeys() (publicKey string, privateKey string, err error) {
+	private, err := ecdh.X25519().GenerateKey(rand.Reader)
+	if err != nil {
+		return "", "", err
+	}
+
+	return base64.StdEncoding.EncodeToString(private.PublicKey().Bytes()),
+		base64.StdEncoding.EncodeToString(private.Bytes()), nil
+}
+
+// ParseHybridPublicKey validates a base64 encoded X25519 public key.
+func ParseHybridPublicKey(publicKey string) (*ecdh.PublicKey, error) {
+	raw, err := base64.StdEncoding.DecodeString(publicKey)
+	if err != nil {
+		return nil, fmt.Errorf("alchemy: public key is not base64: %w", err)
+	}
+
+	return ecdh.X25519().NewPublicKey(raw)
+}
+
+// SealFor encrypts plaintext so that only the holder of the private key
+// matching publicKey can read it.
+func SealFor(publicKey string, plaintext []byte) (string, error) {
+	recipient, err := ParseHybridPublicKey(publicKey)
+	if err != nil {
+		return "", err
+	}
+
+	ephemeral, err := ecdh.X25519().GenerateKey(rand.Reader)
+	if err != nil {
+		return "", err
+	}
+
+	shared, err := ephemeral.ECDH(recipient)
+	if err != nil {
+		return "", err
+	}
+
+	aead, err := hybridCipher(shared, ephemeral.PublicKey().Bytes(), recipient.Bytes())
+	if err != nil {
+		return "", err
+	}
+
+	out := make([]byte, 0, 32+aead.NonceSize()+len(plaintext)+aead.Overhead())
+	out = append(out, ephemeral.PublicKey().Bytes()...)
+
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return "", err
+	}
+	out = append(out, nonce...)
+	out = aead.Seal(out, nonce, plaintext, nil)
+
+	return base64.StdEncoding.EncodeToString(out), nil
+}
+
+// OpenSealed reverses SealFor with the recipient's base64 private key. It is
+// the helper clients use to read their responses.
+func OpenSealed(privateKey string, sealed string) ([]byte, error) {
+	rawKey, err := base64.StdEncoding.DecodeString(privateKey)
+	if err != nil {
+		return nil, fmt.Errorf("alchemy: private key is not base64: %w", err)
+	}
+
+	private, err := ecdh.X25519().NewPrivateKey(rawKey)
+	if err != nil {
+		return nil, err
+	}
+
+	payload, err := base64.StdEncoding.DecodeString(sealed)
+	if err != nil || len(payload) < 32 {
+		return nil, ErrHybridMalformed
+	}
+
+	ephemeral, err := ecdh.X25519().NewPublicKey(payload[:32])
+	if err != nil {
+		return nil, ErrHybridMalformed
+	}
+
+	shared, err := private.ECDH(ephemeral)
+	if err != nil {
+		return nil, err
+	}
+
+	aead, err := hybridCipher(shared, payload[:32], private.PublicKey().Bytes())
+	if err != nil {
+		return nil, err
+	}
+
+	payload = payload[32:]
+	if len(payload) < aead.NonceSize()+aead.Overhead() {
+		return nil, ErrHybridMalformed
+	}
+
+	nonce, ciphertext := payload[:aead.NonceSize()], payload[aead.NonceSize():]
+	return aead.Open(nil, nonce, ciphertext, nil)
+}
+
+// StructSealedFor marshals entity to JSON and seals it for publicKey.
+func StructSealedFor(entity interface{}, publicKey string) (string, error) {
+	data, err := json.Marshal(entity)
+	if err != nil {
+		return "", err
+	}
+
+	return SealFor(publicKey, data)
+}
+
+// OpenSealedStruct opens a payload produced by StructSealedFor into entity.
+func OpenSealedStruct(privateKey string, sealed string, entity interface{}) error {
+	data, err := OpenSealed(privateKey, sealed)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(data, entity)
+}
+
+func hybridCipher(shared, ephemeral, recipient []byte) (cipher.AEAD, error) {
+	salt := append(append([]byte{}, ephemeral...), recipient...)
+
+	key := make([]byte, 32)
+	if _, err := io.ReadFull(hkdf.New(sha256.New, shared, salt, []byte(hybridInfo)), key); err != nil {
+		return nil, err
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,59 @@
+package alchemy_test
+
+import (
+	"project-wraith/pkg/modules/alchemy"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHybridSealing(t *testing.T) {
+	public, private, err := alchemy.GenerateHybridKeys()
+	require.NoError(t, err)
+
+	_, otherPrivate, err := alchemy.GenerateHybridKeys()
+	require.NoError(t, err)
+
+	tests := []struct {
+		name       string
+		publicKey  string
+		privateKey string
+		tamper     bool
+		expectErr  bool
+	}{
+		{name: "Recipient opens the payload", publicKey: public, privateKey: private},
+		{name: "Another client cannot open it", publicKey: public, privateKey: otherPrivate, expectErr: true},
+		{name: "Tampered payload is rejected", publicKey: public, privateKey: private, tamper: true, expectErr: true},
+		{name: "Invalid public key", publicKey: "bm90LWEta2V5", expectErr: true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			input := TestStruct{Name: "Alice", Age: 30, Email: "alice@example.com"}
+
+			sealed, err := alchemy.StructSealedFor(input, tc.publicKey)
+			if tc.privateKey == "" {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			if tc.tamper {
+				raw := []byte(sealed)
+				raw[len(raw)-5] ^= 1
+				sealed = string(raw)
+			}
+
+			var output TestStruct
+			err = alchemy.OpenSealedStruct(tc.privateKey, sealed, &output)
+			if tc.expectErr {
+				assert.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, input, output)
+		})
+	}
+}
@@ -0,0 +1,303 @@
+package alchemy
+
+import (
+	"bufio"
+	"compress/gzip"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// Sealed stream layout:
+//
+//	header: magic "WRTH" | version (1) | flags (1) | chunk size (4) | salt (16)
+//	frames: ciphertext length (4) | AES-GCM ciphertext, repeated
+//
+// Every file gets its own key, derived from the secret and the random salt.
+// The nonce of a frame is its counter plus a flag marking the final frame, and
+// the header is authenticated with every frame, so reordered, dropped or
+// truncated frames and a modified header all fail to open.
+const (
+	DefaultChunkSize = 64 * 1024
+
+	streamMagic      = "WRTH"
+	streamVersion    = 1
+	streamFlagGzip   = 1
+	streamSaltSize   = 16
+	streamHeaderSize = len(streamMagic) + 2 + 4 + streamSaltSize
+	streamMaxChunk   = 16 * 1024 * 1024
+)
+
+var (
+	ErrStreamFormat    = errors.New("alchemy: not a sealed stream")
+	ErrStreamTruncated = errors.New("alchemy: sealed stream is truncated")
+	ErrStreamCorrupted = errors.New("alchemy: sealed stream failed authentication")
+)
+
+// SealStream returns a writer that encrypts everything written to it into dst.
+// Close must be called to write the final frame; it does not close dst.
+func SealStream(dst io.Writer, secret string, compress bool, chunkSize int) (io.WriteCloser, error) {
+	if chunkSize <= 0 {
+		chunkSize = DefaultChunkSize
+	}
+	if chunkSize > streamMaxChunk {
+		return nil, fmt.Errorf("alchemy: chunk size %d exceeds %d", chunkSize, streamMaxChunk)
+	}
+
+	header := make([]byte, streamHeaderSize)
+	copy(header, streamMagic)
+	header[4] = streamVersion
+	if compress {
+		header[5] = streamFlagGzip
+	}
+	binary.BigEndian.PutUint32(header[6:10], uint32(chunkSize))
+	if _, err := io.ReadFull(rand.Reader, header[10:]); err != nil {
+		return nil, err
+	}
+
+	aead, err := streamCipher(secret, header[10:])
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := dst.Write(header); err != nil {
+		return nil, err
+	}
+
+	sw := &sealWriter{
+		dst:    dst,
+		aead:   aead,
+		header: header,
+		buf:    make([]byte, 0, chunkSize),
+	}
+
+	if !compress {
+		return sw, nil
+	}
+
+	return &gzipSealWriter{Writer: gzip.NewWriter(sw), sealed: sw}, nil
+}
+
+// OpenStream returns a reader with the plaintext of a stream produced by
+// SealStream. Authentication errors surface from Read.
+func OpenStream(src io.Reader, secret string) (io.Reader, error) {
+	header := make([]byte, streamHeaderSize)
+	if _, err := io.ReadFull(src, header); err != nil {
+		return nil, ErrStreamFormat
+	}
+
+	if !IsSealedStream(header) || header[4] != streamVersion {
+		return nil, ErrStreamFormat
+	}
+
+	chunkSize := int(binary.BigEndian.Uint32(header[6:10]))
+	if chunkSize <= 0 || chunkSize > streamMaxChunk {
+		return nil, ErrStreamFormat
+	}
+
+	aead, err := streamCipher(secret, header[10:])
+	if err != nil {
+		return nil, err
+	}
+
+	or := &openReader{
+		src:      bufio.NewReader(src),
+		aead:     aead,
+		header:   header,
+		maxFrame: chunkSize + aead.Overhead(),
+	}
+
+	if header[5]&streamFlagGzip == 0 {
+		return or, nil
+	}
+
+	return gzip.NewReader(or)
+}
+
+// IsSealedStream reports whether prefix starts with a sealed stream header.
+func IsSealedStream(prefix []byte) bool {
+	return len(prefix) >= len(streamMagic) && string(prefix[:len(streamMagic)]) == streamMagic
+}
+
+func streamCipher(secret string, salt []byte) (cipher.AEAD, error) {
+	mac := hmac.New(sha256.New, GenerateKey(secret))
+	mac.Write(salt)
+
+	block, err := aes.NewCipher(mac.Sum(nil))
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
+
+func streamNonce(size int, counter uint32, last bool) []byte {
+	nonce := make([]byte, size)
+	binary.BigEndian.PutUint32(nonce[size-5:size-1], counter)
+	if last {
+		nonce[size-1] = 1
+	}
+	return nonce
+}
+
+type sealWriter struct {
+	dst     io.Writer
+	aead    cipher.AEAD
+	header  []byte
+	buf     []byte
+	out     []byte
+	counter uint32
+	closed  bool
+}
+
+func (sw *sealWriter) Write(p []byte) (int, error) {
+	if sw.closed {
+		return 0, errors.New("alchemy: write to closed stream")
+	}
+
+	written := 0
+	for len(p) > 0 {
+		// A full buffer is only flushed once more data arrives, so the last
+		// frame is always the one written by Close.
+		if len(sw.buf) == cap(sw.buf) {
+			if err := sw.flush(false); err != nil {
+				return written, err
+			}
+		}
+
+		n := copy(sw.buf[len(sw.buf):cap(sw.buf)], p)
+		sw.buf = sw.buf[:len(sw.buf)+n]
+		p = p[n:]
+		written += n
+	}
+
+	return written, nil
+}
+
+func (sw *sealWriter) Close() error {
+	if sw.closed {
+		return nil
+	}
+	sw.closed = true
+
+	return sw.flush(true)
+}
+
+func (sw *sealWriter) flush(last bool) error {
+	if sw.counter == ^uint32(0) {
+		return errors.New("alchemy: sealed stream is too long")
+	}
+
+	nonce := streamNonce(sw.aead.NonceSize(), sw.counter, last)
+	sw.out = sw.aead.Seal(sw.out[:0], nonce, sw.buf, sw.header)
+
+	var size [4]byte
+	binary.BigEndian.PutUint32(size[:], uint32(len(sw.out)))
+	if _, err := sw.dst.Write(size[:]); err != nil {
+		return err
+	}
+	if _, err := sw.dst.Write(sw.out); err != nil {
+		return err
+	}
+
+	sw.counter++
+	sw.buf = sw.buf[:0]
+	return nil
+}
+
+type gzipSealWriter struct {
+	*gzip.Writer
+	sealed *sealWriter
+}
+
+func (gw *gzipSealWriter) Close() error {
+	if err := gw.Writer.Close(); err != nil {
+		return err
+	}
+
+	return gw.sealed.Close()
+}
+
+type openReader struct {
+	src      *bufio.Reader
+	aead     cipher.AEAD
+	header   []byte
+	maxFrame int
+	frame    []byte
+	out      []byte
+	plain    []byte
+	counter  uint32
+	done     bool
+	err      error
+}
+
+func (or *openReader) Read(p []byte) (int, error) {
+	for len(or.plain) == 0 {
+		if or.err != nil {
+			return 0, or.err
+		}
+		if or.done {
+			return 0, io.EOF
+		}
+		or.err = or.next()
+	}
+
+	n := copy(p, or.plain)
+	or.plain = or.plain[n:]
+	return n, nil
+}
+
+func (or *openReader) next() error {
+	var size [4]byte
+	if _, err := io.ReadFull(or.src, size[:]); err != nil {
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			return ErrStreamTruncated
+		}
+		return err
+	}
+
+	frameSize := int(binary.BigEndian.Uint32(size[:]))
+	if frameSize < or.aead.Overhead() || frameSize > or.maxFrame {
+		return ErrStreamCorrupted
+	}
+
+	if cap(or.frame) < frameSize {
+		or.frame = make([]byte, frameSize)
+	}
+	or.frame = or.frame[:frameSize]
+	if _, err := io.ReadFull(or.src, or.frame); err != nil {
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			return ErrStreamTruncated
+		}
+		return err
+	}
+
+	_, err := or.src.Peek(1)
+	last := errors.Is(err, io.EOF)
+
+	nonce := streamNonce(or.aead.NonceSize(), or.counter, last)
+	plain, err := or.aead.Open(or.out[:0], nonce, or.frame, or.header)
+	if err != nil {
+		if !last {
+			return ErrStreamCorrupted
+		}
+		// The frame may be intact but not meant to be the last one.
+		nonce = streamNonce(or.aead.NonceSize(), or.counter, false)
+		if _, err := or.aead.Open(nil, nonce, or.frame, or.header); err == nil {
+			return ErrStreamTruncated
+		}
+		return ErrStreamCorrupted
+	}
+
+	or.out = plain
+	or.plain = plain
+	or.counter++
+	or.done = last
+	return nil
+}
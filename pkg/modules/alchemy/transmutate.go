@@ -1,37 +1,311 @@
 package alchemy
 
 import (
+	"errors"
+	"fmt"
 	"reflect"
+	"strings"
+	"sync"
 )
 
+// TagName is the struct tag read by Transmutation and Revert.
+//
+//	Name  string `alchemy:"encrypt"` // randomized encryption
+//	Email string `alchemy:"index"`   // deterministic encryption, usable in lookups
+//	ID    string `alchemy:"-"`       // never touched, not even when nested
+//
+// Untagged fields are left as they are, but nested structs, slices and maps
+// are still walked so their own tagged fields are handled. A tag on a
+// container field applies to every string found inside it.
+const TagName = "alchemy"
+
+const (
+	tagEncrypt = "encrypt"
+	tagIndex   = "index"
+	tagSkip    = "-"
+)
+
+type mode int
+
+const (
+	modeNone mode = iota
+	modeEncrypt
+	modeIndex
+	modeSkip
+)
+
+// FieldError reports the exact field path that could not be transmuted,
+// e.g. "User.Meta[token]" or "Order.Items[2].Sku".
+type FieldError struct {
+	Path string
+	Err  error
+}
+
+func (e *FieldError) Error() string {
+	return fmt.Sprintf("alchemy: field %s: %v", e.Path, e.Err)
+}
+
+func (e *FieldError) Unwrap() error {
+	return e.Err
+}
+
+type fieldMeta struct {
+	index int
+	name  string
+	mode  mode
+}
+
+var fieldsCache sync.Map // reflect.Type -> []fieldMeta
+
 func Transmutation(entity interface{}, secret string) error {
-	val := reflect.ValueOf(entity).Elem()
+	return transmute(entity, func(value string, m mode) (string, error) {
+		if m == modeIndex {
+			return EncryptIndex(value, secret)
+		}
+		return Encrypt(value, secret)
+	})
+}
+
+func Revert(entity interface{}, secret string) error {
+	return transmute(entity, func(value string, _ mode) (string, error) {
+		return Decrypt(value, secret)
+	})
+}
+
+type transform func(value string, m mode) (string, error)
 
-	for i := 0; i < val.NumField(); i++ {
-		field := val.Field(i)
-		if field.Kind() == reflect.String && field.CanSet() {
-			encrypted, err := Encrypt(secret, field.String())
+func transmute(entity interface{}, fn transform) error {
+	val := reflect.ValueOf(entity)
+	if val.Kind() != reflect.Pointer || val.IsNil() {
+		return errors.New("alchemy: entity must be a non-nil pointer")
+	}
+
+	for val.Kind() == reflect.Pointer {
+		if val.IsNil() {
+			return nil
+		}
+		val = val.Elem()
+	}
+
+	return walk(val, val.Type().Name(), modeNone, fn)
+}
+
+func walk(val reflect.Value, path string, m mode, fn transform) error {
+	if m == modeSkip {
+		return nil
+	}
+
+	switch val.Kind() {
+	case reflect.Pointer:
+		if val.IsNil() {
+			return nil
+		}
+		return walk(val.Elem(), path, m, fn)
+
+	case reflect.Interface:
+		if val.IsNil() {
+			return nil
+		}
+		// Values held by an interface are not addressable, so work on a copy
+		// and store it back.
+		inner := reflect.New(val.Elem().Type()).Elem()
+		inner.Set(val.Elem())
+		if err := walk(inner, path, m, fn); err != nil {
+			return err
+		}
+		if val.CanSet() {
+			val.Set(inner)
+		}
+		return nil
+
+	case reflect.String:
+		if m == modeNone || val.Len() == 0 || !val.CanSet() {
+			return nil
+		}
+		result, err := fn(val.String(), m)
+		if err != nil {
+			return &FieldError{Path: path, Err: err}
+		}
+		val.SetString(result)
+		return nil
+
+	case reflect.Struct:
+		for _, field := range fieldsOf(val.Type()) {
+			fieldMode := field.mode
+			if fieldMode == modeNone {
+				fieldMode = m
+			}
+			err := walk(val.Field(field.index), path+"."+field.name, fieldMode, fn)
 			if err != nil {
 				return err
 			}
-			field.SetString(encrypted)
 		}
-	}
-	return nil
-}
+		return nil
 
-func Revert(entity interface{}, secret string) error {
-	val := reflect.ValueOf(entity).Elem()
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < val.Len(); i++ {
+			err := walk(val.Index(i), fmt.Sprintf("%s[%d]", path, i), m, fn)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
 
-	for i := 0; i < val.NumField(); i++ {
-		field := val.Field(i)
-		if field.Kind() == reflect.String && field.CanSet() {
-			decrypted, err := Decrypt(field.String(), secret)
+	case reflect.Map:
+		if val.IsNil() {
+			return nil
+		}
+		iter := val.MapRange()
+		for iter.Next() {
+			// Map values are not addressable either, same trick as interfaces.
+			item := reflect.New(val.Type().Elem()).Elem()
+			item.Set(iter.Value())
+			err := walk(item, fmt.Sprintf("%s[%v]", path, iter.Key()), m, fn)
 			if err != nil {
 				return err
 			}
-			field.SetString(decrypted)
+			val.SetMapIndex(iter.Key(), item)
 		}
+		return nil
+
+	default:
+		return nil
 	}
-	return nil
+}
+
+// fieldsOf returns the exported fields of a struct type together with their
+// alchemy mode. The result is computed once per type.
+func fieldsOf(t reflect.Type) []fieldMeta {
+	if cached, ok := fieldsCache.Load(t); ok {
+		return cached.([]fieldMeta)
+	}
+
+	fields := make([]fieldMeta, 0, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		fields = append(fields, fieldMeta{
+			index: i,
+			name:  field.Name,
+			mode:  parseTag(field.Tag.Get(TagName)),
+		})
+	}
+
+	cached, _ := fieldsCache.LoadOrStore(t, fields)
+	return cached.([]fieldMeta)
+}
+
+func parseTag(tag string) mode {
+	name, _, _ := strings.Cut(tag, ",")
+	switch strings.TrimSpace(name) {
+	case tagEncrypt:
+		return modeEncrypt
+	case tagIndex:
+		return modeIndex
+	case tagSkip:
+		return modeSkip
+	default:
+		return modeNone
+	}
+}
+
+// State describes whether the protected fields of an entity are encrypted.
+type State int
+
+const (
+	StateEmpty State = iota // no protected field holds a value
+	StatePlain
+	StateSealed
+	StateMixed
+)
+
+func (s State) String() string {
+	switch s {
+	case StatePlain:
+		return "plain"
+	case StateSealed:
+		return "sealed"
+	case StateMixed:
+		return "mixed"
+	default:
+		return "empty"
+	}
+}
+
+// Inspect reports the encryption state of the tagged fields of entity. A
+// field counts as sealed when it decrypts with secret.
+func Inspect(entity interface{}, secret string) (State, error) {
+	var sealed, plain int
+	err := transmute(entity, func(value string, _ mode) (string, error) {
+		if _, err := Decrypt(value, secret); err != nil {
+			plain++
+		} else {
+			sealed++
+		}
+		return value, nil
+	})
+	if err != nil {
+		return StateEmpty, err
+	}
+
+	switch {
+	case sealed > 0 && plain > 0:
+		return StateMixed, nil
+	case sealed > 0:
+		return StateSealed, nil
+	case plain > 0:
+		return StatePlain, nil
+	default:
+		return StateEmpty, nil
+	}
+}
+
+// Seal is an idempotent Transmutation: fields that are already encrypted are
+// left alone, except index fields, which are rewritten with their
+// deterministic form so lookups keep matching. It returns how many fields
+// changed.
+func Seal(entity interface{}, secret string) (int, error) {
+	changed := 0
+	err := transmute(entity, func(value string, m mode) (string, error) {
+		plain, err := Decrypt(value, secret)
+		if err != nil {
+			plain = value
+		} else if m != modeIndex {
+			return value, nil
+		}
+
+		var result string
+		if m == modeIndex {
+			result, err = EncryptIndex(plain, secret)
+		} else {
+			result, err = Encrypt(plain, secret)
+		}
+		if err != nil {
+			return "", err
+		}
+		if result != value {
+			changed++
+		}
+		return result, nil
+	})
+
+	return changed, err
+}
+
+// Unseal is an idempotent Revert: fields that do not decrypt are assumed to be
+// plain already. It returns how many fields changed.
+func Unseal(entity interface{}, secret string) (int, error) {
+	changed := 0
+	err := transmute(entity, func(value string, _ mode) (string, error) {
+		plain, err := Decrypt(value, secret)
+		if err != nil {
+			return value, nil
+		}
+		changed++
+		return plain, nil
+	})
+
+	return changed, err
 }
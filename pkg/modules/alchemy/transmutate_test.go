@@ -1,13 +1,35 @@
 package alchemy_test
 
 import (
+	"errors"
 	"project-wraith/pkg/modules/alchemy"
+	"strings"
 	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 type TestEntity struct {
-	Field1 string
-	Field2 string
+	Field1 string `alchemy:"encrypt"`
+	Field2 string `alchemy:"encrypt"`
+}
+
+type TestAddress struct {
+	Street string `alchemy:"encrypt"`
+	City   string
+}
+
+type TestProfile struct {
+	ID        string `alchemy:"-"`
+	Email     string `alchemy:"index"`
+	Status    string
+	Address   TestAddress
+	Previous  []TestAddress
+	Aliases   []string               `alchemy:"encrypt"`
+	Meta      map[string]interface{} `alchemy:"encrypt"`
+	Secondary *TestAddress
+	Ignored   TestAddress `alchemy:"-"`
 }
 
 func TestTransmutation(t *testing.T) {
@@ -84,3 +106,123 @@ func TestRevert(t *testing.T) {
 		})
 	}
 }
+
+func TestTransmutationTags(t *testing.T) {
+	secret := "supersecretkey"
+
+	newProfile := func() TestProfile {
+		return TestProfile{
+			ID:       "id-1",
+			Email:    "alice@example.com",
+			Status:   "active",
+			Address:  TestAddress{Street: "Main 1", City: "Springfield"},
+			Previous: []TestAddress{{Street: "Old 2", City: "Shelbyville"}},
+			Aliases:  []string{"al", "ally"},
+			Meta: map[string]interface{}{
+				"token":  "abc",
+				"count":  3,
+				"nested": map[string]interface{}{"note": "hidden"},
+			},
+			Secondary: &TestAddress{Street: "Second 3", City: "Ogdenville"},
+			Ignored:   TestAddress{Street: "Keep 4", City: "North Haverbrook"},
+		}
+	}
+
+	tests := []struct {
+		name   string
+		assert func(t *testing.T, original, secured TestProfile)
+	}{
+		{
+			name: "Untagged and skipped fields are kept",
+			assert: func(t *testing.T, original, secured TestProfile) {
+				assert.Equal(t, original.ID, secured.ID)
+				assert.Equal(t, original.Status, secured.Status)
+				assert.Equal(t, original.Address.City, secured.Address.City)
+				assert.Equal(t, original.Ignored, secured.Ignored)
+				assert.Equal(t, 3, secured.Meta["count"])
+			},
+		},
+		{
+			name: "Nested structs, slices and maps are encrypted",
+			assert: func(t *testing.T, original, secured TestProfile) {
+				assert.NotEqual(t, original.Address.Street, secured.Address.Street)
+				assert.NotEqual(t, original.Previous[0].Street, secured.Previous[0].Street)
+				assert.NotEqual(t, original.Aliases[1], secured.Aliases[1])
+				assert.NotEqual(t, original.Secondary.Street, secured.Secondary.Street)
+				assert.NotEqual(t, "abc", secured.Meta["token"])
+				assert.NotEqual(t, "hidden", secured.Meta["nested"].(map[string]interface{})["note"])
+			},
+		},
+		{
+			name: "Index fields are deterministic",
+			assert: func(t *testing.T, original, secured TestProfile) {
+				lookup := TestProfile{Email: original.Email}
+				require.NoError(t, alchemy.Transmutation(&lookup, secret))
+				assert.NotEqual(t, original.Email, secured.Email)
+				assert.Equal(t, secured.Email, lookup.Email)
+			},
+		},
+		{
+			name: "Revert restores the original entity",
+			assert: func(t *testing.T, original, secured TestProfile) {
+				require.NoError(t, alchemy.Revert(&secured, secret))
+				assert.Equal(t, original, secured)
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			secured := newProfile()
+			require.NoError(t, alchemy.Transmutation(&secured, secret))
+			tc.assert(t, newProfile(), secured)
+		})
+	}
+}
+
+func TestTransmutationErrors(t *testing.T) {
+	secret := "supersecretkey"
+
+	tests := []struct {
+		name         string
+		input        interface{}
+		expectedPath string
+	}{
+		{
+			name:         "Not a pointer",
+			input:        TestEntity{},
+			expectedPath: "",
+		},
+		{
+			name: "Invalid ciphertext in nested slice",
+			input: &TestProfile{
+				Previous: []TestAddress{{Street: "not-hex"}},
+			},
+			expectedPath: "TestProfile.Previous[0].Street",
+		},
+		{
+			name: "Invalid ciphertext in map",
+			input: &TestProfile{
+				Meta: map[string]interface{}{"token": "not-hex"},
+			},
+			expectedPath: "TestProfile.Meta[token]",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := alchemy.Revert(tc.input, secret)
+			require.Error(t, err)
+
+			var fieldErr *alchemy.FieldError
+			if tc.expectedPath == "" {
+				assert.False(t, errors.As(err, &fieldErr))
+				return
+			}
+
+			require.True(t, errors.As(err, &fieldErr))
+			assert.Equal(t, tc.expectedPath, fieldErr.Path)
+			assert.True(t, strings.Contains(err.Error(), tc.expectedPath))
+		})
+	}
+}
@@ -1,45 +1,138 @@
 package alchemy
 
 import (
+	"bufio"
+	"bytes"
+	"io"
 	"os"
+	"path/filepath"
+	"strings"
 )
 
 type VaultKeeper interface {
 	Secure(filePath string, replace bool) error
 	ObliterateFile(filePath string) error
 	Release(filePath string) (string, error)
+	ReleaseTo(filePath string, dst io.Writer) error
+	SealWriter(dst io.Writer) (io.WriteCloser, error)
+	OpenReader(src io.Reader) (io.Reader, error)
 }
 
 type ArcaneVault struct {
-	secret string
+	secret    string
+	compress  bool
+	chunkSize int
 }
 
+// NewArcaneVault is a function constructor for VaultKeeper. Files are sealed in
+// chunks of chunkSize bytes (DefaultChunkSize when zero), gzip-compressed
+// first when compress is set, so memory use does not depend on file size.
+func NewArcaneVault(secret string, compress bool, chunkSize int) VaultKeeper {
+	return &ArcaneVault{
+		secret:    secret,
+		compress:  compress,
+		chunkSize: chunkSize,
+	}
+}
+
+func (av *ArcaneVault) SealWriter(dst io.Writer) (io.WriteCloser, error) {
+	return SealStream(dst, av.secret, av.compress, av.chunkSize)
+}
+
+func (av *ArcaneVault) OpenReader(src io.Reader) (io.Reader, error) {
+	return OpenStream(src, av.secret)
+}
+
+// Secure seals filePath into filePath + ".secured", or in place when replace
+// is set. The output is written to a temporary file first, so an interrupted
+// run never leaves a half-sealed file behind.
 func (av *ArcaneVault) Secure(filePath string, replace bool) error {
-	scroll, err := os.ReadFile(filePath)
+	scroll, err := os.Open(filePath)
 	if err != nil {
 		return err
 	}
+	defer scroll.Close()
 
-	securedScroll, err := Encrypt(string(scroll), av.secret)
+	target := filePath + ".secured"
+	if replace {
+		target = filePath
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(target), filepath.Base(target)+".*.tmp")
 	if err != nil {
 		return err
 	}
+	defer os.Remove(tmp.Name())
 
-	if replace {
-		return os.WriteFile(filePath, []byte(securedScroll), 0644)
+	sealed, err := av.SealWriter(tmp)
+	if err == nil {
+		_, err = io.Copy(sealed, scroll)
+		if closeErr := sealed.Close(); err == nil {
+			err = closeErr
+		}
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := os.Chmod(tmp.Name(), 0644); err != nil {
+		return err
 	}
 
-	newFilePath := filePath + ".secured"
-	return os.WriteFile(newFilePath, []byte(securedScroll), 0644)
+	return os.Rename(tmp.Name(), target)
 }
 
+// Release returns the whole content of a sealed file. Prefer ReleaseTo for
+// large files.
 func (av *ArcaneVault) Release(filePath string) (string, error) {
-	securedScroll, err := os.ReadFile(filePath)
-	if err != nil {
+	var scroll strings.Builder
+	if err := av.ReleaseTo(filePath, &scroll); err != nil {
 		return "", err
 	}
 
-	return Decrypt(string(securedScroll), av.secret)
+	return scroll.String(), nil
+}
+
+// ReleaseTo streams the plaintext of a sealed file into dst. Files sealed
+// with the older single-blob hex format are still accepted.
+func (av *ArcaneVault) ReleaseTo(filePath string, dst io.Writer) error {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	src := bufio.NewReader(file)
+	prefix, _ := src.Peek(len(streamMagic))
+	if !IsSealedStream(prefix) {
+		return av.releaseLegacy(src, dst)
+	}
+
+	plain, err := av.OpenReader(src)
+	if err != nil {
+		return err
+	}
+
+	_, err = io.Copy(dst, plain)
+	return err
+}
+
+func (av *ArcaneVault) releaseLegacy(src io.Reader, dst io.Writer) error {
+	securedScroll, err := io.ReadAll(src)
+	if err != nil {
+		return err
+	}
+
+	scroll, err := Decrypt(string(bytes.TrimSpace(securedScroll)), av.secret)
+	if err != nil {
+		return err
+	}
+
+	_, err = io.WriteString(dst, scroll)
+	return err
 }
 
 func (av *ArcaneVault) ObliterateFile(filePath string) error {
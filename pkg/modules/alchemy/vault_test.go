@@ -0,0 +1,168 @@
+package alchemy_test
+
+import (
+	"bytes"
+	"crypto/rand"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"project-wraith/pkg/modules/alchemy"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVaultStreamRoundTrip(t *testing.T) {
+	secret := "supersecretkey"
+
+	random := make([]byte, 10_000)
+	_, err := rand.Read(random)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		compress bool
+		input    []byte
+	}{
+		{name: "Empty input", input: []byte{}},
+		{name: "Smaller than a chunk", input: []byte("a single line of logs\n")},
+		{name: "Exactly one chunk", input: bytes.Repeat([]byte("x"), 64)},
+		{name: "Several chunks", input: random},
+		{name: "Several chunks compressed", compress: true, input: bytes.Repeat([]byte("log line\n"), 2_000)},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			vault := alchemy.NewArcaneVault(secret, tc.compress, 64)
+
+			var sealed bytes.Buffer
+			writer, err := vault.SealWriter(&sealed)
+			require.NoError(t, err)
+			_, err = io.Copy(writer, bytes.NewReader(tc.input))
+			require.NoError(t, err)
+			require.NoError(t, writer.Close())
+
+			assert.False(t, bytes.Contains(sealed.Bytes(), []byte("log line")))
+
+			reader, err := vault.OpenReader(bytes.NewReader(sealed.Bytes()))
+			require.NoError(t, err)
+			plain, err := io.ReadAll(reader)
+			require.NoError(t, err)
+			assert.Equal(t, tc.input, plain)
+		})
+	}
+}
+
+func TestVaultStreamTampering(t *testing.T) {
+	secret := "supersecretkey"
+	vault := alchemy.NewArcaneVault(secret, false, 16)
+
+	var sealed bytes.Buffer
+	writer, err := vault.SealWriter(&sealed)
+	require.NoError(t, err)
+	_, err = writer.Write(bytes.Repeat([]byte("0123456789"), 10))
+	require.NoError(t, err)
+	require.NoError(t, writer.Close())
+
+	header := 4 + 1 + 1 + 4 + 16 // magic, version, flags, chunk size, salt
+	frame := 4 + 16 + 16         // length prefix, chunk, GCM tag
+
+	tests := []struct {
+		name     string
+		mutate   func(b []byte) []byte
+		secret   string
+		expected error
+	}{
+		{
+			name:     "Last frame dropped",
+			mutate:   func(b []byte) []byte { return b[:len(b)-(4+4+16)] },
+			secret:   secret,
+			expected: alchemy.ErrStreamTruncated,
+		},
+		{
+			name:     "Cut inside a frame",
+			mutate:   func(b []byte) []byte { return b[:header+frame+10] },
+			secret:   secret,
+			expected: alchemy.ErrStreamTruncated,
+		},
+		{
+			name: "Flipped ciphertext bit",
+			mutate: func(b []byte) []byte {
+				b[header+frame+8] ^= 1
+				return b
+			},
+			secret:   secret,
+			expected: alchemy.ErrStreamCorrupted,
+		},
+		{
+			name: "Frames reordered",
+			mutate: func(b []byte) []byte {
+				out := append([]byte{}, b[:header]...)
+				out = append(out, b[header+frame:header+2*frame]...)
+				out = append(out, b[header:header+frame]...)
+				return append(out, b[header+2*frame:]...)
+			},
+			secret:   secret,
+			expected: alchemy.ErrStreamCorrupted,
+		},
+		{
+			name:     "Wrong secret",
+			mutate:   func(b []byte) []byte { return b },
+			secret:   "another",
+			expected: alchemy.ErrStreamCorrupted,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			input := tc.mutate(append([]byte{}, sealed.Bytes()...))
+
+			reader, err := alchemy.OpenStream(bytes.NewReader(input), tc.secret)
+			require.NoError(t, err)
+			_, err = io.ReadAll(reader)
+			assert.True(t, errors.Is(err, tc.expected), "got %v", err)
+		})
+	}
+}
+
+func TestVaultFiles(t *testing.T) {
+	secret := "supersecretkey"
+	dir := t.TempDir()
+	content := "line one\nline two\n"
+
+	tests := []struct {
+		name    string
+		replace bool
+		legacy  bool
+		target  string
+	}{
+		{name: "Secure to a new file", target: "app.log.secured"},
+		{name: "Secure in place", replace: true, target: "app.log"},
+		{name: "Release legacy hex file", legacy: true, target: "app.log"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			path := filepath.Join(dir, "app.log")
+			vault := alchemy.NewArcaneVault(secret, true, 0)
+
+			if tc.legacy {
+				legacy, err := alchemy.Encrypt(content, secret)
+				require.NoError(t, err)
+				require.NoError(t, os.WriteFile(path, []byte(legacy), 0644))
+			} else {
+				require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+				require.NoError(t, vault.Secure(path, tc.replace))
+			}
+
+			released, err := vault.Release(filepath.Join(dir, tc.target))
+			require.NoError(t, err)
+			assert.Equal(t, content, released)
+
+			require.NoError(t, vault.ObliterateFile(filepath.Join(dir, tc.target)))
+			_ = os.Remove(path)
+		})
+	}
+}
@@ -15,13 +15,11 @@ type Client interface {
 	Close() error
 	Collection(coll string) *mongo.Collection
 	Client() *mongo.Client
-	Ctx() context.Context
 }
 
 type client struct {
 	client *mongo.Client
 	Db     *mongo.Database
-	ctx    context.Context
 	uri    string
 	dbName string
 }
@@ -47,7 +45,6 @@ func (mc *client) Open() error {
 
 	mc.client = client
 	mc.Db = client.Database(mc.dbName)
-	mc.ctx = ctx
 
 	return nil
 }
@@ -67,7 +64,3 @@ func (mc *client) Collection(coll string) *mongo.Collection {
 func (mc *client) Client() *mongo.Client {
 	return mc.client
 }
-
-func (mc *client) Ctx() context.Context {
-	return mc.ctx
-}
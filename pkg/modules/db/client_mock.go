@@ -1,7 +1,6 @@
 package db
 
 import (
-	"context"
 	"github.com/stretchr/testify/mock"
 	"go.mongodb.org/mongo-driver/mongo"
 )
@@ -32,14 +31,6 @@ func (mc *MockClient) Collection(coll string) *mongo.Collection {
 	return nil
 }
 
-func (mc *MockClient) Ctx() context.Context {
-	args := mc.Called()
-	if ctx, ok := args.Get(0).(context.Context); ok {
-		return ctx
-	}
-	return nil
-}
-
 func (mc *MockClient) Client() *mongo.Client {
 	args := mc.Called()
 	return args.Get(0).(*mongo.Client)
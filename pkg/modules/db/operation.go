@@ -0,0 +1,46 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+var (
+	// ErrCanceled marks an operation given up because its context was
+	// canceled, such as by a client that hung up.
+	ErrCanceled = errors.New("database operation canceled")
+	// ErrTimeout marks an operation that ran past its deadline.
+	ErrTimeout = errors.New("database operation timed out")
+)
+
+// Operation bounds one database operation by timeout, or by the deadline of
+// ctx when that comes first. A zero timeout leaves ctx unbounded.
+func Operation(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout <= 0 {
+		return ctx, func() {}
+	}
+
+	return context.WithTimeout(ctx, timeout)
+}
+
+// Interrupted wraps err in ErrCanceled or ErrTimeout when the operation was
+// cut short by its context or the configured timeout, so callers can tell
+// those apart from a failing database. Other errors are returned as is.
+func Interrupted(err error) error {
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, ErrCanceled), errors.Is(err, ErrTimeout):
+		return err
+	case errors.Is(err, context.Canceled):
+		return fmt.Errorf("%w: %w", ErrCanceled, err)
+	case errors.Is(err, context.DeadlineExceeded), mongo.IsTimeout(err):
+		return fmt.Errorf("%w: %w", ErrTimeout, err)
+	}
+
+	return err
+}
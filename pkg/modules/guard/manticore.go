@@ -5,7 +5,11 @@ import (
 	"errors"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"project-wraith/pkg/modules/db"
+	"project-wraith/pkg/modules/metrics"
 	"project-wraith/pkg/modules/tools"
+	"time"
 )
 
 type Credentials struct {
@@ -14,34 +18,42 @@ type Credentials struct {
 }
 
 type Manticore interface {
-	StingAndProwl(Credentials) error
+	StingAndProwl(ctx context.Context, cred Credentials) error
+	// AddOperator stores cred, replacing the password of an operator with
+	// the same username.
+	AddOperator(ctx context.Context, cred Credentials) error
 }
 
 type manticore struct {
 	collection *mongo.Collection
-	ctx        context.Context
+	timeout    time.Duration
 	passSecret string
 }
 
-func NewManticore(collection mongo.Collection, ctx context.Context, passSecret string) Manticore {
+func NewManticore(collection mongo.Collection, timeout time.Duration, passSecret string) Manticore {
 	return &manticore{
 		collection: &collection,
-		ctx:        ctx,
+		timeout:    timeout,
 		passSecret: passSecret,
 	}
 }
 
-func (m *manticore) StingAndProwl(cred Credentials) error {
+func (m *manticore) StingAndProwl(ctx context.Context, cred Credentials) error {
 	filter := bson.M{"username": cred.Username}
 
+	ctx, cancel := db.Operation(ctx, m.timeout)
+	defer cancel()
+
 	var result Credentials
-	err := m.collection.FindOne(context.Background(), filter).Decode(&result)
+	done := metrics.Mongo(m.collection.Name(), "sting_and_prowl")
+	err := m.collection.FindOne(ctx, filter).Decode(&result)
+	done(err)
 	if err != nil {
 		if errors.Is(err, mongo.ErrNoDocuments) {
 			return errors.New("user not found")
 		}
 
-		return err
+		return db.Interrupted(err)
 	}
 
 	if result.Password != tools.Sha512(m.passSecret, cred.Password) {
@@ -50,3 +62,28 @@ func (m *manticore) StingAndProwl(cred Credentials) error {
 
 	return nil
 }
+
+func (m *manticore) AddOperator(ctx context.Context, cred Credentials) error {
+	if cred.Username == "" || cred.Password == "" {
+		return errors.New("username and password are required")
+	}
+
+	operator := Credentials{
+		Username: cred.Username,
+		Password: tools.Sha512(m.passSecret, cred.Password),
+	}
+
+	ctx, cancel := db.Operation(ctx, m.timeout)
+	defer cancel()
+
+	done := metrics.Mongo(m.collection.Name(), "add_operator")
+	_, err := m.collection.ReplaceOne(
+		ctx,
+		bson.M{"username": cred.Username},
+		operator,
+		options.Replace().SetUpsert(true),
+	)
+	done(err)
+
+	return db.Interrupted(err)
+}
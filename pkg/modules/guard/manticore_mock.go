@@ -1,6 +1,7 @@
 package guard
 
 import (
+	"context"
 	"github.com/stretchr/testify/mock"
 )
 
@@ -8,6 +9,10 @@ type MockManticore struct {
 	mock.Mock
 }
 
-func (m *MockManticore) StingAndProwl(url string) error {
-	return m.Called().Error(0)
+func (m *MockManticore) StingAndProwl(ctx context.Context, cred Credentials) error {
+	return m.Called(ctx, cred).Error(0)
+}
+
+func (m *MockManticore) AddOperator(ctx context.Context, cred Credentials) error {
+	return m.Called(ctx, cred).Error(0)
 }
@@ -9,6 +9,7 @@ import (
 	"project-wraith/pkg/modules/guard"
 	"project-wraith/pkg/modules/tools"
 	"testing"
+	"time"
 )
 
 func TestManticore(test *testing.T) {
@@ -58,9 +59,9 @@ func TestManticore(test *testing.T) {
 			tc.setupMocks(mongoTest)
 
 			collection := mongoTest.Coll
-			repo := guard.NewManticore(*collection, context.TODO(), "secret")
+			repo := guard.NewManticore(*collection, time.Second, "secret")
 
-			err := repo.StingAndProwl(tc.credentials)
+			err := repo.StingAndProwl(context.TODO(), tc.credentials)
 			if tc.expectedErr != nil {
 				assert.EqualError(test, err, tc.expectedErr.Error())
 			} else {
@@ -69,3 +70,44 @@ func TestManticore(test *testing.T) {
 		})
 	}
 }
+
+func TestAddOperator(test *testing.T) {
+	test.Parallel()
+
+	mt := mtest.New(test, mtest.NewOptions().ClientType(mtest.Mock))
+
+	testCases := []struct {
+		name        string
+		credentials guard.Credentials
+		expectedErr error
+	}{
+		{
+			name:        "operator saved",
+			credentials: guard.Credentials{Username: "user1", Password: "password123"},
+		},
+		{
+			name:        "missing password",
+			credentials: guard.Credentials{Username: "user1"},
+			expectedErr: errors.New("username and password are required"),
+		},
+	}
+
+	for _, tc := range testCases {
+		mt.Run(tc.name, func(mongoTest *mtest.T) {
+			mongoTest.AddMockResponses(mtest.CreateSuccessResponse())
+
+			repo := guard.NewManticore(*mongoTest.Coll, time.Second, "secret")
+
+			err := repo.AddOperator(context.TODO(), tc.credentials)
+			if tc.expectedErr != nil {
+				assert.EqualError(test, err, tc.expectedErr.Error())
+				return
+			}
+
+			assert.NoError(test, err)
+			update := mongoTest.GetStartedEvent().Command.Lookup("updates").Array().Index(0).Value().Document()
+			assert.Equal(test, tools.Sha512("secret", "password123"), update.Lookup("u", "password").StringValue())
+			assert.True(test, update.Lookup("upsert").Boolean())
+		})
+	}
+}
@@ -0,0 +1,152 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"project-wraith/pkg/modules/logger"
+	"sync"
+	"time"
+)
+
+// Check returns nil when the dependency is usable. Checks that cannot honor
+// ctx are still cut off at the checker timeout.
+type Check func(ctx context.Context) error
+
+const (
+	StatusUp   = "up"
+	StatusDown = "down"
+)
+
+type Result struct {
+	Status    string    `json:"status"`
+	LatencyMs int64     `json:"latencyMs"`
+	CheckedAt time.Time `json:"checkedAt"`
+}
+
+type Report struct {
+	Status string            `json:"status"`
+	Checks map[string]Result `json:"checks,omitempty"`
+}
+
+// Checker runs the readiness checks. Results are reused until they are ttl
+// old, and probes arriving while a check runs wait for it instead of starting
+// another, so probes cannot pile load on the dependencies.
+type Checker interface {
+	Add(name string, check Check)
+	Ready(ctx context.Context) Report
+}
+
+type entry struct {
+	check   Check
+	mu      sync.Mutex
+	result  Result
+	expires time.Time
+}
+
+type checker struct {
+	log     logger.Logger
+	ttl     time.Duration
+	timeout time.Duration
+
+	mu      sync.RWMutex
+	entries map[string]*entry
+}
+
+// NewChecker is a function constructor for Checker. Failed checks are
+// logged as warnings with their error, which the report leaves out.
+func NewChecker(log logger.Logger, ttl, timeout time.Duration) Checker {
+	return &checker{
+		log:     log,
+		ttl:     ttl,
+		timeout: timeout,
+		entries: make(map[string]*entry),
+	}
+}
+
+func (c *checker) Add(name string, check Check) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.entries[name] = &entry{check: check}
+}
+
+func (c *checker) Ready(ctx context.Context) Report {
+	c.mu.RLock()
+	entries := make(map[string]*entry, len(c.entries))
+	for name, e := range c.entries {
+		entries[name] = e
+	}
+	c.mu.RUnlock()
+
+	report := Report{Status: StatusUp, Checks: make(map[string]Result, len(entries))}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for name, e := range entries {
+		wg.Add(1)
+		go func(name string, e *entry) {
+			defer wg.Done()
+			result := c.result(ctx, name, e)
+
+			mu.Lock()
+			defer mu.Unlock()
+			report.Checks[name] = result
+			if result.Status != StatusUp {
+				report.Status = StatusDown
+			}
+		}(name, e)
+	}
+	wg.Wait()
+
+	return report
+}
+
+func (c *checker) result(ctx context.Context, name string, e *entry) Result {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if time.Now().Before(e.expires) {
+		return e.result
+	}
+
+	start := time.Now()
+	err := c.run(ctx, e.check)
+
+	e.result = Result{
+		Status:    StatusUp,
+		LatencyMs: time.Since(start).Milliseconds(),
+		CheckedAt: start.UTC(),
+	}
+	if err != nil {
+		e.result.Status = StatusDown
+		c.log.Warn("readiness check %s failed: %v", name, err)
+	}
+	e.expires = start.Add(c.ttl)
+
+	return e.result
+}
+
+// run gives check its own timeout, detached from the probe that triggered it
+// since the result is shared with the probes that follow.
+func (c *checker) run(ctx context.Context, check Check) error {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), c.timeout)
+	defer cancel()
+
+	done := make(chan error, 1)
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				done <- fmt.Errorf("check panicked: %v", r)
+			}
+		}()
+		done <- check(ctx)
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return errors.New("check timed out")
+	}
+}
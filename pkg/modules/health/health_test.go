@@ -0,0 +1,103 @@
+package health_test
+
+import (
+	"context"
+	"errors"
+	"project-wraith/pkg/modules/health"
+	"project-wraith/pkg/modules/logger"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestReady(test *testing.T) {
+	test.Parallel()
+
+	tests := []struct {
+		name           string
+		check          health.Check
+		expectedStatus string
+	}{
+		{
+			name:           "Test Ready - Up",
+			check:          func(ctx context.Context) error { return nil },
+			expectedStatus: health.StatusUp,
+		},
+		{
+			name:           "Test Ready - Failing",
+			check:          func(ctx context.Context) error { return errors.New("connection refused") },
+			expectedStatus: health.StatusDown,
+		},
+		{
+			name: "Test Ready - Timed out",
+			check: func(ctx context.Context) error {
+				time.Sleep(time.Second)
+				return nil
+			},
+			expectedStatus: health.StatusDown,
+		},
+		{
+			name:           "Test Ready - Panicked",
+			check:          func(ctx context.Context) error { panic("boom") },
+			expectedStatus: health.StatusDown,
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		test.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			logMock := &logger.MockLogger{}
+			logMock.On("Warn", mock.Anything).Return(nil)
+
+			checker := health.NewChecker(logMock, time.Minute, 50*time.Millisecond)
+			checker.Add("up", func(ctx context.Context) error { return nil })
+			checker.Add("tested", tc.check)
+
+			report := checker.Ready(context.Background())
+
+			assert.Equal(t, tc.expectedStatus, report.Status)
+			assert.Equal(t, tc.expectedStatus, report.Checks["tested"].Status)
+			assert.Equal(t, health.StatusUp, report.Checks["up"].Status)
+			assert.Less(t, report.Checks["tested"].LatencyMs, int64(500))
+		})
+	}
+}
+
+func TestReadyCache(test *testing.T) {
+	test.Parallel()
+
+	logMock := &logger.MockLogger{}
+	logMock.On("Warn", mock.Anything).Return(nil)
+
+	var calls atomic.Int32
+	checker := health.NewChecker(logMock, 100*time.Millisecond, time.Second)
+	checker.Add("slow", func(ctx context.Context) error {
+		calls.Add(1)
+		time.Sleep(20 * time.Millisecond)
+		return nil
+	})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			checker.Ready(context.Background())
+		}()
+	}
+	wg.Wait()
+	assert.Equal(test, int32(1), calls.Load(), "concurrent probes run the check once")
+
+	checker.Ready(context.Background())
+	assert.Equal(test, int32(1), calls.Load(), "cached result is reused")
+
+	time.Sleep(150 * time.Millisecond)
+	checker.Ready(context.Background())
+	assert.Equal(test, int32(2), calls.Load(), "expired result is refreshed")
+}
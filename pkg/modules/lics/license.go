@@ -3,8 +3,11 @@ package lics
 import (
 	"context"
 	"errors"
+	"fmt"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
+	"project-wraith/pkg/modules/db"
+	"project-wraith/pkg/modules/metrics"
 	"time"
 )
 
@@ -21,33 +24,55 @@ type License struct {
 	IsActive           bool      `json:"is_active" bson:"is_active"`
 }
 
+var ErrNotFound = errors.New("license not found")
+
 type LicenseRepository interface {
-	Get(lic License) (*License, error)
+	Get(ctx context.Context, lic License) (*License, error)
+	Issue(ctx context.Context, lic License) error
 }
 
 type licenseRepository struct {
 	collection *mongo.Collection
-	ctx        context.Context
+	timeout    time.Duration
 }
 
-func NewLicenseRepository(collection mongo.Collection, ctx context.Context) LicenseRepository {
+func NewLicenseRepository(collection mongo.Collection, timeout time.Duration) LicenseRepository {
 	return &licenseRepository{
 		collection: &collection,
-		ctx:        ctx,
+		timeout:    timeout,
 	}
 }
 
-func (r *licenseRepository) Get(lic License) (*License, error) {
+func (r *licenseRepository) Get(ctx context.Context, lic License) (*License, error) {
 	filter := bson.M{"license_key": lic.LicenseKey}
 
+	ctx, cancel := db.Operation(ctx, r.timeout)
+	defer cancel()
+
 	var result License
-	err := r.collection.FindOne(context.Background(), filter).Decode(&result)
+	done := metrics.Mongo(r.collection.Name(), "get")
+	err := r.collection.FindOne(ctx, filter).Decode(&result)
+	done(err)
 	if err != nil {
 		if errors.Is(err, mongo.ErrNoDocuments) {
-			return nil, errors.New("license not found")
+			return nil, ErrNotFound
 		}
-		return nil, err
+		return nil, db.Interrupted(err)
 	}
 
 	return &result, nil
 }
+
+func (r *licenseRepository) Issue(ctx context.Context, lic License) error {
+	ctx, cancel := db.Operation(ctx, r.timeout)
+	defer cancel()
+
+	done := metrics.Mongo(r.collection.Name(), "issue")
+	_, err := r.collection.InsertOne(ctx, lic)
+	done(err)
+	if err != nil {
+		return fmt.Errorf("failed to issue license: %w", db.Interrupted(err))
+	}
+
+	return nil
+}
@@ -1,13 +1,20 @@
 package lics
 
-import "github.com/stretchr/testify/mock"
+import (
+	"context"
+	"github.com/stretchr/testify/mock"
+)
 
 type MockLicenseRepository struct {
 	mock.Mock
 }
 
 // Get retrieves a license by its key from the mock repository.
-func (m *MockLicenseRepository) Get(lic License) (*License, error) {
-	args := m.Called(lic)
+func (m *MockLicenseRepository) Get(ctx context.Context, lic License) (*License, error) {
+	args := m.Called(ctx, lic)
 	return args.Get(0).(*License), args.Error(1)
 }
+
+func (m *MockLicenseRepository) Issue(ctx context.Context, lic License) error {
+	return m.Called(ctx, lic).Error(0)
+}
@@ -37,6 +37,16 @@ func TestLicenseRepository(test *testing.T) {
 			},
 			expectedErr: nil,
 		},
+		{
+			name:   "issue license",
+			action: "issue",
+			license: lics.License{
+				LicenseKey: "1234-5678-9101",
+				IsActive:   true,
+				ExpiryDate: time.Now().Add(24 * time.Hour).UTC(),
+			},
+			expectedErr: nil,
+		},
 	}
 
 	for _, tc := range testCases {
@@ -44,7 +54,7 @@ func TestLicenseRepository(test *testing.T) {
 			mongoTest.Parallel()
 
 			collection := mongoTest.Coll
-			repo := lics.NewLicenseRepository(*collection, context.TODO())
+			repo := lics.NewLicenseRepository(*collection, time.Second)
 
 			switch tc.action {
 			case "get":
@@ -60,10 +70,14 @@ func TestLicenseRepository(test *testing.T) {
 					{"is_active", tc.license.IsActive},
 					{"product_name", tc.license.ProductName},
 				}))
-				result, err := repo.Get(tc.query)
+				result, err := repo.Get(context.TODO(), tc.query)
 				assert.Equal(test, tc.expectedErr, err)
 				assert.IsType(test, &tc.license, result)
 				assert.Equal(test, tc.license.ID, result.ID)
+			case "issue":
+				mongoTest.AddMockResponses(mtest.CreateSuccessResponse())
+				err := repo.Issue(context.TODO(), tc.license)
+				assert.Equal(test, tc.expectedErr, err)
 			}
 		})
 	}
@@ -0,0 +1,150 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"project-wraith/pkg/modules/logger"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// Hook is one subsystem of the server. Start and Stop may be nil.
+type Hook struct {
+	Name  string
+	Start func(ctx context.Context) error
+	Stop  func(ctx context.Context) error
+}
+
+// Lifecycle starts hooks in the order they are given and stops them in the
+// reverse order, so a subsystem is stopped before the ones it was built on.
+type Lifecycle interface {
+	Start(ctx context.Context, hook Hook) error
+	Fail(err error)
+	Wait(ctx context.Context) error
+	Stop() error
+}
+
+type lifecycle struct {
+	log         logger.Logger
+	stopTimeout time.Duration
+	failed      chan error
+
+	mu      sync.Mutex
+	started []Hook
+}
+
+// NewLifecycle is a function constructor for Lifecycle. Stop gives all the
+// hooks stopTimeout to finish.
+func NewLifecycle(log logger.Logger, stopTimeout time.Duration) Lifecycle {
+	return &lifecycle{
+		log:         log,
+		stopTimeout: stopTimeout,
+		failed:      make(chan error, 1),
+	}
+}
+
+// Start runs hook.Start and, when it succeeds, remembers hook for Stop.
+func (l *lifecycle) Start(ctx context.Context, hook Hook) error {
+	if hook.Start != nil {
+		if err := hook.Start(ctx); err != nil {
+			return fmt.Errorf("failed to start %s: %w", hook.Name, err)
+		}
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.started = append(l.started, hook)
+	l.log.Debug("started %s", hook.Name)
+	return nil
+}
+
+// Fail makes Wait return err. It is meant for subsystems failing in the
+// background, like a server that cannot listen; only the first error is kept.
+func (l *lifecycle) Fail(err error) {
+	select {
+	case l.failed <- err:
+	default:
+	}
+}
+
+// Wait blocks until SIGINT or SIGTERM arrives, ctx is done or a hook fails.
+// Once the first signal is received a second one kills the process.
+func (l *lifecycle) Wait(ctx context.Context) error {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(signals)
+
+	select {
+	case sig := <-signals:
+		l.log.Info("received %s, shutting down", sig)
+		return nil
+	case <-ctx.Done():
+		l.log.Info("shutting down: %v", ctx.Err())
+		return nil
+	case err := <-l.failed:
+		l.log.Error("shutting down: %v", err)
+		return err
+	}
+}
+
+// Stop stops the started hooks in reverse order. Every hook is stopped even
+// when an earlier one fails or the timeout runs out; their errors are joined.
+func (l *lifecycle) Stop() error {
+	l.mu.Lock()
+	started := l.started
+	l.started = nil
+	l.mu.Unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), l.stopTimeout)
+	defer cancel()
+
+	var errs []error
+	for i := len(started) - 1; i >= 0; i-- {
+		hook := started[i]
+		if hook.Stop == nil {
+			continue
+		}
+
+		if err := hook.Stop(ctx); err != nil {
+			l.log.Error("failed to stop %s: %v", hook.Name, err)
+			errs = append(errs, fmt.Errorf("failed to stop %s: %w", hook.Name, err))
+			continue
+		}
+		l.log.Debug("stopped %s", hook.Name)
+	}
+
+	return errors.Join(errs...)
+}
+
+// Background is a hook for a loop that runs until its stop channel closes.
+// Stopping it waits for the loop to return, up to the stop deadline.
+func Background(name string, run func(stop <-chan struct{})) Hook {
+	stop := make(chan struct{})
+	done := make(chan struct{})
+
+	return Hook{
+		Name: name,
+		Start: func(ctx context.Context) error {
+			go func() {
+				defer close(done)
+				run(stop)
+			}()
+			return nil
+		},
+		Stop: func(ctx context.Context) error {
+			close(stop)
+
+			select {
+			case <-done:
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		},
+	}
+}
@@ -0,0 +1,162 @@
+package lifecycle_test
+
+import (
+	"context"
+	"errors"
+	"project-wraith/pkg/modules/lifecycle"
+	"project-wraith/pkg/modules/logger"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func newLog() *logger.MockLogger {
+	logMock := &logger.MockLogger{}
+	logMock.On("Debug", mock.Anything).Return(nil)
+	logMock.On("Info", mock.Anything).Return(nil)
+	logMock.On("Error", mock.Anything).Return(nil)
+	return logMock
+}
+
+func recorded(name string, calls *[]string, stopErr error) lifecycle.Hook {
+	return lifecycle.Hook{
+		Name: name,
+		Start: func(ctx context.Context) error {
+			*calls = append(*calls, "start "+name)
+			return nil
+		},
+		Stop: func(ctx context.Context) error {
+			*calls = append(*calls, "stop "+name)
+			return stopErr
+		},
+	}
+}
+
+func TestStartStop(test *testing.T) {
+	test.Parallel()
+
+	var calls []string
+	lc := lifecycle.NewLifecycle(newLog(), time.Second)
+
+	require.NoError(test, lc.Start(context.Background(), recorded("db", &calls, nil)))
+	require.NoError(test, lc.Start(context.Background(), recorded("teardown", &calls, errors.New("bucket gone"))))
+	require.NoError(test, lc.Start(context.Background(), lifecycle.Hook{Name: "no-op"}))
+	require.NoError(test, lc.Start(context.Background(), recorded("server", &calls, nil)))
+
+	err := lc.Start(context.Background(), lifecycle.Hook{
+		Name:  "broken",
+		Start: func(ctx context.Context) error { return errors.New("refused") },
+		Stop: func(ctx context.Context) error {
+			calls = append(calls, "stop broken")
+			return nil
+		},
+	})
+	assert.ErrorContains(test, err, "failed to start broken: refused")
+
+	err = lc.Stop()
+	assert.ErrorContains(test, err, "failed to stop teardown: bucket gone")
+	assert.Equal(test, []string{
+		"start db", "start teardown", "start server",
+		"stop server", "stop teardown", "stop db",
+	}, calls)
+
+	assert.NoError(test, lc.Stop(), "hooks are stopped once")
+}
+
+func TestWait(test *testing.T) {
+	tests := []struct {
+		name          string
+		trigger       func(lc lifecycle.Lifecycle, cancel context.CancelFunc)
+		expectedError string
+	}{
+		{
+			name: "Test Wait - SIGTERM",
+			trigger: func(lc lifecycle.Lifecycle, cancel context.CancelFunc) {
+				_ = syscall.Kill(syscall.Getpid(), syscall.SIGTERM)
+			},
+		},
+		{
+			name: "Test Wait - Context done",
+			trigger: func(lc lifecycle.Lifecycle, cancel context.CancelFunc) {
+				cancel()
+			},
+		},
+		{
+			name: "Test Wait - Failed",
+			trigger: func(lc lifecycle.Lifecycle, cancel context.CancelFunc) {
+				lc.Fail(errors.New("address already in use"))
+				lc.Fail(errors.New("ignored"))
+			},
+			expectedError: "address already in use",
+		},
+	}
+
+	for _, tc := range tests {
+		test.Run(tc.name, func(t *testing.T) {
+			lc := lifecycle.NewLifecycle(newLog(), time.Second)
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			done := make(chan error, 1)
+			go func() { done <- lc.Wait(ctx) }()
+			time.Sleep(20 * time.Millisecond)
+			tc.trigger(lc, cancel)
+
+			select {
+			case err := <-done:
+				if tc.expectedError != "" {
+					assert.EqualError(t, err, tc.expectedError)
+				} else {
+					assert.NoError(t, err)
+				}
+			case <-time.After(time.Second):
+				t.Fatal("Wait did not return")
+			}
+		})
+	}
+}
+
+func TestBackground(test *testing.T) {
+	test.Parallel()
+
+	tests := []struct {
+		name          string
+		run           func(stop <-chan struct{})
+		expectedError error
+	}{
+		{
+			name:          "Test Background - Returns on stop",
+			run:           func(stop <-chan struct{}) { <-stop },
+			expectedError: nil,
+		},
+		{
+			name: "Test Background - Stuck",
+			run: func(stop <-chan struct{}) {
+				<-stop
+				time.Sleep(time.Second)
+			},
+			expectedError: context.DeadlineExceeded,
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		test.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			lc := lifecycle.NewLifecycle(newLog(), 50*time.Millisecond)
+			require.NoError(t, lc.Start(context.Background(), lifecycle.Background("loop", tc.run)))
+
+			err := lc.Stop()
+			if tc.expectedError != nil {
+				assert.ErrorIs(t, err, tc.expectedError)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
@@ -4,3 +4,11 @@ type Response struct {
 	Message string      `json:"message,omitempty"`
 	Content interface{} `json:"content,omitempty"`
 }
+
+// Envelope wraps an encrypted JSON body. Alg names the scheme, Kid the key
+// that was used and Payload holds the sealed original body.
+type Envelope struct {
+	Alg     string `json:"alg"`
+	Kid     string `json:"kid"`
+	Payload string `json:"payload"`
+}
@@ -0,0 +1,21 @@
+package logger
+
+import "context"
+
+type contextKey struct{}
+
+// WithContext stores a request scoped logger in ctx.
+func WithContext(ctx context.Context, log Logger) context.Context {
+	return context.WithValue(ctx, contextKey{}, log)
+}
+
+// FromContext returns the logger stored by WithContext, or fallback.
+func FromContext(ctx context.Context, fallback Logger) Logger {
+	if ctx != nil {
+		if log, ok := ctx.Value(contextKey{}).(Logger); ok {
+			return log
+		}
+	}
+
+	return fallback
+}
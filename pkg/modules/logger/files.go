@@ -1,28 +1,66 @@
 package logger
 
 import (
-	"bufio"
+	"compress/gzip"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"io/fs"
 	"log"
 	"os"
+	"path/filepath"
+	"strings"
 )
 
-func ReadFile(filePath string) ([]map[string]interface{}, error) {
+// ReadFile parses the entries of a log file, gzipped or not. Lines sealed at
+// rest are opened with secret; lines that cannot be read are skipped.
+func ReadFile(filePath, secret string) ([]map[string]interface{}, error) {
 	file, err := os.Open(filePath)
 	if err != nil {
-		return nil, fmt.Errorf("error opening file: %v", err)
+		return nil, fmt.Errorf("error opening file: %w", err)
 	}
+	defer file.Close()
 
-	scanner := bufio.NewScanner(file)
+	var reader io.Reader = file
+	if strings.HasSuffix(filePath, gzipExt) {
+		gz, err := gzip.NewReader(file)
+		if err != nil {
+			return nil, fmt.Errorf("error opening file: %v", err)
+		}
+		defer gz.Close()
+		reader = gz
+	}
+
+	return parseLines(reader, secret)
+}
+
+// parseLines parses one JSON entry per line, skipping the lines that cannot
+// be read.
+func parseLines(reader io.Reader, secret string) ([]map[string]interface{}, error) {
+	lines, err := newLineReader(reader, secret)
+	if err != nil {
+		return nil, err
+	}
 
 	var logs []map[string]interface{} // Slice to hold the parsed log entries
-	for scanner.Scan() {
-		line := scanner.Text()
+	for {
+		line, ok, err := lines.next()
+		if !ok {
+			if err != nil {
+				return nil, fmt.Errorf("error reading file: %v", err)
+			}
+			break
+		}
+		if err != nil {
+			log.Printf("Error opening line: %v", err) // Log the error and continue
+			continue
+		}
+
 		var logEntry map[string]interface{}
 
 		// Unmarshal JSON into map
-		err := json.Unmarshal([]byte(line), &logEntry)
+		err = json.Unmarshal(line, &logEntry)
 		if err != nil {
 			log.Printf("Error parsing JSON: %v", err) // Log the error and continue
 			continue
@@ -31,14 +69,35 @@ func ReadFile(filePath string) ([]map[string]interface{}, error) {
 		logs = append(logs, logEntry)
 	}
 
-	if err := scanner.Err(); err != nil {
-		return nil, fmt.Errorf("error reading file: %v", err)
-	}
+	return logs, nil
+}
 
-	err = file.Close()
+// ReadLevel returns up to limit of the latest entries of a level, oldest
+// first, going back from the active file through its rotated segments.
+func ReadLevel(folder, level string, limit int, secret string) ([]map[string]interface{}, error) {
+	segments, err := Segments(folder, level)
 	if err != nil {
 		return nil, err
 	}
 
+	files := append(segments, filepath.Join(folder, level+logExt))
+
+	var logs []map[string]interface{}
+	for i := len(files) - 1; i >= 0 && len(logs) < limit; i-- {
+		entries, err := ReadFile(files[i], secret)
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				continue
+			}
+			return nil, err
+		}
+
+		logs = append(entries, logs...)
+	}
+
+	if len(logs) > limit {
+		logs = logs[len(logs)-limit:]
+	}
+
 	return logs, nil
 }
@@ -1 +1,56 @@
 package logger_test
+
+import (
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"project-wraith/pkg/modules/logger"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadLevel(t *testing.T) {
+	folder := t.TempDir()
+
+	require.NoError(t, os.WriteFile(filepath.Join(folder, "info-20261018T000000.000Z.log"),
+		[]byte(`{"message":"one"}`+"\n"+`{"message":"two"}`+"\n"), 0644))
+
+	file, err := os.Create(filepath.Join(folder, "info-20261019T000000.000Z.log.gz"))
+	require.NoError(t, err)
+	gz := gzip.NewWriter(file)
+	_, err = gz.Write([]byte(`{"message":"three"}` + "\n"))
+	require.NoError(t, err)
+	require.NoError(t, gz.Close())
+	require.NoError(t, file.Close())
+
+	require.NoError(t, os.WriteFile(filepath.Join(folder, "info.log"),
+		[]byte(`{"message":"four"}`+"\n"+"not json\n"), 0644))
+
+	tests := []struct {
+		name             string
+		level            string
+		limit            int
+		expectedMessages []string
+	}{
+		{name: "Active file only", level: "info", limit: 1, expectedMessages: []string{"four"}},
+		{name: "Into a compressed segment", level: "info", limit: 2, expectedMessages: []string{"three", "four"}},
+		{name: "Trimmed to the limit", level: "info", limit: 3, expectedMessages: []string{"two", "three", "four"}},
+		{name: "Everything", level: "info", limit: 100, expectedMessages: []string{"one", "two", "three", "four"}},
+		{name: "Missing level", level: "warn", limit: 10, expectedMessages: nil},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			entries, err := logger.ReadLevel(folder, tc.level, tc.limit, "")
+			require.NoError(t, err)
+
+			var messages []string
+			for _, entry := range entries {
+				messages = append(messages, entry["message"].(string))
+			}
+			assert.Equal(t, tc.expectedMessages, messages)
+		})
+	}
+}
@@ -0,0 +1,84 @@
+package logger
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// Event is what a hook receives for an entry, after redaction. Fields holds
+// those added with With and those of the call itself.
+type Event struct {
+	Time    time.Time
+	Level   string
+	Caller  string
+	Message string
+	Fields  map[string]interface{}
+}
+
+// Hook is called on the logging goroutine once an entry is written, so it
+// must return quickly and must not log at a level it is hooked to.
+type Hook func(event Event)
+
+type leveledHook struct {
+	level zapcore.Level
+	hook  Hook
+}
+
+// hooks is shared by a logger and all of its children.
+type hooks struct {
+	mu      sync.RWMutex
+	entries []leveledHook
+}
+
+func (h *hooks) add(level zapcore.Level, hook Hook) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.entries = append(h.entries, leveledHook{level: level, hook: hook})
+}
+
+func (h *hooks) enabled(level zapcore.Level) bool {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	for _, entry := range h.entries {
+		if level >= entry.level {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (h *hooks) fire(level zapcore.Level, event Event) {
+	h.mu.RLock()
+	entries := h.entries
+	h.mu.RUnlock()
+
+	for _, entry := range entries {
+		if level >= entry.level {
+			entry.hook(event)
+		}
+	}
+}
+
+func newEvent(level zapcore.Level, caller, message string, fieldSets ...[]interface{}) Event {
+	event := Event{
+		Time:    time.Now().UTC(),
+		Level:   level.String(),
+		Caller:  caller,
+		Message: message,
+		Fields:  make(map[string]interface{}),
+	}
+
+	for _, fields := range fieldSets {
+		for i := 0; i+1 < len(fields); i += 2 {
+			event.Fields[fmt.Sprint(fields[i])] = fields[i+1]
+		}
+	}
+
+	return event
+}
@@ -4,10 +4,8 @@ import (
 	"errors"
 	"fmt"
 	"os"
-	"path/filepath"
 	"project-wraith/pkg/consts"
 	"project-wraith/pkg/modules/tools"
-	"strings"
 
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
@@ -15,23 +13,66 @@ import (
 
 type Logger interface {
 	Initialize() error
+	Debug(message string, args ...interface{})
 	Warn(message string, args ...interface{})
 	Info(message string, args ...interface{})
 	Error(message string, args ...interface{})
+	// Debugw, Infow, Warnw and Errorw take alternating keys and values.
+	Debugw(message string, keysAndValues ...interface{})
+	Infow(message string, keysAndValues ...interface{})
+	Warnw(message string, keysAndValues ...interface{})
+	Errorw(message string, keysAndValues ...interface{})
+	// With returns a child logger adding the key/value fields to every entry.
+	With(fields ...interface{}) Logger
+	// Rotate moves every non empty level file aside as a segment.
+	Rotate() error
+	// Files names the level files written in the logs folder.
+	Files() []string
+	// AddHook calls hook for every entry at level or above, on this logger
+	// and all of its children.
+	AddHook(level string, hook Hook) error
+	// SetLevel drops the entries below level, on this logger and all of its
+	// children.
+	SetLevel(level string) error
 }
 
 var _ Logger = (*logger)(nil)
 
+// levels are the levels with a file of their own, <level>.log.
+var levels = []zapcore.Level{zap.DebugLevel, zap.WarnLevel, zap.InfoLevel, zap.ErrorLevel}
+
 type logger struct {
 	loggers     map[zapcore.Level]*zap.SugaredLogger
+	rotators    map[zapcore.Level]*rotator
+	redactor    *redactor
+	hooks       *hooks
+	level       zap.AtomicLevel
+	fields      []interface{}
 	projectPath string
+	rotation    Rotation
+	encryptKey  string
 }
 
-// NewLogger is a function constructor for Logger
-func NewLogger(projectPath string) Logger {
+// NewLogger is a function constructor for Logger. Debug entries are only
+// written, to debug.log, when debug is set or SetLevel lowers the level.
+// Messages and fields are masked according to redaction before they are
+// encoded. With an encryptKey every line of the log files is sealed; stdout
+// stays plain.
+func NewLogger(projectPath string, debug bool, rotation Rotation, redaction Redaction, encryptKey string) Logger {
+	level := zap.NewAtomicLevelAt(zap.InfoLevel)
+	if debug {
+		level.SetLevel(zap.DebugLevel)
+	}
+
 	return &logger{
 		loggers:     make(map[zapcore.Level]*zap.SugaredLogger),
+		rotators:    make(map[zapcore.Level]*rotator),
+		redactor:    newRedactor(redaction),
+		hooks:       &hooks{},
+		level:       level,
 		projectPath: projectPath,
+		rotation:    rotation,
+		encryptKey:  encryptKey,
 	}
 }
 
@@ -41,61 +82,156 @@ func (l logger) Initialize() error {
 	encoderConfig.MessageKey = "message"
 	encoderConfig.LevelKey = "severity"
 
-	for _, level := range []zapcore.Level{zap.WarnLevel, zap.InfoLevel, zap.ErrorLevel} {
-		logFilePath, err := l.getLogFilePath(level.String())
+	if err := os.MkdirAll(l.projectPath, os.ModePerm); err != nil {
+		return fmt.Errorf("failed to create logs directory: %w", err)
+	}
+
+	encoder := zapcore.NewJSONEncoder(encoderConfig)
+	stdout := zapcore.Lock(os.Stdout)
+
+	var sealer *lineSealer
+	if l.encryptKey != "" {
+		var err error
+		sealer, err = newLineSealer(l.encryptKey)
 		if err != nil {
 			return err
 		}
+	}
 
-		cfg := zap.Config{
-			Level:             zap.NewAtomicLevelAt(level),
-			Development:       false,
-			DisableCaller:     true,
-			DisableStacktrace: true,
-			Encoding:          "json",
-			EncoderConfig:     encoderConfig,
-			OutputPaths:       []string{"stdout", logFilePath},
-			ErrorOutputPaths:  []string{"stderr"},
-		}
-
-		zl, err := cfg.Build()
+	for _, level := range levels {
+		file, err := newRotator(l.projectPath, level.String(), l.rotation)
 		if err != nil {
 			return err
 		}
 
-		l.loggers[level] = zl.Sugar()
+		var sink zapcore.WriteSyncer = file
+		if sealer != nil {
+			sink = sealedSink{next: file, sealer: sealer}
+		}
+
+		core := zapcore.NewCore(
+			encoder,
+			zapcore.NewMultiWriteSyncer(stdout, sink),
+			zap.NewAtomicLevelAt(level),
+		)
+
+		l.rotators[level] = file
+		l.loggers[level] = zap.New(core, zap.ErrorOutput(zapcore.Lock(os.Stderr))).Sugar()
 	}
 
-	if len(l.loggers) < 3 {
+	if len(l.loggers) < len(levels) {
 		return errors.New("no loggers configured")
 	}
 
 	return nil
 }
 
+func (l logger) Debug(message string, args ...interface{}) {
+	l.write(zapcore.DebugLevel, fmt.Sprintf(message, args...))
+}
+
 func (l logger) Warn(message string, args ...interface{}) {
-	formattedMessage := fmt.Sprintf(strings.ToLower(message), args...)
-	callerInfo := tools.ExtractCallerInfo(consts.LoggerCallerLevel)
-	l.loggers[zapcore.WarnLevel].Warnw(formattedMessage, "caller", callerInfo)
+	l.write(zapcore.WarnLevel, fmt.Sprintf(message, args...))
 }
 
 func (l logger) Info(message string, args ...interface{}) {
-	formattedMessage := fmt.Sprintf(strings.ToLower(message), args...)
-	callerInfo := tools.ExtractCallerInfo(consts.LoggerCallerLevel)
-	l.loggers[zapcore.InfoLevel].Infow(formattedMessage, "caller", callerInfo)
+	l.write(zapcore.InfoLevel, fmt.Sprintf(message, args...))
 }
 
 func (l logger) Error(message string, args ...interface{}) {
-	formattedMessage := fmt.Sprintf(strings.ToLower(message), args...)
-	callerInfo := tools.ExtractCallerInfo(consts.LoggerCallerLevel)
+	l.write(zapcore.ErrorLevel, fmt.Sprintf(message, args...))
+}
 
-	l.loggers[zapcore.ErrorLevel].Errorw(formattedMessage, "caller", callerInfo)
+func (l logger) Debugw(message string, keysAndValues ...interface{}) {
+	l.write(zapcore.DebugLevel, message, keysAndValues...)
 }
 
-func (l logger) getLogFilePath(level string) (string, error) {
-	if err := os.MkdirAll(l.projectPath, os.ModePerm); err != nil {
-		return "", fmt.Errorf("failed to create logs directory %e", err)
+func (l logger) Infow(message string, keysAndValues ...interface{}) {
+	l.write(zapcore.InfoLevel, message, keysAndValues...)
+}
+
+func (l logger) Warnw(message string, keysAndValues ...interface{}) {
+	l.write(zapcore.WarnLevel, message, keysAndValues...)
+}
+
+func (l logger) Errorw(message string, keysAndValues ...interface{}) {
+	l.write(zapcore.ErrorLevel, message, keysAndValues...)
+}
+
+func (l logger) With(fields ...interface{}) Logger {
+	child := l
+	child.loggers = make(map[zapcore.Level]*zap.SugaredLogger, len(l.loggers))
+	masked := l.redactor.pairs(fields)
+	child.fields = append(append([]interface{}{}, l.fields...), masked...)
+	for level, sugared := range l.loggers {
+		child.loggers[level] = sugared.With(masked...)
 	}
 
-	return filepath.Join(l.projectPath, fmt.Sprintf("%s.log", level)), nil
+	return child
+}
+
+func (l logger) Rotate() error {
+	var errs []error
+	for _, file := range l.rotators {
+		errs = append(errs, file.Rotate())
+	}
+
+	return errors.Join(errs...)
+}
+
+func (l logger) Files() []string {
+	files := levelNames()
+	for i := range files {
+		files[i] += logExt
+	}
+
+	return files
+}
+
+// levelNames names the levels with a file of their own.
+func levelNames() []string {
+	names := make([]string, 0, len(levels))
+	for _, level := range levels {
+		names = append(names, level.String())
+	}
+
+	return names
+}
+
+func (l logger) AddHook(level string, hook Hook) error {
+	parsed, err := zapcore.ParseLevel(level)
+	if err != nil {
+		return err
+	}
+
+	l.hooks.add(parsed, hook)
+	return nil
+}
+
+func (l logger) SetLevel(level string) error {
+	parsed, err := zapcore.ParseLevel(level)
+	if err != nil {
+		return err
+	}
+
+	l.level.SetLevel(parsed)
+	return nil
+}
+
+// write is called from the public methods, one frame deeper than the code
+// being logged.
+func (l logger) write(level zapcore.Level, message string, keysAndValues ...interface{}) {
+	sugared, ok := l.loggers[level]
+	if !ok || !l.level.Enabled(level) {
+		return
+	}
+
+	callerInfo := tools.ExtractCallerInfo(consts.LoggerCallerLevel + 1)
+	masked := l.redactor.pairs(keysAndValues)
+	message = l.redactor.text(message)
+	sugared.Logw(level, message, append(masked, "caller", callerInfo)...)
+
+	if l.hooks.enabled(level) {
+		l.hooks.fire(level, newEvent(level, callerInfo, message, l.fields, masked))
+	}
 }
@@ -22,3 +22,48 @@ func (m *MockLogger) Warn(msg string, args ...interface{}) {
 func (m *MockLogger) Error(msg string, args ...interface{}) {
 	m.Called(msg)
 }
+
+func (m *MockLogger) Rotate() error {
+	args := m.Called()
+	return args.Error(0)
+}
+
+func (m *MockLogger) Files() []string {
+	args := m.Called()
+	return args.Get(0).([]string)
+}
+
+func (m *MockLogger) Debug(msg string, args ...interface{}) {
+	m.Called(msg)
+}
+
+func (m *MockLogger) Debugw(msg string, keysAndValues ...interface{}) {
+	m.Called(msg)
+}
+
+func (m *MockLogger) Infow(msg string, keysAndValues ...interface{}) {
+	m.Called(msg)
+}
+
+func (m *MockLogger) Warnw(msg string, keysAndValues ...interface{}) {
+	m.Called(msg)
+}
+
+func (m *MockLogger) Errorw(msg string, keysAndValues ...interface{}) {
+	m.Called(msg)
+}
+
+func (m *MockLogger) AddHook(level string, hook Hook) error {
+	args := m.Called(level, hook)
+	return args.Error(0)
+}
+
+func (m *MockLogger) SetLevel(level string) error {
+	args := m.Called(level)
+	return args.Error(0)
+}
+
+// With returns the mock itself, so expectations hold for child loggers.
+func (m *MockLogger) With(fields ...interface{}) Logger {
+	return m
+}
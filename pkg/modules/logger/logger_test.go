@@ -5,6 +5,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"os"
+	"path/filepath"
 	"testing"
 )
 
@@ -38,7 +39,7 @@ func TestLogger(t *testing.T) {
 				}(tt.projectPath)
 			}
 
-			l := NewLogger(tt.projectPath)
+			l := NewLogger(tt.projectPath, false, Rotation{}, Redaction{}, "")
 			err := l.Initialize()
 
 			if tt.expectedError != nil {
@@ -47,7 +48,111 @@ func TestLogger(t *testing.T) {
 			} else {
 				assert.NoError(t, err)
 				assert.NotNil(t, l)
+				assert.Equal(t, []string{"debug.log", "warn.log", "info.log", "error.log"}, l.Files())
+				for _, file := range l.Files() {
+					assert.FileExists(t, filepath.Join(tt.projectPath, file))
+				}
 			}
 		})
 	}
 }
+
+func TestLoggerFields(t *testing.T) {
+	tests := []struct {
+		name          string
+		debug         bool
+		expectedDebug bool
+	}{
+		{name: "Debug disabled", debug: false},
+		{name: "Debug enabled", debug: true, expectedDebug: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			folder := t.TempDir()
+
+			l := NewLogger(folder, tt.debug, Rotation{}, Redaction{}, "")
+			require.NoError(t, l.Initialize())
+
+			child := l.With("requestId", "req-1")
+			child.Infow("User Registered", "route", "/user/register")
+			child.Debug("Cache %s", "Miss")
+			l.Info("Without Fields")
+
+			entries, err := ReadFile(filepath.Join(folder, "info.log"), "")
+			require.NoError(t, err)
+			require.Len(t, entries, 2)
+
+			assert.Equal(t, "User Registered", entries[0]["message"])
+			assert.Equal(t, "req-1", entries[0]["requestId"])
+			assert.Equal(t, "/user/register", entries[0]["route"])
+			assert.Contains(t, entries[0]["caller"], "logger_test.go")
+			assert.NotContains(t, entries[1], "requestId")
+
+			debugEntries, err := ReadFile(filepath.Join(folder, "debug.log"), "")
+			require.NoError(t, err)
+			if !tt.expectedDebug {
+				assert.Empty(t, debugEntries)
+				return
+			}
+
+			require.Len(t, debugEntries, 1)
+			assert.Equal(t, "Cache Miss", debugEntries[0]["message"])
+			assert.Equal(t, "req-1", debugEntries[0]["requestId"])
+		})
+	}
+}
+
+func TestLoggerHooks(t *testing.T) {
+	l := NewLogger(t.TempDir(), false, Rotation{}, Redaction{}, "")
+	require.NoError(t, l.Initialize())
+
+	var events []Event
+	require.NoError(t, l.AddHook("warn", func(event Event) {
+		events = append(events, event)
+	}))
+	assert.Error(t, l.AddHook("loud", func(Event) {}))
+
+	child := l.With("requestId", "r1")
+	child.Info("not hooked")
+	child.Warn("hooked %d", 1)
+	child.Errorw("also hooked", "password", "hunter2", "id", 7)
+
+	require.Len(t, events, 2)
+	assert.Equal(t, "warn", events[0].Level)
+	assert.Equal(t, "hooked 1", events[0].Message)
+	assert.Equal(t, "r1", events[0].Fields["requestId"])
+	assert.Contains(t, events[0].Caller, "logger_test.go")
+
+	assert.Equal(t, "error", events[1].Level)
+	assert.Equal(t, map[string]interface{}{"requestId": "r1", "password": redacted, "id": 7}, events[1].Fields)
+}
+
+func TestLoggerLevel(t *testing.T) {
+	folder := t.TempDir()
+	l := NewLogger(folder, false, Rotation{}, Redaction{}, "")
+	require.NoError(t, l.Initialize())
+
+	var events []Event
+	require.NoError(t, l.AddHook("debug", func(event Event) {
+		events = append(events, event)
+	}))
+
+	child := l.With("requestId", "r1")
+	child.Debug("dropped")
+	require.NoError(t, l.SetLevel("debug"))
+	child.Debug("written")
+	require.NoError(t, l.SetLevel("error"))
+	child.Warn("dropped")
+	child.Error("written")
+	assert.Error(t, l.SetLevel("loud"))
+
+	require.Len(t, events, 2)
+	assert.Equal(t, "debug", events[0].Level)
+	assert.Equal(t, "error", events[1].Level)
+
+	debugLog, err := os.ReadFile(filepath.Join(folder, "debug.log"))
+	require.NoError(t, err)
+	assert.Contains(t, string(debugLog), "written")
+	assert.NotContains(t, string(debugLog), "dropped")
+}
@@ -0,0 +1,348 @@
+package logger
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// TimeLayout is how the encoder writes the ts field.
+	TimeLayout      = "2006-01-02T15:04:05.000Z0700"
+	DefaultPageSize = 100
+)
+
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// Query filters log entries. Empty fields match everything; Caller and Text
+// match substrings, ignoring case.
+type Query struct {
+	Levels    []string
+	From      time.Time
+	To        time.Time
+	Caller    string
+	Text      string
+	RequestID string
+	Cursor    string
+	Limit     int
+}
+
+// Page holds entries newest first. Next is the cursor of the following,
+// older, page; it is empty on the last one.
+type Page struct {
+	Entries []map[string]interface{} `json:"entries"`
+	Next    string                   `json:"next,omitempty"`
+}
+
+type cursor struct {
+	ts   time.Time
+	skip int
+}
+
+type found struct {
+	entry map[string]interface{}
+	ts    time.Time
+	level string
+	order int
+}
+
+// Search returns a page of the entries matching query, across the active
+// level files and their rotated segments. Without query levels every level
+// file the logger writes is searched, debug.log included. Cursors are time based, so they
+// stay valid while files rotate.
+func Search(folder, secret string, query Query) (*Page, error) {
+	after, err := decodeCursor(query.Cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	levels := query.Levels
+	if len(levels) == 0 {
+		levels = levelNames()
+	}
+
+	if query.Limit <= 0 {
+		query.Limit = DefaultPageSize
+	}
+
+	// One more than a page tells whether another one follows.
+	want := query.Limit + 1
+
+	var candidates []found
+	for _, level := range levels {
+		matches, err := searchLevel(folder, secret, level, query, after, want)
+		if err != nil {
+			return nil, err
+		}
+		candidates = append(candidates, matches...)
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return before(candidates[j], candidates[i])
+	})
+
+	if after != nil {
+		skipped := 0
+		for len(candidates) > 0 && candidates[0].ts.Equal(after.ts) && skipped < after.skip {
+			candidates = candidates[1:]
+			skipped++
+		}
+	}
+
+	page := &Page{Entries: []map[string]interface{}{}}
+	for i := 0; i < len(candidates) && i < query.Limit; i++ {
+		page.Entries = append(page.Entries, candidates[i].entry)
+	}
+
+	if len(candidates) > query.Limit && len(page.Entries) > 0 {
+		last := candidates[len(page.Entries)-1].ts
+		skip := 0
+		for i := len(page.Entries) - 1; i >= 0 && candidates[i].ts.Equal(last); i-- {
+			skip++
+		}
+		if after != nil && after.ts.Equal(last) {
+			skip += after.skip
+		}
+		page.Next = encodeCursor(cursor{ts: last, skip: skip})
+	}
+
+	return page, nil
+}
+
+// searchLevel walks the files of a level newest first and stops once it
+// holds want matches older than the next file can contain.
+func searchLevel(folder, secret, level string, query Query, after *cursor, want int) ([]found, error) {
+	segments, err := Segments(folder, level)
+	if err != nil {
+		return nil, err
+	}
+
+	files := append(segments, filepath.Join(folder, level+logExt))
+
+	var matches []found
+	for i := len(files) - 1; i >= 0; i-- {
+		if i < len(files)-1 {
+			// A segment only holds entries up to its rotation time.
+			rotatedAt, _ := SegmentTime(files[i])
+			if !query.From.IsZero() && rotatedAt.Before(query.From) {
+				break
+			}
+			if len(matches) >= want+skipOf(after) && rotatedAt.Before(matches[len(matches)-1].ts) {
+				break
+			}
+		}
+
+		entries, err := ReadFile(files[i], secret)
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				continue
+			}
+			return nil, err
+		}
+
+		for j := len(entries) - 1; j >= 0; j-- {
+			ts, ok := entryTime(entries[j])
+			if !ok || !query.matches(entries[j], ts) {
+				continue
+			}
+			if after != nil && ts.After(after.ts) {
+				continue
+			}
+
+			matches = append(matches, found{entry: entries[j], ts: ts, level: level, order: len(matches)})
+		}
+	}
+
+	return matches, nil
+}
+
+func (q Query) matches(entry map[string]interface{}, ts time.Time) bool {
+	if !q.From.IsZero() && ts.Before(q.From) {
+		return false
+	}
+	if !q.To.IsZero() && ts.After(q.To) {
+		return false
+	}
+	if q.RequestID != "" && fmt.Sprint(entry["requestId"]) != q.RequestID {
+		return false
+	}
+	if q.Caller != "" && !containsFold(fmt.Sprint(entry["caller"]), q.Caller) {
+		return false
+	}
+	if q.Text != "" && !containsFold(fmt.Sprint(entry["message"]), q.Text) {
+		return false
+	}
+
+	return true
+}
+
+// Follow calls emit with every entry appended to the level files from now
+// on, checking them every interval, until ctx is done or emit fails. Without
+// query levels every level file is followed. A file
+// that shrinks was rotated and is read again from the start.
+func Follow(ctx context.Context, folder, secret string, query Query, interval time.Duration, emit func(map[string]interface{}) error) error {
+	levels := query.Levels
+	if len(levels) == 0 {
+		levels = levelNames()
+	}
+
+	offsets := make(map[string]int64, len(levels))
+	for _, level := range levels {
+		info, err := os.Stat(filepath.Join(folder, level+logExt))
+		if err == nil {
+			offsets[level] = info.Size()
+		}
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+
+		for _, level := range levels {
+			entries, offset, err := readFrom(filepath.Join(folder, level+logExt), secret, offsets[level])
+			if err != nil {
+				return err
+			}
+			offsets[level] = offset
+
+			for _, entry := range entries {
+				ts, _ := entryTime(entry)
+				if !query.matches(entry, ts) {
+					continue
+				}
+				if err := emit(entry); err != nil {
+					return err
+				}
+			}
+		}
+	}
+}
+
+// readFrom parses the complete lines written to filePath past offset and
+// returns the offset to continue from.
+func readFrom(filePath, secret string, offset int64) ([]map[string]interface{}, int64, error) {
+	file, err := os.Open(filePath)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, 0, nil
+	}
+	if err != nil {
+		return nil, offset, err
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return nil, offset, err
+	}
+	if info.Size() < offset {
+		offset = 0
+	}
+
+	if _, err := file.Seek(offset, io.SeekStart); err != nil {
+		return nil, offset, err
+	}
+
+	chunk, err := io.ReadAll(io.LimitReader(file, info.Size()-offset))
+	if err != nil {
+		return nil, offset, err
+	}
+
+	complete := strings.LastIndexByte(string(chunk), '\n') + 1
+	if complete == 0 {
+		return nil, offset, nil
+	}
+
+	entries, err := parseLines(strings.NewReader(string(chunk[:complete])), secret)
+	if err != nil {
+		return nil, offset, err
+	}
+
+	return entries, offset + int64(complete), nil
+}
+
+func entryTime(entry map[string]interface{}) (time.Time, bool) {
+	raw, ok := entry["ts"].(string)
+	if !ok {
+		return time.Time{}, false
+	}
+
+	ts, err := time.Parse(TimeLayout, raw)
+	if err != nil {
+		return time.Time{}, false
+	}
+
+	return ts, true
+}
+
+// before orders found entries by time, then level and position, so pages
+// are stable.
+func before(a, b found) bool {
+	if !a.ts.Equal(b.ts) {
+		return a.ts.Before(b.ts)
+	}
+	if a.level != b.level {
+		return a.level > b.level
+	}
+
+	return a.order > b.order
+}
+
+func skipOf(after *cursor) int {
+	if after == nil {
+		return 0
+	}
+
+	return after.skip
+}
+
+func encodeCursor(c cursor) string {
+	raw := c.ts.UTC().Format(time.RFC3339Nano) + "|" + strconv.Itoa(c.skip)
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func decodeCursor(value string) (*cursor, error) {
+	if value == "" {
+		return nil, nil
+	}
+
+	raw, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	stamp, skip, ok := strings.Cut(string(raw), "|")
+	if !ok {
+		return nil, ErrInvalidCursor
+	}
+
+	ts, err := time.Parse(time.RFC3339Nano, stamp)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	n, err := strconv.Atoi(skip)
+	if err != nil || n < 0 {
+		return nil, ErrInvalidCursor
+	}
+
+	return &cursor{ts: ts, skip: n}, nil
+}
+
+func containsFold(s, substr string) bool {
+	return strings.Contains(strings.ToLower(s), strings.ToLower(substr))
+}
@@ -0,0 +1,172 @@
+package logger_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"project-wraith/pkg/modules/logger"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSearch(t *testing.T) {
+	folder := t.TempDir()
+
+	require.NoError(t, os.WriteFile(filepath.Join(folder, "info-20261019T100000.000Z.log"), []byte(
+		`{"ts":"2026-10-19T09:00:00.000Z","caller":"gateway/user.go:40","message":"one"}`+"\n"+
+			`{"ts":"2026-10-19T09:30:00.000Z","caller":"gateway/auth.go:12","message":"two"}`+"\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(folder, "info.log"), []byte(
+		`{"ts":"2026-10-19T10:30:00.000Z","caller":"gateway/user.go:52","message":"three"}`+"\n"+
+			"not json\n"+
+			`{"ts":"2026-10-19T11:00:00.000Z","caller":"gateway/auth.go:30","message":"Four","requestId":"r1"}`+"\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(folder, "debug.log"), []byte(
+		`{"ts":"2026-10-19T08:00:00.000Z","caller":"core/health.go:20","message":"zero"}`+"\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(folder, "error.log"), []byte(
+		`{"ts":"2026-10-19T10:30:00.000Z","caller":"gateway/user.go:60","message":"failed three"}`+"\n"+
+			`{"ts":"2026-10-19T10:45:00.000Z","caller":"gateway/auth.go:33","message":"failed four"}`+"\n"), 0644))
+
+	at := func(value string) time.Time {
+		ts, err := time.Parse(time.RFC3339, value)
+		require.NoError(t, err)
+		return ts
+	}
+
+	tests := []struct {
+		name             string
+		query            logger.Query
+		expectedMessages []string
+	}{
+		{
+			name:             "Everything, newest first",
+			query:            logger.Query{},
+			expectedMessages: []string{"Four", "failed four", "failed three", "three", "two", "one", "zero"},
+		},
+		{
+			name:             "Paged",
+			query:            logger.Query{Limit: 2},
+			expectedMessages: []string{"Four", "failed four", "failed three", "three", "two", "one", "zero"},
+		},
+		{
+			name:             "Paged through equal timestamps",
+			query:            logger.Query{Limit: 1},
+			expectedMessages: []string{"Four", "failed four", "failed three", "three", "two", "one", "zero"},
+		},
+		{
+			name:             "Level",
+			query:            logger.Query{Levels: []string{"error"}},
+			expectedMessages: []string{"failed four", "failed three"},
+		},
+		{
+			name:             "Time range",
+			query:            logger.Query{From: at("2026-10-19T09:30:00Z"), To: at("2026-10-19T10:30:00Z")},
+			expectedMessages: []string{"failed three", "three", "two"},
+		},
+		{
+			name:             "Caller",
+			query:            logger.Query{Caller: "USER.GO"},
+			expectedMessages: []string{"failed three", "three", "one"},
+		},
+		{
+			name:             "Text",
+			query:            logger.Query{Text: "four"},
+			expectedMessages: []string{"Four", "failed four"},
+		},
+		{
+			name:             "Request ID",
+			query:            logger.Query{RequestID: "r1"},
+			expectedMessages: []string{"Four"},
+		},
+		{
+			name:             "Missing level",
+			query:            logger.Query{Levels: []string{"warn"}},
+			expectedMessages: nil,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var messages []string
+
+			query := tc.query
+			for pages := 0; ; pages++ {
+				require.Less(t, pages, 10)
+
+				page, err := logger.Search(folder, "", query)
+				require.NoError(t, err)
+
+				for _, entry := range page.Entries {
+					messages = append(messages, entry["message"].(string))
+				}
+				if page.Next == "" {
+					break
+				}
+				query.Cursor = page.Next
+			}
+
+			assert.Equal(t, tc.expectedMessages, messages)
+		})
+	}
+
+	t.Run("Invalid cursor", func(t *testing.T) {
+		_, err := logger.Search(folder, "", logger.Query{Cursor: "not a cursor"})
+		assert.ErrorIs(t, err, logger.ErrInvalidCursor)
+	})
+}
+
+func TestFollow(t *testing.T) {
+	folder := t.TempDir()
+	filePath := filepath.Join(folder, "info.log")
+
+	require.NoError(t, os.WriteFile(filePath, []byte(
+		`{"ts":"2026-10-19T09:00:00.000Z","message":"before"}`+"\n"), 0644))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	messages := make(chan string, 10)
+	done := make(chan error, 1)
+	go func() {
+		done <- logger.Follow(ctx, folder, "", logger.Query{Levels: []string{"info"}, Text: "tail"}, 10*time.Millisecond,
+			func(entry map[string]interface{}) error {
+				messages <- entry["message"].(string)
+				return nil
+			})
+	}()
+
+	next := func() string {
+		select {
+		case message := <-messages:
+			return message
+		case <-time.After(2 * time.Second):
+			t.Fatal("no entry followed")
+			return ""
+		}
+	}
+
+	// Give Follow time to take the current size before appending.
+	time.Sleep(50 * time.Millisecond)
+
+	file, err := os.OpenFile(filePath, os.O_APPEND|os.O_WRONLY, 0644)
+	require.NoError(t, err)
+	_, err = file.WriteString(`{"ts":"2026-10-19T10:00:00.000Z","message":"skipped"}` + "\n" +
+		`{"ts":"2026-10-19T10:00:01.000Z","message":"tail one"}` + "\n" +
+		`{"ts":"2026-10-19T10:00:02.000Z","message":"tail two, partial`)
+	require.NoError(t, err)
+	assert.Equal(t, "tail one", next())
+
+	_, err = file.WriteString(`"}` + "\n")
+	require.NoError(t, err)
+	require.NoError(t, file.Close())
+	assert.Equal(t, "tail two, partial", next())
+
+	// A rotated file starts over from the beginning.
+	require.NoError(t, os.WriteFile(filePath, []byte(
+		`{"ts":"2026-10-19T10:01:00.000Z","message":"tail after rotation"}`+"\n"), 0644))
+	assert.Equal(t, "tail after rotation", next())
+
+	cancel()
+	assert.ErrorIs(t, <-done, context.Canceled)
+}
@@ -0,0 +1,177 @@
+package logger
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// DefaultRedactedFields are masked, along with any Redaction.Fields, in every
+// field whose name contains one of them, ignoring case, dashes and
+// underscores.
+var DefaultRedactedFields = []string{"password", "secret", "token", "apikey", "authorization", "cookie"}
+
+const redacted = "[redacted]"
+
+// identifiers are random IDs written as is, even when they match the hex
+// pattern, so entries can be searched by them.
+var identifiers = map[string]bool{"traceid": true, "spanid": true}
+
+// Redaction configures what is masked before entries reach zap. Allow names
+// fields or patterns (email, phone, jwt, hex) to leave untouched while
+// debugging.
+type Redaction struct {
+	Fields []string
+	Allow  []string
+}
+
+type pattern struct {
+	name string
+	re   *regexp.Regexp
+}
+
+var patterns = []pattern{
+	{name: "jwt", re: regexp.MustCompile(`eyJ[A-Za-z0-9_-]+\.[A-Za-z0-9_-]+\.[A-Za-z0-9_-]*`)},
+	{name: "email", re: regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`)},
+	{name: "hex", re: regexp.MustCompile(`\b[0-9a-fA-F]{32,}\b`)},
+	// Without a leading + a phone needs parentheses or separators, so bare
+	// numbers such as unix timestamps are left alone.
+	{name: "phone", re: regexp.MustCompile(`\+\d{1,3}[\s.-]?\(?\d{3}\)?[\s.-]?\d{3}[\s.-]?\d{4}\b|\+\d{7,15}\b|\(\d{3}\)[\s.-]?\d{3}[\s.-]?\d{4}\b|\b\d{3}[\s.-]\d{3}[\s.-]\d{4}\b`)},
+}
+
+type redactor struct {
+	fields   []string
+	allow    map[string]bool
+	patterns []pattern
+}
+
+func newRedactor(redaction Redaction) *redactor {
+	r := &redactor{allow: make(map[string]bool)}
+
+	for _, allowed := range redaction.Allow {
+		r.allow[normalize(allowed)] = true
+	}
+
+	fields := make([]string, 0, len(DefaultRedactedFields)+len(redaction.Fields))
+	fields = append(fields, DefaultRedactedFields...)
+	fields = append(fields, redaction.Fields...)
+
+	for _, field := range fields {
+		if field = normalize(field); field != "" && !r.allow[field] {
+			r.fields = append(r.fields, field)
+		}
+	}
+
+	for _, p := range patterns {
+		if !r.allow[p.name] {
+			r.patterns = append(r.patterns, p)
+		}
+	}
+
+	return r
+}
+
+// text masks every pattern match in s.
+func (r *redactor) text(s string) string {
+	for _, p := range r.patterns {
+		s = p.re.ReplaceAllString(s, "[redacted:"+p.name+"]")
+	}
+
+	return s
+}
+
+// pairs masks alternating keys and values, returning a new slice.
+func (r *redactor) pairs(keysAndValues []interface{}) []interface{} {
+	out := make([]interface{}, len(keysAndValues))
+
+	for i := 0; i < len(keysAndValues); i++ {
+		if i%2 == 1 {
+			key := fmt.Sprint(keysAndValues[i-1])
+			if r.sensitive(key) {
+				out[i] = redacted
+				continue
+			}
+			if identifiers[normalize(key)] {
+				out[i] = keysAndValues[i]
+				continue
+			}
+			out[i] = r.value(keysAndValues[i])
+			continue
+		}
+
+		out[i] = keysAndValues[i]
+	}
+
+	return out
+}
+
+func (r *redactor) sensitive(key string) bool {
+	key = normalize(key)
+	if r.allow[key] {
+		return false
+	}
+
+	for _, field := range r.fields {
+		if strings.Contains(key, field) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// value masks a field value. Structs, maps and slices go through their JSON
+// form so nested fields are masked by name too.
+func (r *redactor) value(v interface{}) interface{} {
+	switch typed := v.(type) {
+	case nil, bool, int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64, time.Time, time.Duration:
+		return v
+	case string:
+		return r.text(typed)
+	case error:
+		return r.text(typed.Error())
+	case fmt.Stringer:
+		return r.text(typed.String())
+	}
+
+	encoded, err := json.Marshal(v)
+	if err != nil {
+		return r.text(fmt.Sprintf("%+v", v))
+	}
+
+	var decoded interface{}
+	if err := json.Unmarshal(encoded, &decoded); err != nil {
+		return r.text(string(encoded))
+	}
+
+	return r.walk(decoded)
+}
+
+func (r *redactor) walk(v interface{}) interface{} {
+	switch typed := v.(type) {
+	case map[string]interface{}:
+		for key, nested := range typed {
+			if r.sensitive(key) {
+				typed[key] = redacted
+				continue
+			}
+			typed[key] = r.walk(nested)
+		}
+		return typed
+	case []interface{}:
+		for i, nested := range typed {
+			typed[i] = r.walk(nested)
+		}
+		return typed
+	case string:
+		return r.text(typed)
+	default:
+		return v
+	}
+}
+
+func normalize(name string) string {
+	return strings.NewReplacer("_", "", "-", "", " ", "").Replace(strings.ToLower(name))
+}
@@ -0,0 +1,316 @@
+package logger
+
+import (
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// SegmentLayout stamps rotated files as <level>-<stamp>.log[.gz], in UTC.
+const SegmentLayout = "20060102T150405.000Z"
+
+const (
+	logExt     = ".log"
+	gzipExt    = ".gz"
+	megabyte   = 1024 * 1024
+	dayLayout  = "2006-01-02"
+	retryStamp = 3
+)
+
+// Rotation configures when the level files are rotated and how long the
+// rotated segments are kept. Zero values disable the matching limit.
+type Rotation struct {
+	MaxSizeMB  int
+	MaxBackups int
+	MaxAgeDays int
+	Daily      bool
+	Compress   bool
+}
+
+// rotator is a zapcore.WriteSyncer over <folder>/<level>.log that moves the
+// file aside when it grows too large or the day changes.
+type rotator struct {
+	mu       sync.Mutex
+	folder   string
+	level    string
+	rotation Rotation
+	file     *os.File
+	size     int64
+	day      string
+	now      func() time.Time
+	rename   func(oldPath, newPath string) error
+	// errs receives the rotation failures that do not cost a write.
+	errs io.Writer
+}
+
+func newRotator(folder, level string, rotation Rotation) (*rotator, error) {
+	r := &rotator{
+		folder:   folder,
+		level:    level,
+		rotation: rotation,
+		now:      time.Now,
+		rename:   os.Rename,
+		errs:     os.Stderr,
+	}
+
+	if err := r.open(); err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+func (r *rotator) Write(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// A failed rotation keeps the current file open, so the line is still
+	// written to it.
+	if r.due(int64(len(p))) {
+		if err := r.rotate(); err != nil {
+			r.report(err)
+		}
+	}
+
+	n, err := r.file.Write(p)
+	r.size += int64(n)
+	return n, err
+}
+
+func (r *rotator) Sync() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.file.Sync()
+}
+
+// Rotate moves the current file aside, if it has content.
+func (r *rotator) Rotate() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.size == 0 {
+		return nil
+	}
+
+	return r.rotate()
+}
+
+func (r *rotator) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.file.Close()
+}
+
+func (r *rotator) path() string {
+	return filepath.Join(r.folder, r.level+logExt)
+}
+
+func (r *rotator) open() error {
+	file, err := os.OpenFile(r.path(), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open log file: %w", err)
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+
+	r.file = file
+	r.size = info.Size()
+	r.day = info.ModTime().Format(dayLayout)
+	if r.size == 0 {
+		r.day = r.now().Format(dayLayout)
+	}
+
+	return nil
+}
+
+func (r *rotator) due(incoming int64) bool {
+	if r.size == 0 {
+		return false
+	}
+	if r.rotation.Daily && r.now().Format(dayLayout) != r.day {
+		return true
+	}
+
+	return r.rotation.MaxSizeMB > 0 && r.size+incoming > int64(r.rotation.MaxSizeMB)*megabyte
+}
+
+// rotate moves the current file aside and opens a new one. The current file is
+// reopened whatever fails after it is closed. Compressing and pruning the
+// segments happen after and only report their failures.
+func (r *rotator) rotate() error {
+	segment, err := r.segmentPath()
+	if err != nil {
+		return err
+	}
+
+	closeErr := r.file.Close()
+	if closeErr == nil {
+		closeErr = r.rename(r.path(), segment)
+	}
+	if err := r.open(); err != nil {
+		return errors.Join(closeErr, err)
+	}
+	if closeErr != nil {
+		return closeErr
+	}
+
+	if r.rotation.Compress {
+		if err := compressFile(segment); err != nil {
+			r.report(err)
+		}
+	}
+
+	if err := r.prune(); err != nil {
+		r.report(err)
+	}
+
+	return nil
+}
+
+func (r *rotator) report(err error) {
+	fmt.Fprintf(r.errs, "%s failed to rotate %s: %v\n", r.now().UTC().Format(TimeLayout), r.level+logExt, err)
+}
+
+// segmentPath picks a free name; two rotations within the same millisecond
+// would otherwise collide.
+func (r *rotator) segmentPath() (string, error) {
+	stamp := r.now().UTC()
+
+	for i := 0; i < retryStamp; i++ {
+		name := fmt.Sprintf("%s-%s%s", r.level, stamp.Format(SegmentLayout), logExt)
+		candidate := filepath.Join(r.folder, name)
+
+		_, errPlain := os.Stat(candidate)
+		_, errGzip := os.Stat(candidate + gzipExt)
+		if errors.Is(errPlain, fs.ErrNotExist) && errors.Is(errGzip, fs.ErrNotExist) {
+			return candidate, nil
+		}
+
+		stamp = stamp.Add(time.Millisecond)
+	}
+
+	return "", fmt.Errorf("no free segment name for %s", r.level)
+}
+
+// prune drops the segments past MaxBackups or older than MaxAgeDays.
+func (r *rotator) prune() error {
+	if r.rotation.MaxBackups <= 0 && r.rotation.MaxAgeDays <= 0 {
+		return nil
+	}
+
+	segments, err := Segments(r.folder, r.level)
+	if err != nil {
+		return err
+	}
+
+	cutoff := r.now().Add(-time.Duration(r.rotation.MaxAgeDays) * 24 * time.Hour)
+	keep := len(segments)
+	if r.rotation.MaxBackups > 0 && keep > r.rotation.MaxBackups {
+		keep = r.rotation.MaxBackups
+	}
+
+	// segments are oldest first
+	for i, segment := range segments {
+		expired := i < len(segments)-keep
+		if !expired && r.rotation.MaxAgeDays > 0 {
+			if stamp, ok := SegmentTime(segment); ok && stamp.Before(cutoff) {
+				expired = true
+			}
+		}
+
+		if expired {
+			if err := os.Remove(segment); err != nil && !errors.Is(err, fs.ErrNotExist) {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// Segments lists the rotated files of a level in folder, oldest first.
+func Segments(folder, level string) ([]string, error) {
+	matches, err := filepath.Glob(filepath.Join(folder, level+"-*"+logExt+"*"))
+	if err != nil {
+		return nil, err
+	}
+
+	var segments []string
+	for _, match := range matches {
+		if _, ok := SegmentTime(match); ok {
+			segments = append(segments, match)
+		}
+	}
+
+	sort.Slice(segments, func(i, j int) bool {
+		left, _ := SegmentTime(segments[i])
+		right, _ := SegmentTime(segments[j])
+		return left.Before(right)
+	})
+
+	return segments, nil
+}
+
+// SegmentTime returns the rotation time stamped in a segment name.
+func SegmentTime(segment string) (time.Time, bool) {
+	name := strings.TrimSuffix(filepath.Base(segment), gzipExt)
+	if !strings.HasSuffix(name, logExt) {
+		return time.Time{}, false
+	}
+	name = strings.TrimSuffix(name, logExt)
+
+	dash := strings.LastIndex(name, "-")
+	if dash < 0 {
+		return time.Time{}, false
+	}
+
+	stamp, err := time.Parse(SegmentLayout, name[dash+1:])
+	if err != nil {
+		return time.Time{}, false
+	}
+
+	return stamp, true
+}
+
+func compressFile(source string) error {
+	in, err := os.Open(source)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	target := source + gzipExt
+	out, err := os.OpenFile(target, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+
+	gz := gzip.NewWriter(out)
+	_, err = io.Copy(gz, in)
+	if err == nil {
+		err = gz.Close()
+	}
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(target)
+		return err
+	}
+
+	return os.Remove(source)
+}
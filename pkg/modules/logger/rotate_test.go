@@ -0,0 +1,117 @@
+package logger
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRotator(t *testing.T) {
+	line := strings.Repeat("x", 400*1024) + "\n"
+
+	tests := []struct {
+		name             string
+		rotation         Rotation
+		writes           int
+		nextDay          bool
+		expectedSegments int
+		expectedGzip     bool
+	}{
+		{name: "No limits", rotation: Rotation{}, writes: 4, expectedSegments: 0},
+		{name: "By size", rotation: Rotation{MaxSizeMB: 1}, writes: 4, expectedSegments: 1},
+		{name: "By day", rotation: Rotation{Daily: true}, writes: 2, nextDay: true, expectedSegments: 1},
+		{name: "Compressed", rotation: Rotation{MaxSizeMB: 1, Compress: true}, writes: 4, expectedSegments: 1, expectedGzip: true},
+		{name: "Max backups", rotation: Rotation{MaxSizeMB: 1, MaxBackups: 2}, writes: 16, expectedSegments: 2},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			folder := t.TempDir()
+			clock := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+
+			r, err := newRotator(folder, "info", tc.rotation)
+			require.NoError(t, err)
+			defer r.Close()
+			r.now = func() time.Time {
+				clock = clock.Add(time.Second)
+				return clock
+			}
+
+			for i := 0; i < tc.writes; i++ {
+				if tc.nextDay && i == tc.writes-1 {
+					clock = clock.Add(24 * time.Hour)
+				}
+				_, err := r.Write([]byte(line))
+				require.NoError(t, err)
+			}
+
+			segments, err := Segments(folder, "info")
+			require.NoError(t, err)
+			assert.Len(t, segments, tc.expectedSegments)
+
+			for _, segment := range segments {
+				assert.Equal(t, tc.expectedGzip, strings.HasSuffix(segment, ".gz"))
+			}
+		})
+	}
+}
+
+func TestRotatorRenameFailure(t *testing.T) {
+	folder := t.TempDir()
+	line := strings.Repeat("x", 600*1024) + "\n"
+
+	r, err := newRotator(folder, "info", Rotation{MaxSizeMB: 1})
+	require.NoError(t, err)
+	defer r.Close()
+
+	var reported bytes.Buffer
+	r.errs = &reported
+	r.rename = func(string, string) error { return errors.New("device busy") }
+
+	for i := 0; i < 2; i++ {
+		_, err := r.Write([]byte(line))
+		require.NoError(t, err)
+	}
+	assert.Contains(t, reported.String(), "device busy")
+
+	content, err := os.ReadFile(filepath.Join(folder, "info.log"))
+	require.NoError(t, err)
+	assert.Equal(t, 2*len(line), len(content))
+
+	r.rename = os.Rename
+	_, err = r.Write([]byte(line))
+	require.NoError(t, err)
+
+	segments, err := Segments(folder, "info")
+	require.NoError(t, err)
+	assert.Len(t, segments, 1)
+}
+
+func TestRotatorMaxAge(t *testing.T) {
+	folder := t.TempDir()
+
+	old := filepath.Join(folder, "info-20260101T000000.000Z.log")
+	recent := filepath.Join(folder, "info-20261018T000000.000Z.log")
+	require.NoError(t, os.WriteFile(old, []byte("{}\n"), 0644))
+	require.NoError(t, os.WriteFile(recent, []byte("{}\n"), 0644))
+
+	r, err := newRotator(folder, "info", Rotation{MaxAgeDays: 7})
+	require.NoError(t, err)
+	defer r.Close()
+	r.now = func() time.Time { return time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC) }
+
+	_, err = r.Write([]byte("{}\n"))
+	require.NoError(t, err)
+	require.NoError(t, r.Rotate())
+
+	segments, err := Segments(folder, "info")
+	require.NoError(t, err)
+	assert.Equal(t, []string{recent, filepath.Join(folder, "info-20261019T120000.000Z.log")}, segments)
+}
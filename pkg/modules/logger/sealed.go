@@ -0,0 +1,183 @@
+package logger
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"project-wraith/pkg/modules/alchemy"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// sealedPrefix marks a log line sealed at rest; the rest of the line is the
+// base64 of nonce | AES-GCM ciphertext of the JSON entry.
+const sealedPrefix = "wrth1:"
+
+var ErrSealedLine = errors.New("sealed log line")
+
+type lineSealer struct {
+	aead cipher.AEAD
+}
+
+func newLineSealer(secret string) (*lineSealer, error) {
+	block, err := aes.NewCipher(alchemy.GenerateKey(secret))
+	if err != nil {
+		return nil, err
+	}
+
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	return &lineSealer{aead: aead}, nil
+}
+
+func (ls *lineSealer) seal(line []byte) ([]byte, error) {
+	nonce := make([]byte, ls.aead.NonceSize(), ls.aead.NonceSize()+len(line)+ls.aead.Overhead())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+
+	sealed := ls.aead.Seal(nonce, nonce, line, []byte(sealedPrefix))
+
+	out := make([]byte, 0, len(sealedPrefix)+base64.StdEncoding.EncodedLen(len(sealed))+1)
+	out = append(out, sealedPrefix...)
+	out = base64.StdEncoding.AppendEncode(out, sealed)
+	return append(out, '\n'), nil
+}
+
+func (ls *lineSealer) open(line []byte) ([]byte, error) {
+	sealed, err := base64.StdEncoding.DecodeString(string(bytes.TrimPrefix(line, []byte(sealedPrefix))))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrSealedLine, err)
+	}
+
+	nonceSize := ls.aead.NonceSize()
+	if len(sealed) < nonceSize {
+		return nil, fmt.Errorf("%w: too short", ErrSealedLine)
+	}
+
+	plain, err := ls.aead.Open(nil, sealed[:nonceSize], sealed[nonceSize:], []byte(sealedPrefix))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrSealedLine, err)
+	}
+
+	return plain, nil
+}
+
+// sealedSink seals every line written to it before passing it on, so log
+// files never hold plaintext entries.
+type sealedSink struct {
+	next   zapcore.WriteSyncer
+	sealer *lineSealer
+}
+
+func (ss sealedSink) Write(p []byte) (int, error) {
+	var out []byte
+	for _, line := range bytes.Split(p, []byte{'\n'}) {
+		if len(line) == 0 {
+			continue
+		}
+
+		sealed, err := ss.sealer.seal(line)
+		if err != nil {
+			return 0, err
+		}
+		out = append(out, sealed...)
+	}
+
+	if _, err := ss.next.Write(out); err != nil {
+		return 0, err
+	}
+
+	return len(p), nil
+}
+
+func (ss sealedSink) Sync() error {
+	return ss.next.Sync()
+}
+
+// lineReader yields the plaintext lines of a log file, opening sealed lines
+// with secret. Sealed lines fail when there is no secret.
+type lineReader struct {
+	scanner *bufio.Scanner
+	sealer  *lineSealer
+	number  int
+}
+
+func newLineReader(src io.Reader, secret string) (*lineReader, error) {
+	scanner := bufio.NewScanner(src)
+	scanner.Buffer(make([]byte, 0, 64*1024), 4*megabyte)
+
+	lr := &lineReader{scanner: scanner}
+	if secret != "" {
+		sealer, err := newLineSealer(secret)
+		if err != nil {
+			return nil, err
+		}
+		lr.sealer = sealer
+	}
+
+	return lr, nil
+}
+
+// next returns false at the end of src. err is set for a line that could
+// not be opened; reading can go on after it.
+func (lr *lineReader) next() (line []byte, ok bool, err error) {
+	if !lr.scanner.Scan() {
+		return nil, false, lr.scanner.Err()
+	}
+	lr.number++
+
+	line = lr.scanner.Bytes()
+	if !bytes.HasPrefix(line, []byte(sealedPrefix)) {
+		return line, true, nil
+	}
+
+	if lr.sealer == nil {
+		return nil, true, fmt.Errorf("line %d: %w: no secret to open it", lr.number, ErrSealedLine)
+	}
+
+	plain, err := lr.sealer.open(line)
+	if err != nil {
+		return nil, true, fmt.Errorf("line %d: %w", lr.number, err)
+	}
+
+	return plain, true, nil
+}
+
+// DecryptLines copies src to dst with every sealed line opened with secret.
+// Plain lines pass through unchanged, so mixed files are fine.
+func DecryptLines(src io.Reader, dst io.Writer, secret string) error {
+	lr, err := newLineReader(src, secret)
+	if err != nil {
+		return err
+	}
+
+	writer := bufio.NewWriter(dst)
+	for {
+		line, ok, err := lr.next()
+		if err != nil {
+			return err
+		}
+		if !ok {
+			break
+		}
+
+		if _, err := writer.Write(line); err != nil {
+			return err
+		}
+		if err := writer.WriteByte('\n'); err != nil {
+			return err
+		}
+	}
+
+	return writer.Flush()
+}
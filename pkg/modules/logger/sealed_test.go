@@ -0,0 +1,85 @@
+package logger
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSealedLogFiles(t *testing.T) {
+	folder := t.TempDir()
+
+	l := NewLogger(folder, false, Rotation{}, Redaction{}, "logs_secret")
+	require.NoError(t, l.Initialize())
+
+	l.Infow("user signed in", "username", "alice")
+	l.Info("reset mail queued")
+
+	raw, err := os.ReadFile(filepath.Join(folder, "info.log"))
+	require.NoError(t, err)
+	assert.NotContains(t, string(raw), "alice")
+	assert.NotContains(t, string(raw), "reset mail")
+	for _, line := range strings.Split(strings.TrimSpace(string(raw)), "\n") {
+		assert.True(t, strings.HasPrefix(line, sealedPrefix))
+	}
+
+	tests := []struct {
+		name            string
+		secret          string
+		expectedEntries int
+	}{
+		{name: "Right secret", secret: "logs_secret", expectedEntries: 2},
+		{name: "Wrong secret", secret: "other_secret", expectedEntries: 0},
+		{name: "No secret", secret: "", expectedEntries: 0},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			entries, err := ReadFile(filepath.Join(folder, "info.log"), tc.secret)
+			require.NoError(t, err)
+			assert.Len(t, entries, tc.expectedEntries)
+		})
+	}
+}
+
+func TestDecryptLines(t *testing.T) {
+	sealer, err := newLineSealer("logs_secret")
+	require.NoError(t, err)
+
+	sealed, err := sealer.seal([]byte(`{"message":"sealed"}`))
+	require.NoError(t, err)
+
+	tampered := append([]byte{}, sealed...)
+	tampered[len(tampered)-6] ^= 1
+
+	tests := []struct {
+		name      string
+		input     []byte
+		secret    string
+		expected  string
+		expectErr bool
+	}{
+		{name: "Mixed file", input: append([]byte(`{"message":"plain"}`+"\n"), sealed...), secret: "logs_secret", expected: `{"message":"plain"}` + "\n" + `{"message":"sealed"}` + "\n"},
+		{name: "Wrong secret", input: sealed, secret: "other_secret", expectErr: true},
+		{name: "Tampered line", input: tampered, secret: "logs_secret", expectErr: true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var out bytes.Buffer
+			err := DecryptLines(bytes.NewReader(tc.input), &out, tc.secret)
+			if tc.expectErr {
+				assert.ErrorIs(t, err, ErrSealedLine)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, out.String())
+		})
+	}
+}
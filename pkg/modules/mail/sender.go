@@ -2,13 +2,22 @@ package mail
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"html/template"
+	"net"
 	"net/smtp"
+	"project-wraith/pkg/modules/metrics"
+	"project-wraith/pkg/modules/tracing"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
 type Mail interface {
-	Send(tmpl string, content interface{}, subject string, to []string) error
+	Send(ctx context.Context, tmpl string, content interface{}, subject string, to []string) error
+	// Ping connects to the SMTP server and waits for its greeting.
+	Ping(ctx context.Context) error
 }
 
 type mail struct {
@@ -27,7 +36,14 @@ func NewMail(from, password, smtpHost, smtpPort string) Mail {
 	}
 }
 
-func (mc *mail) Send(tmpl string, content interface{}, subject string, to []string) error {
+func (mc *mail) Send(ctx context.Context, tmpl string, content interface{}, subject string, to []string) (err error) {
+	_, span := tracing.Start(ctx, "SMTP send",
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("server.address", mc.smtpHost),
+			attribute.Int("mail.recipients", len(to))))
+	defer func() { tracing.End(span, err) }()
+
 	parsedTmpl, err := template.ParseFiles(tmpl)
 	if err != nil {
 		return err
@@ -49,6 +65,7 @@ func (mc *mail) Send(tmpl string, content interface{}, subject string, to []stri
 		mc.from,
 		to,
 		body.Bytes())
+	metrics.MailSent.WithLabelValues(metrics.Outcome(err)).Inc()
 	if err != nil {
 		return err
 	}
@@ -56,3 +73,25 @@ func (mc *mail) Send(tmpl string, content interface{}, subject string, to []stri
 	return nil
 
 }
+
+func (mc *mail) Ping(ctx context.Context) error {
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", net.JoinHostPort(mc.smtpHost, mc.smtpPort))
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if deadline, ok := ctx.Deadline(); ok {
+		if err := conn.SetDeadline(deadline); err != nil {
+			return err
+		}
+	}
+
+	client, err := smtp.NewClient(conn, mc.smtpHost)
+	if err != nil {
+		return err
+	}
+
+	return client.Quit()
+}
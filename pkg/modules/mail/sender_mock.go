@@ -1,6 +1,8 @@
 package mail
 
 import (
+	"context"
+
 	"github.com/stretchr/testify/mock"
 )
 
@@ -8,7 +10,11 @@ type MockMail struct {
 	mock.Mock
 }
 
-func (m *MockMail) Send(tmpl string, content interface{}, subject string, to []string) error {
-	args := m.Called(tmpl, content, subject, to)
+func (m *MockMail) Send(ctx context.Context, tmpl string, content interface{}, subject string, to []string) error {
+	args := m.Called(ctx, tmpl, content, subject, to)
 	return args.Error(0)
 }
+
+func (m *MockMail) Ping(ctx context.Context) error {
+	return m.Called(ctx).Error(0)
+}
@@ -1,10 +1,15 @@
 package mail
 
 import (
+	"bufio"
+	"context"
 	"errors"
+	"net"
 	"net/smtp"
 	"os"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -73,7 +78,7 @@ func TestSendMail(t *testing.T) {
 			}(tc.templateFile) // Clean up the file after test
 
 			// Call Send method
-			err = m.Send(tc.templateFile, tc.content, tc.subject, tc.to)
+			err = m.Send(context.Background(), tc.templateFile, tc.content, tc.subject, tc.to)
 			if tc.wantError {
 				assert.Error(t, err)
 			} else {
@@ -91,3 +96,43 @@ func writeFile(filename, content string) error {
 func deleteFile(filename string) error {
 	return os.Remove(filename)
 }
+
+func TestPing(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	defer listener.Close()
+
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		_, _ = conn.Write([]byte("220 localhost ESMTP\r\n"))
+		reader := bufio.NewReader(conn)
+		for {
+			line, err := reader.ReadString('\n')
+			if err != nil {
+				return
+			}
+			if strings.HasPrefix(line, "QUIT") {
+				_, _ = conn.Write([]byte("221 bye\r\n"))
+				return
+			}
+			_, _ = conn.Write([]byte("250 localhost\r\n"))
+		}
+	}()
+
+	host, port, err := net.SplitHostPort(listener.Addr().String())
+	assert.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	m := NewMail("from@example.com", "password", host, port)
+	assert.NoError(t, m.Ping(ctx))
+
+	listener.Close()
+	assert.Error(t, m.Ping(ctx))
+}
@@ -0,0 +1,125 @@
+package metrics
+
+import (
+	"errors"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+const namespace = "wraith"
+
+// Registry holds every collector of the server, plus the Go runtime and
+// process ones, apart from the prometheus default registry.
+var Registry = prometheus.NewRegistry()
+
+var (
+	HTTPRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_requests_total",
+		Help:      "HTTP requests by route template, method and status.",
+	}, []string{"route", "method", "status"})
+
+	HTTPDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "HTTP request latency by route template, method and status.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"route", "method", "status"})
+
+	MongoDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "mongo_operation_duration_seconds",
+		Help:      "Repository operation latency by collection, operation and outcome.",
+		Buckets:   []float64{.001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5},
+	}, []string{"collection", "operation", "outcome"})
+
+	MailSent = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "mail_sent_total",
+		Help:      "Mails sent by outcome.",
+	}, []string{"outcome"})
+
+	SMSSent = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "sms_sent_total",
+		Help:      "SMS sent by outcome.",
+	}, []string{"outcome"})
+
+	Logins = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "logins_total",
+		Help:      "Login attempts by outcome.",
+	}, []string{"outcome"})
+
+	ResetStarts = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "password_reset_starts_total",
+		Help:      "Password resets started by outcome.",
+	}, []string{"outcome"})
+
+	LicenseStatus = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "license_status",
+		Help:      "1 for the current license status: active, invalid or unused.",
+	}, []string{"status"})
+)
+
+const (
+	Success = "success"
+	Failure = "failure"
+
+	LicenseActive  = "active"
+	LicenseInvalid = "invalid"
+	LicenseUnused  = "unused"
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		HTTPRequests,
+		HTTPDuration,
+		MongoDuration,
+		MailSent,
+		SMSSent,
+		Logins,
+		ResetStarts,
+		LicenseStatus,
+	)
+}
+
+// Outcome labels err as Success or Failure.
+func Outcome(err error) string {
+	if err != nil {
+		return Failure
+	}
+
+	return Success
+}
+
+// Mongo starts timing a repository operation; call the returned func with
+// the operation error once it is done. Finding no documents is a success.
+func Mongo(collection, operation string) func(err error) {
+	start := time.Now()
+
+	return func(err error) {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			err = nil
+		}
+		MongoDuration.WithLabelValues(collection, operation, Outcome(err)).Observe(time.Since(start).Seconds())
+	}
+}
+
+// License sets the current license status.
+func License(status string) {
+	for _, known := range []string{LicenseActive, LicenseInvalid, LicenseUnused} {
+		value := 0.0
+		if known == status {
+			value = 1
+		}
+		LicenseStatus.WithLabelValues(known).Set(value)
+	}
+}
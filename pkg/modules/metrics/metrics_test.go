@@ -0,0 +1,48 @@
+package metrics_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/mongo"
+	"project-wraith/pkg/modules/metrics"
+)
+
+func TestMongo(t *testing.T) {
+	tests := []struct {
+		name            string
+		err             error
+		expectedOutcome string
+	}{
+		{name: "Success", expectedOutcome: metrics.Success},
+		{name: "No documents", err: mongo.ErrNoDocuments, expectedOutcome: metrics.Success},
+		{name: "Failure", err: errors.New("connection reset"), expectedOutcome: metrics.Failure},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			done := metrics.Mongo(tc.name, "get")
+			done(tc.err)
+
+			observed := &dto.Metric{}
+			histogram := metrics.MongoDuration.WithLabelValues(tc.name, "get", tc.expectedOutcome)
+			require.NoError(t, histogram.(prometheus.Metric).Write(observed))
+			assert.Equal(t, uint64(1), observed.GetHistogram().GetSampleCount())
+		})
+	}
+}
+
+func TestLicense(t *testing.T) {
+	metrics.License(metrics.LicenseActive)
+	assert.Equal(t, 1.0, testutil.ToFloat64(metrics.LicenseStatus.WithLabelValues(metrics.LicenseActive)))
+
+	metrics.License(metrics.LicenseInvalid)
+	assert.Equal(t, 0.0, testutil.ToFloat64(metrics.LicenseStatus.WithLabelValues(metrics.LicenseActive)))
+	assert.Equal(t, 1.0, testutil.ToFloat64(metrics.LicenseStatus.WithLabelValues(metrics.LicenseInvalid)))
+	assert.Equal(t, 0.0, testutil.ToFloat64(metrics.LicenseStatus.WithLabelValues(metrics.LicenseUnused)))
+}
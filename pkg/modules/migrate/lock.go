@@ -0,0 +1,126 @@
+package migrate
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"project-wraith/pkg/modules/metrics"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ErrLocked is returned by Up and Down while another runner, such as a
+// replica starting at the same time, holds the migrations lock.
+var ErrLocked = errors.New("migrations are locked by another runner")
+
+const lockID = "migrations"
+
+type lockDocument struct {
+	ID        string    `bson:"_id"`
+	Owner     string    `bson:"owner"`
+	ExpiresAt time.Time `bson:"expires_at"`
+}
+
+// lock is a single document that a runner owns until it releases it or its
+// expiry passes, so a crashed runner does not block the others for good.
+type lock struct {
+	collection *mongo.Collection
+	owner      string
+	ttl        time.Duration
+}
+
+func newLock(collection *mongo.Collection, ttl time.Duration) (*lock, error) {
+	suffix := make([]byte, 4)
+	if _, err := rand.Read(suffix); err != nil {
+		return nil, err
+	}
+
+	host, _ := os.Hostname()
+	owner := fmt.Sprintf("%s:%d:%s", host, os.Getpid(), hex.EncodeToString(suffix))
+
+	return &lock{collection: collection, owner: owner, ttl: ttl}, nil
+}
+
+// acquire takes the lock when it is free, expired or already ours, and
+// extends it by ttl. The upsert runs into the unique _id when someone else
+// holds it.
+func (l *lock) acquire(ctx context.Context) error {
+	now := time.Now().UTC()
+	filter := bson.M{
+		"_id": lockID,
+		"$or": bson.A{
+			bson.M{"owner": l.owner},
+			bson.M{"expires_at": bson.M{"$lte": now}},
+		},
+	}
+	update := bson.M{"$set": bson.M{"owner": l.owner, "expires_at": now.Add(l.ttl)}}
+
+	done := metrics.Mongo(l.collection.Name(), "lock")
+	_, err := l.collection.UpdateOne(ctx, filter, update, options.Update().SetUpsert(true))
+	done(err)
+	if mongo.IsDuplicateKeyError(err) {
+		return ErrLocked
+	}
+	if err != nil {
+		return fmt.Errorf("failed to lock migrations: %w", err)
+	}
+
+	return nil
+}
+
+func (l *lock) release(ctx context.Context) error {
+	done := metrics.Mongo(l.collection.Name(), "unlock")
+	_, err := l.collection.DeleteOne(ctx, bson.M{"_id": lockID, "owner": l.owner})
+	done(err)
+	if err != nil {
+		return fmt.Errorf("failed to unlock migrations: %w", err)
+	}
+
+	return nil
+}
+
+// Indexes is a migration creating models on collection. Down drops them by
+// name, so every model must set one.
+func Indexes(version int, name string, collection *mongo.Collection, models ...mongo.IndexModel) Migration {
+	names := make([]string, len(models))
+	for i, model := range models {
+		if model.Options != nil && model.Options.Name != nil {
+			names[i] = *model.Options.Name
+		}
+	}
+
+	return Migration{
+		Version: version,
+		Name:    name,
+		Up: func(ctx context.Context) error {
+			for _, index := range names {
+				if index == "" {
+					return errors.New("every index needs a name")
+				}
+			}
+
+			done := metrics.Mongo(collection.Name(), "create_indexes")
+			_, err := collection.Indexes().CreateMany(ctx, models)
+			done(err)
+			return err
+		},
+		Down: func(ctx context.Context) error {
+			for _, index := range names {
+				done := metrics.Mongo(collection.Name(), "drop_index")
+				_, err := collection.Indexes().DropOne(ctx, index)
+				done(err)
+				if err != nil {
+					return fmt.Errorf("failed to drop index %s: %w", index, err)
+				}
+			}
+
+			return nil
+		},
+	}
+}
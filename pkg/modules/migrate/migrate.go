@@ -0,0 +1,217 @@
+package migrate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"project-wraith/pkg/modules/metrics"
+	"sort"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Migration is one versioned schema change. Down may be nil for changes that
+// cannot be reverted.
+type Migration struct {
+	Version int
+	Name    string
+	Up      func(ctx context.Context) error
+	Down    func(ctx context.Context) error
+}
+
+// Record marks an applied migration in the records collection.
+type Record struct {
+	Version   int       `bson:"_id"`
+	Name      string    `bson:"name"`
+	AppliedAt time.Time `bson:"applied_at"`
+}
+
+type Status struct {
+	Version   int
+	Name      string
+	Applied   bool
+	AppliedAt time.Time
+}
+
+type Migrator interface {
+	// Up applies the pending migrations in version order, stopping at the
+	// first failure. It returns ErrLocked while another runner holds the lock.
+	Up(ctx context.Context) ([]Migration, error)
+	// Down reverts the last steps applied migrations, newest first, under the
+	// same lock as Up.
+	Down(ctx context.Context, steps int) ([]Migration, error)
+	Status(ctx context.Context) ([]Status, error)
+}
+
+type migrator struct {
+	records    *mongo.Collection
+	lock       *lock
+	migrations []Migration
+}
+
+// NewMigrator is a function constructor for Migrator. migrations must have
+// distinct positive versions; they are run in version order. The lock lives in
+// locks and expires after lockTTL unless it is released, or extended after
+// each migration.
+func NewMigrator(records mongo.Collection, locks mongo.Collection, lockTTL time.Duration, migrations []Migration) (Migrator, error) {
+	if lockTTL <= 0 {
+		return nil, errors.New("lock ttl must be positive")
+	}
+
+	sorted := append([]Migration(nil), migrations...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Version < sorted[j].Version })
+
+	for i, migration := range sorted {
+		if migration.Version <= 0 {
+			return nil, fmt.Errorf("migration %q: version must be positive", migration.Name)
+		}
+		if i > 0 && sorted[i-1].Version == migration.Version {
+			return nil, fmt.Errorf("migration %d: version used twice", migration.Version)
+		}
+		if migration.Up == nil {
+			return nil, fmt.Errorf("migration %d: no up step", migration.Version)
+		}
+	}
+
+	lock, err := newLock(&locks, lockTTL)
+	if err != nil {
+		return nil, err
+	}
+
+	return &migrator{records: &records, lock: lock, migrations: sorted}, nil
+}
+
+func (m *migrator) Up(ctx context.Context) (done []Migration, err error) {
+	if err := m.lock.acquire(ctx); err != nil {
+		return nil, err
+	}
+	defer func() { err = errors.Join(err, m.lock.release(ctx)) }()
+
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, migration := range m.migrations {
+		if _, ok := applied[migration.Version]; ok {
+			continue
+		}
+
+		if err := migration.Up(ctx); err != nil {
+			return done, fmt.Errorf("migration %d %s: %w", migration.Version, migration.Name, err)
+		}
+
+		record := Record{Version: migration.Version, Name: migration.Name, AppliedAt: time.Now().UTC()}
+		finish := metrics.Mongo(m.records.Name(), "record")
+		_, err := m.records.InsertOne(ctx, record)
+		finish(err)
+		if err != nil {
+			return done, fmt.Errorf("migration %d %s applied but not recorded: %w", migration.Version, migration.Name, err)
+		}
+
+		done = append(done, migration)
+		if err := m.lock.acquire(ctx); err != nil {
+			return done, err
+		}
+	}
+
+	return done, nil
+}
+
+func (m *migrator) Down(ctx context.Context, steps int) (done []Migration, err error) {
+	if err := m.lock.acquire(ctx); err != nil {
+		return nil, err
+	}
+	defer func() { err = errors.Join(err, m.lock.release(ctx)) }()
+
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := len(m.migrations) - 1; i >= 0 && len(done) < steps; i-- {
+		migration := m.migrations[i]
+		if _, ok := applied[migration.Version]; !ok {
+			continue
+		}
+
+		if migration.Down == nil {
+			return done, fmt.Errorf("migration %d %s cannot be reverted", migration.Version, migration.Name)
+		}
+
+		if err := migration.Down(ctx); err != nil {
+			return done, fmt.Errorf("migration %d %s: %w", migration.Version, migration.Name, err)
+		}
+
+		finish := metrics.Mongo(m.records.Name(), "unrecord")
+		_, err := m.records.DeleteOne(ctx, bson.M{"_id": migration.Version})
+		finish(err)
+		if err != nil {
+			return done, fmt.Errorf("migration %d %s reverted but still recorded: %w", migration.Version, migration.Name, err)
+		}
+
+		done = append(done, migration)
+		if err := m.lock.acquire(ctx); err != nil {
+			return done, err
+		}
+	}
+
+	return done, nil
+}
+
+// Status lists every known migration, then the applied ones this build does
+// not know, such as those of a newer release.
+func (m *migrator) Status(ctx context.Context) ([]Status, error) {
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]Status, 0, len(m.migrations))
+	for _, migration := range m.migrations {
+		status := Status{Version: migration.Version, Name: migration.Name}
+		if record, ok := applied[migration.Version]; ok {
+			status.Applied = true
+			status.AppliedAt = record.AppliedAt
+			delete(applied, migration.Version)
+		}
+		statuses = append(statuses, status)
+	}
+
+	var unknown []Status
+	for _, record := range applied {
+		unknown = append(unknown, Status{
+			Version:   record.Version,
+			Name:      record.Name,
+			Applied:   true,
+			AppliedAt: record.AppliedAt,
+		})
+	}
+	sort.Slice(unknown, func(i, j int) bool { return unknown[i].Version < unknown[j].Version })
+
+	return append(statuses, unknown...), nil
+}
+
+func (m *migrator) applied(ctx context.Context) (map[int]Record, error) {
+	finish := metrics.Mongo(m.records.Name(), "applied")
+	cursor, err := m.records.Find(ctx, bson.M{}, options.Find().SetSort(bson.M{"_id": 1}))
+	finish(err)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read applied migrations: %w", err)
+	}
+
+	var records []Record
+	if err := cursor.All(ctx, &records); err != nil {
+		return nil, fmt.Errorf("failed to read applied migrations: %w", err)
+	}
+
+	applied := make(map[int]Record, len(records))
+	for _, record := range records {
+		applied[record.Version] = record
+	}
+
+	return applied, nil
+}
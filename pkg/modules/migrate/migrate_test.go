@@ -0,0 +1,203 @@
+package migrate_test
+
+import (
+	"context"
+	"errors"
+	"project-wraith/pkg/modules/migrate"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+func TestNewMigrator(test *testing.T) {
+	up := func(ctx context.Context) error { return nil }
+
+	tests := []struct {
+		name          string
+		migrations    []migrate.Migration
+		expectedError string
+	}{
+		{
+			name:       "Test NewMigrator - Valid",
+			migrations: []migrate.Migration{{Version: 2, Name: "b", Up: up}, {Version: 1, Name: "a", Up: up}},
+		},
+		{
+			name:          "Test NewMigrator - Duplicated version",
+			migrations:    []migrate.Migration{{Version: 1, Name: "a", Up: up}, {Version: 1, Name: "b", Up: up}},
+			expectedError: "migration 1: version used twice",
+		},
+		{
+			name:          "Test NewMigrator - No version",
+			migrations:    []migrate.Migration{{Name: "a", Up: up}},
+			expectedError: `migration "a": version must be positive`,
+		},
+		{
+			name:          "Test NewMigrator - No up step",
+			migrations:    []migrate.Migration{{Version: 1, Name: "a"}},
+			expectedError: "migration 1: no up step",
+		},
+	}
+
+	mt := mtest.New(test, mtest.NewOptions().ClientType(mtest.Mock))
+
+	for _, tc := range tests {
+		mt.Run(tc.name, func(mongoTest *mtest.T) {
+			_, err := migrate.NewMigrator(*mongoTest.Coll, *mongoTest.Coll, time.Minute, tc.migrations)
+			if tc.expectedError != "" {
+				assert.EqualError(mongoTest, err, tc.expectedError)
+			} else {
+				assert.NoError(mongoTest, err)
+			}
+		})
+	}
+}
+
+func TestMigrator(test *testing.T) {
+	mt := mtest.New(test, mtest.NewOptions().ClientType(mtest.Mock))
+
+	applied := func(mongoTest *mtest.T, versions ...int) {
+		var docs []bson.D
+		for _, version := range versions {
+			docs = append(docs, bson.D{{Key: "_id", Value: version}, {Key: "name", Value: "applied"}})
+		}
+		mongoTest.AddMockResponses(mtest.CreateCursorResponse(0, "db.schema_migrations", mtest.FirstBatch, docs...))
+	}
+
+	mt.Run("Test Up - Pending only, stops at failure", func(mongoTest *mtest.T) {
+		var ran []int
+		step := func(version int, err error) func(ctx context.Context) error {
+			return func(ctx context.Context) error {
+				ran = append(ran, version)
+				return err
+			}
+		}
+
+		migrator, err := migrate.NewMigrator(*mongoTest.Coll, *mongoTest.Coll, time.Minute, []migrate.Migration{
+			{Version: 1, Name: "first", Up: step(1, nil)},
+			{Version: 2, Name: "second", Up: step(2, nil)},
+			{Version: 3, Name: "third", Up: step(3, errors.New("boom"))},
+			{Version: 4, Name: "fourth", Up: step(4, nil)},
+		})
+		require.NoError(mongoTest, err)
+
+		mongoTest.AddMockResponses(mtest.CreateSuccessResponse())
+		applied(mongoTest, 1)
+		mongoTest.AddMockResponses(
+			mtest.CreateSuccessResponse(),
+			mtest.CreateSuccessResponse(),
+			mtest.CreateSuccessResponse(),
+		)
+
+		done, err := migrator.Up(context.Background())
+		assert.EqualError(mongoTest, err, "migration 3 third: boom")
+		assert.Equal(mongoTest, []int{2, 3}, ran)
+		require.Len(mongoTest, done, 1)
+		assert.Equal(mongoTest, 2, done[0].Version)
+	})
+
+	mt.Run("Test Down - Newest first, irreversible", func(mongoTest *mtest.T) {
+		var reverted []int
+		down := func(version int) func(ctx context.Context) error {
+			return func(ctx context.Context) error {
+				reverted = append(reverted, version)
+				return nil
+			}
+		}
+		up := func(ctx context.Context) error { return nil }
+
+		migrator, err := migrate.NewMigrator(*mongoTest.Coll, *mongoTest.Coll, time.Minute, []migrate.Migration{
+			{Version: 1, Name: "first", Up: up},
+			{Version: 2, Name: "second", Up: up, Down: down(2)},
+			{Version: 3, Name: "third", Up: up, Down: down(3)},
+		})
+		require.NoError(mongoTest, err)
+
+		mongoTest.AddMockResponses(mtest.CreateSuccessResponse())
+		applied(mongoTest, 1, 2, 3)
+		mongoTest.AddMockResponses(
+			mtest.CreateSuccessResponse(bson.E{Key: "n", Value: int32(1)}),
+			mtest.CreateSuccessResponse(),
+			mtest.CreateSuccessResponse(bson.E{Key: "n", Value: int32(1)}),
+			mtest.CreateSuccessResponse(),
+			mtest.CreateSuccessResponse(),
+		)
+
+		done, err := migrator.Down(context.Background(), 5)
+		assert.EqualError(mongoTest, err, "migration 1 first cannot be reverted")
+		assert.Equal(mongoTest, []int{3, 2}, reverted)
+		assert.Len(mongoTest, done, 2)
+	})
+
+	mt.Run("Test Up - Locked by another runner", func(mongoTest *mtest.T) {
+		ran := false
+		migrator, err := migrate.NewMigrator(*mongoTest.Coll, *mongoTest.Coll, time.Minute, []migrate.Migration{
+			{Version: 1, Name: "first", Up: func(ctx context.Context) error {
+				ran = true
+				return nil
+			}},
+		})
+		require.NoError(mongoTest, err)
+
+		mongoTest.AddMockResponses(mtest.CreateWriteErrorsResponse(mtest.WriteError{
+			Index: 0, Code: 11000, Message: "E11000 duplicate key error",
+		}))
+
+		_, err = migrator.Up(context.Background())
+		assert.ErrorIs(mongoTest, err, migrate.ErrLocked)
+		assert.False(mongoTest, ran)
+	})
+
+	mt.Run("Test Status", func(mongoTest *mtest.T) {
+		up := func(ctx context.Context) error { return nil }
+		migrator, err := migrate.NewMigrator(*mongoTest.Coll, *mongoTest.Coll, time.Minute, []migrate.Migration{
+			{Version: 1, Name: "first", Up: up},
+			{Version: 2, Name: "second", Up: up},
+		})
+		require.NoError(mongoTest, err)
+
+		applied(mongoTest, 1, 7)
+
+		statuses, err := migrator.Status(context.Background())
+		require.NoError(mongoTest, err)
+		require.Len(mongoTest, statuses, 3)
+		assert.True(mongoTest, statuses[0].Applied)
+		assert.False(mongoTest, statuses[1].Applied)
+		assert.Equal(mongoTest, 7, statuses[2].Version)
+		assert.True(mongoTest, statuses[2].Applied)
+	})
+}
+
+func TestIndexes(test *testing.T) {
+	mt := mtest.New(test, mtest.NewOptions().ClientType(mtest.Mock))
+
+	mt.Run("Test Indexes - Create and drop by name", func(mongoTest *mtest.T) {
+		migration := migrate.Indexes(1, "users", mongoTest.Coll, mongo.IndexModel{
+			Keys:    bson.D{{Key: "username", Value: 1}},
+			Options: options.Index().SetName("users_username_unique").SetUnique(true),
+		})
+
+		mongoTest.AddMockResponses(mtest.CreateSuccessResponse(), mtest.CreateSuccessResponse())
+		require.NoError(mongoTest, migration.Up(context.Background()))
+		require.NoError(mongoTest, migration.Down(context.Background()))
+
+		mongoTest.GetStartedEvent()
+		dropped := mongoTest.GetStartedEvent()
+		require.NotNil(mongoTest, dropped)
+		assert.Equal(mongoTest, "dropIndexes", dropped.CommandName)
+		assert.Equal(mongoTest, "users_username_unique", dropped.Command.Lookup("index").StringValue())
+	})
+
+	mt.Run("Test Indexes - Unnamed index", func(mongoTest *mtest.T) {
+		migration := migrate.Indexes(1, "users", mongoTest.Coll, mongo.IndexModel{
+			Keys: bson.D{{Key: "username", Value: 1}},
+		})
+
+		assert.EqualError(mongoTest, migration.Up(context.Background()), "every index needs a name")
+	})
+}
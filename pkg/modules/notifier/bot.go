@@ -1,31 +1,67 @@
 package notifier
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
 	"project-wraith/pkg/modules/req"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	DefaultTelegramURL = "https://api.telegram.org"
+	maxTelegramLength  = 4000 // Under Telegram's 4096 characters per message
 )
 
 type TelegramBot struct {
+	baseURL  string
 	botToken string
 	chatID   string
 }
 
-func NewTelegramBot(botToken string, chatID string) TelegramBot {
+// NewTelegramBot is a function constructor for TelegramBot. An empty baseURL
+// means DefaultTelegramURL.
+func NewTelegramBot(baseURL, botToken, chatID string) TelegramBot {
+	if baseURL == "" {
+		baseURL = DefaultTelegramURL
+	}
+
 	return TelegramBot{
+		baseURL:  strings.TrimSuffix(baseURL, "/"),
 		botToken: botToken,
 		chatID:   chatID,
 	}
 }
 
+func (tb TelegramBot) Notify(msg Message) error {
+	_, err := tb.SendChatNotification(msg.Text())
+	return err
+}
+
 func (tb TelegramBot) SendChatNotification(text string) (string, error) {
+	return tb.sendMessage(tb.chatID, text)
+}
+
+// SendMessage sends text to a chat other than the notifications one.
+func (tb TelegramBot) SendMessage(chatID int64, text string) error {
+	_, err := tb.sendMessage(strconv.FormatInt(chatID, 10), text)
+	return err
+}
+
+func (tb TelegramBot) sendMessage(chatID, text string) (string, error) {
+	if runes := []rune(text); len(runes) > maxTelegramLength {
+		text = string(runes[:maxTelegramLength]) + "…"
+	}
+
 	message := map[string]string{
-		"chat_id": tb.chatID,
+		"chat_id": chatID,
 		"text":    text,
 	}
 
-	apiURL := fmt.Sprintf("https://api.telegram.org/bot%s/sendMessage", tb.botToken)
+	apiURL := fmt.Sprintf("%s/bot%s/sendMessage", tb.baseURL, tb.botToken)
 
 	jsonData, err := json.Marshal(message)
 	if err != nil {
@@ -40,10 +76,67 @@ func (tb TelegramBot) SendChatNotification(text string) (string, error) {
 			"Content-Type": "application/json",
 		},
 	}
-	res, err := req.SendRequest(content)
+	res, err := req.Send(context.Background(), content)
+	if err != nil {
+		return "", fmt.Errorf("failed to send request: %w", withoutURL(err))
+	}
+	if res.StatusCode != http.StatusOK {
+		return res.Body, fmt.Errorf("telegram answered %d: %s", res.StatusCode, res.Body)
+	}
+
+	return res.Body, nil
+}
+
+// Update is the part of a Telegram update the ops bot reads.
+type Update struct {
+	UpdateID int64 `json:"update_id"`
+	Message  *struct {
+		From *struct {
+			ID       int64  `json:"id"`
+			Username string `json:"username"`
+		} `json:"from"`
+		Chat struct {
+			ID int64 `json:"id"`
+		} `json:"chat"`
+		Text string `json:"text"`
+	} `json:"message"`
+}
+
+type updatesResponse struct {
+	Ok          bool     `json:"ok"`
+	Description string   `json:"description"`
+	Result      []Update `json:"result"`
+}
+
+// GetUpdates long polls for the messages after offset, waiting up to timeout
+// for one to arrive or until ctx is done.
+func (tb TelegramBot) GetUpdates(ctx context.Context, offset int64, timeout time.Duration) ([]Update, error) {
+	query, err := json.Marshal(map[string]interface{}{
+		"offset":          offset,
+		"timeout":         int(timeout.Seconds()),
+		"allowed_updates": []string{"message"},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal query: %w", err)
+	}
+
+	res, err := req.Send(ctx, req.HTTPRequest{
+		Method:  http.MethodPost,
+		URL:     fmt.Sprintf("%s/bot%s/getUpdates", tb.baseURL, tb.botToken),
+		Body:    query,
+		Headers: map[string]string{"Content-Type": "application/json"},
+	})
 	if err != nil {
-		return "", fmt.Errorf("failed to send request: %w", err)
+		return nil, fmt.Errorf("failed to send request: %w", withoutURL(err))
+	}
+
+	var updates updatesResponse
+	if err := json.Unmarshal([]byte(res.Body), &updates); err != nil {
+		return nil, fmt.Errorf("telegram answered %d: %w", res.StatusCode, err)
+	}
+	if !updates.Ok {
+		return nil, fmt.Errorf("telegram answered %d: %s", res.StatusCode, updates.Description)
 	}
 
-	return res, nil
+	return updates.Result, nil
 }
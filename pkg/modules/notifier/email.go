@@ -0,0 +1,32 @@
+package notifier
+
+import (
+	"context"
+	"fmt"
+	"project-wraith/pkg/modules/mail"
+	"strings"
+)
+
+type email struct {
+	mailer   mail.Mail
+	template string
+	to       []string
+}
+
+// NewEmail is a function constructor for a Notifier mailing messages to to,
+// rendered with the html template at template.
+func NewEmail(mailer mail.Mail, template string, to []string) Notifier {
+	return &email{mailer: mailer, template: template, to: to}
+}
+
+func (e *email) Notify(msg Message) error {
+	// The subject goes into a mail header as is.
+	subject := strings.NewReplacer("\r", " ", "\n", " ").Replace(fmt.Sprintf("[%s] %s", msg.Severity, msg.Title))
+
+	err := e.mailer.Send(context.Background(), e.template, msg, subject, e.to)
+	if err != nil {
+		return fmt.Errorf("failed to send mail: %w", err)
+	}
+
+	return nil
+}
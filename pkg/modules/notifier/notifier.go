@@ -0,0 +1,155 @@
+package notifier
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+type Severity int
+
+const (
+	SeverityInfo Severity = iota
+	SeverityWarning
+	SeverityError
+	SeverityCritical
+)
+
+var severityNames = []string{"info", "warning", "error", "critical"}
+
+func (s Severity) String() string {
+	if s < SeverityInfo || s > SeverityCritical {
+		return fmt.Sprintf("severity(%d)", int(s))
+	}
+
+	return severityNames[s]
+}
+
+func (s Severity) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// ParseSeverity reads a severity name, ignoring case; "warn" is accepted for
+// warning.
+func ParseSeverity(name string) (Severity, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == "warn" {
+		return SeverityWarning, nil
+	}
+
+	for i, known := range severityNames {
+		if name == known {
+			return Severity(i), nil
+		}
+	}
+
+	return SeverityInfo, fmt.Errorf("unknown severity %q", name)
+}
+
+// Categories used by the server; routes may name any others.
+const (
+	CategoryErrors = "errors"
+	CategoryOps    = "ops"
+)
+
+type Message struct {
+	Severity Severity
+	Category string
+	Title    string
+	Body     string
+	Fields   map[string]string
+}
+
+// Keys returns the field names sorted, so every channel renders them in the
+// same order.
+func (m Message) Keys() []string {
+	keys := make([]string, 0, len(m.Fields))
+	for key := range m.Fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
+// Text renders the message as plain text for chat channels.
+func (m Message) Text() string {
+	var text strings.Builder
+	fmt.Fprintf(&text, "[%s] %s", strings.ToUpper(m.Severity.String()), m.Title)
+	if m.Body != "" {
+		fmt.Fprintf(&text, "\n%s", m.Body)
+	}
+	for _, key := range m.Keys() {
+		fmt.Fprintf(&text, "\n%s: %s", key, m.Fields[key])
+	}
+
+	return text.String()
+}
+
+type Notifier interface {
+	Notify(msg Message) error
+}
+
+// Route sends the messages at Severity or above to Notifier. Empty
+// Categories match every category.
+type Route struct {
+	Name       string
+	Notifier   Notifier
+	Severity   Severity
+	Categories []string
+}
+
+func (r Route) matches(msg Message) bool {
+	if msg.Severity < r.Severity {
+		return false
+	}
+	if len(r.Categories) == 0 {
+		return true
+	}
+
+	for _, category := range r.Categories {
+		if strings.EqualFold(category, msg.Category) {
+			return true
+		}
+	}
+
+	return false
+}
+
+type router struct {
+	routes []Route
+}
+
+// NewRouter is a function constructor for a Notifier that hands every
+// message to each matching route. A failing route does not stop the others.
+func NewRouter(routes ...Route) Notifier {
+	return &router{routes: routes}
+}
+
+func (r *router) Notify(msg Message) error {
+	var errs []error
+	for _, route := range r.routes {
+		if !route.matches(msg) {
+			continue
+		}
+
+		if err := route.Notifier.Notify(msg); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", route.Name, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// withoutURL drops the request URL from transport errors, as bot tokens and
+// webhook URLs are secrets that must not end up in the logs.
+func withoutURL(err error) error {
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		return urlErr.Err
+	}
+
+	return err
+}
@@ -0,0 +1,30 @@
+package notifier
+
+import (
+	"context"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type MockNotifier struct {
+	mock.Mock
+}
+
+func (m *MockNotifier) Notify(msg Message) error {
+	args := m.Called(msg)
+	return args.Error(0)
+}
+
+type MockOffsetStore struct {
+	mock.Mock
+}
+
+func (m *MockOffsetStore) Load(ctx context.Context) (int64, error) {
+	args := m.Called(ctx)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockOffsetStore) Save(ctx context.Context, offset int64) error {
+	args := m.Called(ctx, offset)
+	return args.Error(0)
+}
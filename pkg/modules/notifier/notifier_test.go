@@ -0,0 +1,191 @@
+package notifier
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"project-wraith/pkg/modules/mail"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type captured struct {
+	path    string
+	headers http.Header
+	body    []byte
+}
+
+func capture(t *testing.T, status int) (*httptest.Server, *captured) {
+	c := &captured{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+
+		c.path = r.URL.Path
+		c.headers = r.Header
+		c.body = body
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(server.Close)
+
+	return server, c
+}
+
+var sample = Message{
+	Severity: SeverityError,
+	Category: CategoryErrors,
+	Title:    "insert failed",
+	Body:     "at user.go:52",
+	Fields:   map[string]string{"requestId": "r1", "fingerprint": "abc"},
+}
+
+func TestRouter(t *testing.T) {
+	tests := []struct {
+		name           string
+		msg            Message
+		expectedRoutes []string
+	}{
+		{
+			name:           "Severity below every route",
+			msg:            Message{Severity: SeverityInfo, Category: CategoryErrors},
+			expectedRoutes: nil,
+		},
+		{
+			name:           "Error in errors",
+			msg:            Message{Severity: SeverityError, Category: CategoryErrors},
+			expectedRoutes: []string{"chat", "pager"},
+		},
+		{
+			name:           "Critical ops",
+			msg:            Message{Severity: SeverityCritical, Category: CategoryOps},
+			expectedRoutes: []string{"chat", "ops"},
+		},
+		{
+			name:           "Warning ops",
+			msg:            Message{Severity: SeverityWarning, Category: CategoryOps},
+			expectedRoutes: []string{"chat"},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var notified []string
+			route := func(name string, severity Severity, categories ...string) Route {
+				m := &MockNotifier{}
+				m.On("Notify", mock.Anything).Run(func(mock.Arguments) {
+					notified = append(notified, name)
+				}).Return(nil)
+				return Route{Name: name, Notifier: m, Severity: severity, Categories: categories}
+			}
+
+			router := NewRouter(
+				route("chat", SeverityWarning),
+				route("pager", SeverityError, "ERRORS"),
+				route("ops", SeverityCritical, CategoryOps),
+			)
+
+			require.NoError(t, router.Notify(tc.msg))
+			assert.Equal(t, tc.expectedRoutes, notified)
+		})
+	}
+
+	t.Run("Failing route", func(t *testing.T) {
+		failing := &MockNotifier{}
+		failing.On("Notify", mock.Anything).Return(errors.New("down"))
+		working := &MockNotifier{}
+		working.On("Notify", mock.Anything).Return(nil)
+
+		err := NewRouter(Route{Name: "slack", Notifier: failing}, Route{Name: "mail", Notifier: working}).Notify(sample)
+		assert.EqualError(t, err, "slack: down")
+		working.AssertNumberOfCalls(t, "Notify", 1)
+	})
+}
+
+func TestParseSeverity(t *testing.T) {
+	for name, expected := range map[string]Severity{"info": SeverityInfo, "WARN": SeverityWarning, "warning": SeverityWarning, " error ": SeverityError, "critical": SeverityCritical} {
+		severity, err := ParseSeverity(name)
+		require.NoError(t, err)
+		assert.Equal(t, expected, severity)
+	}
+
+	_, err := ParseSeverity("loud")
+	assert.Error(t, err)
+}
+
+func TestTelegram(t *testing.T) {
+	server, c := capture(t, http.StatusOK)
+
+	require.NoError(t, NewTelegramBot(server.URL+"/", "token", "chat").Notify(sample))
+	assert.Equal(t, "/bottoken/sendMessage", c.path)
+
+	var payload map[string]string
+	require.NoError(t, json.Unmarshal(c.body, &payload))
+	assert.Equal(t, "chat", payload["chat_id"])
+	assert.Equal(t, "[ERROR] insert failed\nat user.go:52\nfingerprint: abc\nrequestId: r1", payload["text"])
+
+	rejecting, _ := capture(t, http.StatusUnauthorized)
+	err := NewTelegramBot(rejecting.URL, "token", "chat").Notify(sample)
+	assert.ErrorContains(t, err, "401")
+
+	err = NewTelegramBot("http://127.0.0.1:1", "secret-token", "chat").Notify(sample)
+	require.Error(t, err)
+	assert.NotContains(t, err.Error(), "secret-token")
+}
+
+func TestSlackWebhook(t *testing.T) {
+	server, c := capture(t, http.StatusOK)
+
+	require.NoError(t, NewSlackWebhook(server.URL+"/services/T/B/X").Notify(sample))
+	assert.Equal(t, "/services/T/B/X", c.path)
+
+	var payload slackPayload
+	require.NoError(t, json.Unmarshal(c.body, &payload))
+	assert.Equal(t, "*[error] insert failed*", payload.Text)
+	require.Len(t, payload.Attachments, 1)
+	assert.Equal(t, "danger", payload.Attachments[0].Color)
+	assert.Equal(t, []slackField{{Title: "fingerprint", Value: "abc", Short: true}, {Title: "requestId", Value: "r1", Short: true}}, payload.Attachments[0].Fields)
+
+	rejecting, _ := capture(t, http.StatusNotFound)
+	assert.Error(t, NewSlackWebhook(rejecting.URL).Notify(sample))
+}
+
+func TestWebhook(t *testing.T) {
+	server, c := capture(t, http.StatusAccepted)
+
+	require.NoError(t, NewWebhook(server.URL, "shh").Notify(sample))
+
+	timestamp := c.headers.Get(TimestampHeader)
+	require.NotEmpty(t, timestamp)
+	assert.Equal(t, Sign("shh", timestamp, c.body), c.headers.Get(SignatureHeader))
+	assert.NotEqual(t, Sign("other", timestamp, c.body), c.headers.Get(SignatureHeader))
+
+	var payload map[string]interface{}
+	require.NoError(t, json.Unmarshal(c.body, &payload))
+	assert.Equal(t, "error", payload["severity"])
+	assert.Equal(t, "errors", payload["category"])
+	assert.Equal(t, "insert failed", payload["title"])
+
+	unsigned, c := capture(t, http.StatusOK)
+	require.NoError(t, NewWebhook(unsigned.URL, "").Notify(sample))
+	assert.Empty(t, c.headers.Get(SignatureHeader))
+
+	rejecting, _ := capture(t, http.StatusInternalServerError)
+	assert.Error(t, NewWebhook(rejecting.URL, "shh").Notify(sample))
+}
+
+func TestEmail(t *testing.T) {
+	mailMock := &mail.MockMail{}
+	mailMock.On("Send", mock.Anything, "notification.html", sample, "[error] insert failed", []string{"ops@example.com"}).Return(nil)
+
+	require.NoError(t, NewEmail(mailMock, "notification.html", []string{"ops@example.com"}).Notify(sample))
+
+	injected := sample
+	injected.Title = "bad\r\nBcc: someone@example.com"
+	mailMock.On("Send", mock.Anything, "notification.html", injected, "[error] bad  Bcc: someone@example.com", []string{"ops@example.com"}).Return(errors.New("smtp down"))
+	assert.ErrorContains(t, NewEmail(mailMock, "notification.html", []string{"ops@example.com"}).Notify(injected), "smtp down")
+}
@@ -0,0 +1,202 @@
+package notifier
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"project-wraith/pkg/modules/logger"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Command answers an ops bot command; args are the words that followed it.
+type Command func(args []string) (string, error)
+
+// OpsBot answers commands sent to the Telegram bot from the allowed chats.
+// Every command, allowed or not, is audit logged.
+type OpsBot interface {
+	Handle(name, usage string, command Command)
+	// Run long polls for commands until stop is closed.
+	Run(stop <-chan struct{})
+}
+
+// OffsetStore keeps the offset of the next update across restarts. Telegram
+// only forgets a batch once a later poll passes a higher offset, so without it
+// the last batch of commands would run again after a restart.
+type OffsetStore interface {
+	Load(ctx context.Context) (int64, error)
+	Save(ctx context.Context, offset int64) error
+}
+
+const opsRetryDelay = 5 * time.Second
+
+type opsCommand struct {
+	usage string
+	run   Command
+}
+
+type opsBot struct {
+	log      logger.Logger
+	bot      TelegramBot
+	allowed  map[int64]bool
+	timeout  time.Duration
+	commands map[string]opsCommand
+	offsets  OffsetStore
+	offset   int64
+}
+
+// NewOpsBot is a function constructor for OpsBot. Commands from chats not in
+// allowedChats are logged and ignored, without an answer. The offset of every
+// batch is saved to offsets before its commands run, so each runs at most once.
+func NewOpsBot(log logger.Logger, bot TelegramBot, allowedChats []int64, pollTimeout time.Duration, offsets OffsetStore) OpsBot {
+	allowed := make(map[int64]bool, len(allowedChats))
+	for _, chat := range allowedChats {
+		allowed[chat] = true
+	}
+
+	return &opsBot{
+		log:      log,
+		bot:      bot,
+		allowed:  allowed,
+		timeout:  pollTimeout,
+		commands: make(map[string]opsCommand),
+		offsets:  offsets,
+	}
+}
+
+func (ob *opsBot) Handle(name, usage string, command Command) {
+	ob.commands[strings.TrimPrefix(name, "/")] = opsCommand{usage: usage, run: command}
+}
+
+func (ob *opsBot) Run(stop <-chan struct{}) {
+	// The poll is canceled as soon as stop closes, instead of finishing its
+	// long wait first.
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		select {
+		case <-stop:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	for {
+		offset, err := ob.offsets.Load(ctx)
+		if err == nil {
+			ob.offset = offset
+			break
+		}
+		if errors.Is(err, context.Canceled) || !ob.wait(ctx, "ops bot failed to load its offset: %v", err) {
+			return
+		}
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		default:
+		}
+
+		updates, err := ob.bot.GetUpdates(ctx, ob.offset, ob.timeout)
+		if errors.Is(err, context.Canceled) {
+			return
+		}
+		if err != nil {
+			if !ob.wait(ctx, "ops bot failed to get updates: %v", err) {
+				return
+			}
+			continue
+		}
+		if len(updates) == 0 {
+			continue
+		}
+
+		// The batch is confirmed before it runs; a batch that cannot be
+		// confirmed is polled again instead.
+		next := updates[len(updates)-1].UpdateID + 1
+		if err := ob.offsets.Save(ctx, next); err != nil {
+			if errors.Is(err, context.Canceled) || !ob.wait(ctx, "ops bot failed to save its offset: %v", err) {
+				return
+			}
+			continue
+		}
+		ob.offset = next
+
+		for _, update := range updates {
+			ob.handle(update)
+		}
+	}
+}
+
+// wait logs err and waits opsRetryDelay. It returns false when ctx ends first.
+func (ob *opsBot) wait(ctx context.Context, message string, err error) bool {
+	ob.log.Warn(message, err)
+
+	select {
+	case <-ctx.Done():
+		return false
+	case <-time.After(opsRetryDelay):
+		return true
+	}
+}
+
+func (ob *opsBot) handle(update Update) {
+	msg := update.Message
+	if msg == nil || !strings.HasPrefix(msg.Text, "/") {
+		return
+	}
+
+	words := strings.Fields(msg.Text)
+	// In groups commands may be addressed as /status@bot_name.
+	name, _, _ := strings.Cut(strings.TrimPrefix(words[0], "/"), "@")
+	args := words[1:]
+
+	audit := []interface{}{"chat", msg.Chat.ID, "command", name, "args", strings.Join(args, " ")}
+	if msg.From != nil {
+		audit = append(audit, "user", msg.From.ID, "username", msg.From.Username)
+	}
+
+	if !ob.allowed[msg.Chat.ID] {
+		ob.log.Warnw("ops bot: command denied", audit...)
+		return
+	}
+
+	var answer string
+	var err error
+	if name == "help" {
+		answer = ob.help()
+	} else if command, ok := ob.commands[name]; ok {
+		answer, err = command.run(args)
+	} else {
+		answer = fmt.Sprintf("unknown command /%s, try /help", name)
+	}
+
+	if err != nil {
+		ob.log.Warnw("ops bot: command failed", append(audit, "error", err)...)
+		answer = fmt.Sprintf("/%s failed: %v", name, err)
+	} else {
+		ob.log.Infow("ops bot: command done", audit...)
+	}
+
+	if err := ob.bot.SendMessage(msg.Chat.ID, answer); err != nil {
+		ob.log.Warn("ops bot failed to answer: %v", err)
+	}
+}
+
+func (ob *opsBot) help() string {
+	names := make([]string, 0, len(ob.commands))
+	for name := range ob.commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	lines := []string{"/help"}
+	for _, name := range names {
+		lines = append(lines, strings.TrimSpace("/"+name+" "+ob.commands[name].usage))
+	}
+
+	return strings.Join(lines, "\n")
+}
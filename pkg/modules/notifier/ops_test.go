@@ -0,0 +1,219 @@
+package notifier
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"project-wraith/pkg/modules/logger"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type answer struct {
+	ChatID string `json:"chat_id"`
+	Text   string `json:"text"`
+}
+
+// telegramStub serves updates once, then empty polls, and collects the
+// answers sent back.
+func telegramStub(t *testing.T, updates string) (string, <-chan answer) {
+	answers := make(chan answer, 10)
+	var once sync.Once
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/getUpdates"):
+			result := "[]"
+			once.Do(func() { result = updates })
+			if result == "[]" {
+				time.Sleep(10 * time.Millisecond)
+			}
+			_, _ = w.Write([]byte(`{"ok":true,"result":` + result + `}`))
+		case strings.HasSuffix(r.URL.Path, "/sendMessage"):
+			var a answer
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&a))
+			answers <- a
+			_, _ = w.Write([]byte(`{"ok":true}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	return server.URL, answers
+}
+
+func TestOpsBot(t *testing.T) {
+	updates := `[
+		{"update_id": 1, "message": {"from": {"id": 7, "username": "mallory"}, "chat": {"id": 666}, "text": "/lock 123"}},
+		{"update_id": 2, "message": {"from": {"id": 8, "username": "ops"}, "chat": {"id": 42}, "text": "hello"}},
+		{"update_id": 3, "message": {"from": {"id": 8, "username": "ops"}, "chat": {"id": 42}, "text": "/lock@wraith_bot 123"}},
+		{"update_id": 4, "message": {"from": {"id": 8, "username": "ops"}, "chat": {"id": 42}, "text": "/unlock 404"}},
+		{"update_id": 5, "message": {"from": {"id": 8, "username": "ops"}, "chat": {"id": 42}, "text": "/nope"}},
+		{"update_id": 6, "message": {"from": {"id": 8, "username": "ops"}, "chat": {"id": 42}, "text": "/help"}}
+	]`
+	baseURL, answers := telegramStub(t, updates)
+
+	var mu sync.Mutex
+	var audit []string
+	logMock := &logger.MockLogger{}
+	for _, method := range []string{"Infow", "Warnw"} {
+		method := method
+		logMock.On(method, mock.Anything).Run(func(args mock.Arguments) {
+			mu.Lock()
+			defer mu.Unlock()
+			audit = append(audit, method+" "+args.String(0))
+		}).Return()
+	}
+
+	offsets := &MockOffsetStore{}
+	offsets.On("Load", mock.Anything).Return(int64(0), nil)
+	offsets.On("Save", mock.Anything, int64(7)).Return(nil)
+
+	ops := NewOpsBot(logMock, NewTelegramBot(baseURL, "token", ""), []int64{42}, time.Second, offsets)
+	ops.Handle("lock", "<id>", func(args []string) (string, error) {
+		return "locked " + strings.Join(args, ","), nil
+	})
+	ops.Handle("/unlock", "<id>", func(args []string) (string, error) {
+		return "", errors.New("user not found")
+	})
+
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		ops.Run(stop)
+		close(done)
+	}()
+
+	var got []answer
+	for len(got) < 4 {
+		select {
+		case a := <-answers:
+			got = append(got, a)
+		case <-time.After(2 * time.Second):
+			t.Fatalf("only %d answers", len(got))
+		}
+	}
+	close(stop)
+	<-done
+
+	assert.Equal(t, []answer{
+		{ChatID: "42", Text: "locked 123"},
+		{ChatID: "42", Text: "/unlock failed: user not found"},
+		{ChatID: "42", Text: "unknown command /nope, try /help"},
+		{ChatID: "42", Text: "/help\n/lock <id>\n/unlock <id>"},
+	}, got)
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, []string{
+		"Warnw ops bot: command denied",
+		"Infow ops bot: command done",
+		"Warnw ops bot: command failed",
+		"Infow ops bot: command done",
+		"Infow ops bot: command done",
+	}, audit)
+	offsets.AssertExpectations(t)
+}
+
+func TestOpsBotOffset(t *testing.T) {
+	var mu sync.Mutex
+	var polled []int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var query struct {
+			Offset int64 `json:"offset"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&query))
+		mu.Lock()
+		polled = append(polled, query.Offset)
+		mu.Unlock()
+
+		_, _ = w.Write([]byte(`{"ok":true,"result":[
+			{"update_id": 9, "message": {"chat": {"id": 42}, "text": "/lock 123"}}
+		]}`))
+	}))
+	t.Cleanup(server.Close)
+
+	logMock := &logger.MockLogger{}
+	logMock.On("Warn", mock.Anything).Return()
+
+	saved := make(chan struct{})
+	offsets := &MockOffsetStore{}
+	offsets.On("Load", mock.Anything).Return(int64(9), nil)
+	offsets.On("Save", mock.Anything, int64(10)).Run(func(mock.Arguments) {
+		close(saved)
+	}).Return(errors.New("no primary"))
+
+	ops := NewOpsBot(logMock, NewTelegramBot(server.URL, "token", ""), []int64{42}, time.Second, offsets)
+	ops.Handle("lock", "<id>", func(args []string) (string, error) {
+		t.Error("a command ran although its batch was not confirmed")
+		return "", nil
+	})
+
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		ops.Run(stop)
+		close(done)
+	}()
+
+	select {
+	case <-saved:
+	case <-time.After(2 * time.Second):
+		t.Fatal("the offset was never saved")
+	}
+	close(stop)
+	<-done
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, []int64{9}, polled)
+	logMock.AssertCalled(t, "Warn", "ops bot failed to save its offset: %v")
+}
+
+func TestOpsBotStopDuringPoll(t *testing.T) {
+	polling := make(chan struct{}, 1)
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		polling <- struct{}{}
+		select {
+		case <-r.Context().Done():
+		case <-release:
+		}
+	}))
+	t.Cleanup(server.Close)
+	t.Cleanup(func() { close(release) })
+
+	logMock := &logger.MockLogger{}
+	offsets := &MockOffsetStore{}
+	offsets.On("Load", mock.Anything).Return(int64(0), nil)
+	ops := NewOpsBot(logMock, NewTelegramBot(server.URL, "token", ""), []int64{42}, 30*time.Second, offsets)
+
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		ops.Run(stop)
+		close(done)
+	}()
+
+	select {
+	case <-polling:
+	case <-time.After(2 * time.Second):
+		t.Fatal("the bot never polled")
+	}
+	close(stop)
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Run did not return while a poll was in flight")
+	}
+	logMock.AssertNotCalled(t, "Warn", mock.Anything)
+}
@@ -0,0 +1,225 @@
+package notifier
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"project-wraith/pkg/modules/logger"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Relay forwards error log events to a notifier, in the errors category. The first occurrence of an
+// error is sent right away; repeats within the window are only counted and
+// reported together in the digest sent when the window closes.
+type Relay interface {
+	// Hook is the logger.Hook to register for the error level.
+	Hook(event logger.Event)
+	// Run sends the queued alerts and a digest every window until stop is
+	// closed, then sends the last digest.
+	Run(stop <-chan struct{})
+}
+
+const (
+	relayQueueSize = 64
+	maxTitleLength = 200
+	maxFieldLength = 200
+)
+
+var (
+	uuidPattern   = regexp.MustCompile(`[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}`)
+	hexPattern    = regexp.MustCompile(`\b(?:0x)?[0-9a-fA-F]{8,}\b`)
+	numberPattern = regexp.MustCompile(`\d+`)
+	quotedPattern = regexp.MustCompile(`"[^"]*"|'[^']*'`)
+)
+
+type tally struct {
+	caller  string
+	message string
+	count   int
+}
+
+type relay struct {
+	log      logger.Logger
+	notifier Notifier
+	window   time.Duration
+	queue    chan Message
+
+	mu      sync.Mutex
+	tallies map[string]*tally
+	dropped int
+}
+
+// NewRelay is a function constructor for Relay. log is only used for
+// warnings, so failed sends are never forwarded again.
+func NewRelay(log logger.Logger, notifier Notifier, window time.Duration) Relay {
+	return &relay{
+		log:      log,
+		notifier: notifier,
+		window:   window,
+		queue:    make(chan Message, relayQueueSize),
+		tallies:  make(map[string]*tally),
+	}
+}
+
+func (r *relay) Hook(event logger.Event) {
+	fingerprint := Fingerprint(event.Caller, event.Message)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if t, ok := r.tallies[fingerprint]; ok {
+		t.count++
+		return
+	}
+	r.tallies[fingerprint] = &tally{caller: event.Caller, message: event.Message, count: 1}
+
+	select {
+	case r.queue <- alert(fingerprint, event):
+	default:
+		r.dropped++
+	}
+}
+
+func (r *relay) Run(stop <-chan struct{}) {
+	ticker := time.NewTicker(r.window)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case msg := <-r.queue:
+			r.send(msg)
+		case <-ticker.C:
+			r.digest()
+		case <-stop:
+			for {
+				select {
+				case msg := <-r.queue:
+					r.send(msg)
+				default:
+					r.digest()
+					return
+				}
+			}
+		}
+	}
+}
+
+// digest reports the errors repeated since the last one and opens a new
+// window.
+func (r *relay) digest() {
+	r.mu.Lock()
+	tallies := r.tallies
+	dropped := r.dropped
+	r.tallies = make(map[string]*tally)
+	r.dropped = 0
+	r.mu.Unlock()
+
+	fingerprints := make([]string, 0, len(tallies))
+	for fingerprint, t := range tallies {
+		if t.count > 1 {
+			fingerprints = append(fingerprints, fingerprint)
+		}
+	}
+	if len(fingerprints) == 0 && dropped == 0 {
+		return
+	}
+
+	sort.Slice(fingerprints, func(i, j int) bool {
+		a, b := tallies[fingerprints[i]], tallies[fingerprints[j]]
+		if a.count != b.count {
+			return a.count > b.count
+		}
+		return fingerprints[i] < fingerprints[j]
+	})
+
+	var body strings.Builder
+	for i, fingerprint := range fingerprints {
+		if i > 0 {
+			body.WriteString("\n")
+		}
+		t := tallies[fingerprint]
+		fmt.Fprintf(&body, "[%s] %q at %s occurred %d times in %s", fingerprint, t.message, t.caller, t.count, span(r.window))
+	}
+	if dropped > 0 {
+		fmt.Fprintf(&body, "\n\n%d alerts were dropped while the notifier was busy", dropped)
+	}
+
+	r.send(Message{
+		Severity: SeverityError,
+		Category: CategoryErrors,
+		Title:    fmt.Sprintf("error digest for the last %s", span(r.window)),
+		Body:     body.String(),
+	})
+}
+
+func (r *relay) send(msg Message) {
+	if err := r.notifier.Notify(msg); err != nil {
+		r.log.Warn("failed to forward error notification: %v", err)
+	}
+}
+
+// alert is the message for the first occurrence of an error. Recovered
+// panics, logged with a panic field, are critical; their stack stays in the
+// logs. Every other field is cut to maxFieldLength.
+func alert(fingerprint string, event logger.Event) Message {
+	msg := Message{
+		Severity: SeverityError,
+		Category: CategoryErrors,
+		Title:    truncate(event.Message, maxTitleLength),
+		Body:     "at " + event.Caller,
+		Fields:   map[string]string{"fingerprint": fingerprint},
+	}
+
+	for key, value := range event.Fields {
+		switch key {
+		case "panic":
+			msg.Severity = SeverityCritical
+		case "stack":
+		default:
+			msg.Fields[key] = truncate(fmt.Sprint(value), maxFieldLength)
+		}
+	}
+
+	return msg
+}
+
+func truncate(text string, limit int) string {
+	if runes := []rune(text); len(runes) > limit {
+		return string(runes[:limit]) + "…"
+	}
+
+	return text
+}
+
+// Fingerprint identifies an error by where it was logged and its message
+// with IDs, numbers and quoted values taken out, so repeats of the same
+// failure share it.
+func Fingerprint(caller, message string) string {
+	sum := sha256.Sum256([]byte(caller + "\x00" + NormalizeMessage(message)))
+	return hex.EncodeToString(sum[:6])
+}
+
+func NormalizeMessage(message string) string {
+	message = quotedPattern.ReplaceAllString(message, `"…"`)
+	message = uuidPattern.ReplaceAllString(message, "<uuid>")
+	message = hexPattern.ReplaceAllString(message, "<hex>")
+	return numberPattern.ReplaceAllString(message, "<n>")
+}
+
+// span prints whole minutes and hours without their zero tails, "5m" rather
+// than "5m0s".
+func span(d time.Duration) string {
+	text := d.String()
+	if strings.HasSuffix(text, "m0s") {
+		text = strings.TrimSuffix(text, "0s")
+	}
+	if strings.HasSuffix(text, "h0m") {
+		text = strings.TrimSuffix(text, "0m")
+	}
+
+	return text
+}
@@ -0,0 +1,120 @@
+package notifier
+
+import (
+	"errors"
+	"project-wraith/pkg/modules/logger"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFingerprint(t *testing.T) {
+	tests := []struct {
+		name     string
+		callerA  string
+		messageA string
+		callerB  string
+		messageB string
+		same     bool
+	}{
+		{
+			name:    "Numbers and IDs",
+			callerA: "user.go:52 Register()", messageA: "user 66f1c0ffee0123456789abcd not found after 3 tries",
+			callerB: "user.go:52 Register()", messageB: "user 66f1c0ffee0123456789dcba not found after 12 tries",
+			same: true,
+		},
+		{
+			name:    "Quoted values and UUIDs",
+			callerA: "auth.go:30 Login()", messageA: `request 0b7c7c4e-7f0f-4bf8-9c43-8a7e0c1e2f3a failed: "alice"`,
+			callerB: "auth.go:30 Login()", messageB: `request 6d0a1f5e-1111-4bf8-9c43-000000000000 failed: "bob"`,
+			same: true,
+		},
+		{
+			name:    "Different callers",
+			callerA: "user.go:52 Register()", messageA: "failed",
+			callerB: "user.go:60 Register()", messageB: "failed",
+			same: false,
+		},
+		{
+			name:    "Different messages",
+			callerA: "user.go:52 Register()", messageA: "failed to open db",
+			callerB: "user.go:52 Register()", messageB: "failed to close db",
+			same: false,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			a := Fingerprint(tc.callerA, tc.messageA)
+			b := Fingerprint(tc.callerB, tc.messageB)
+			assert.Equal(t, tc.same, a == b)
+		})
+	}
+}
+
+func TestRelay(t *testing.T) {
+	logMock := &logger.MockLogger{}
+	logMock.On("Warn", mock.Anything).Return()
+
+	var mu sync.Mutex
+	var messages []Message
+	notifierMock := &MockNotifier{}
+	notifierMock.On("Notify", mock.Anything).Run(func(args mock.Arguments) {
+		mu.Lock()
+		defer mu.Unlock()
+		messages = append(messages, args.Get(0).(Message))
+	}).Return(errors.New("chat unavailable"))
+
+	r := NewRelay(logMock, notifierMock, time.Hour)
+
+	for i := 0; i < 57; i++ {
+		r.Hook(logger.Event{Level: "error", Caller: "user.go:52 Register()", Message: "insert failed after 3 tries", Fields: map[string]interface{}{"requestId": "r1"}})
+	}
+
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		r.Run(stop)
+		close(done)
+	}()
+	close(stop)
+	<-done
+
+	require.Len(t, messages, 2)
+	assert.Equal(t, SeverityError, messages[0].Severity)
+	assert.Equal(t, CategoryErrors, messages[0].Category)
+	assert.Equal(t, "insert failed after 3 tries", messages[0].Title)
+	assert.Equal(t, "r1", messages[0].Fields["requestId"])
+	assert.Equal(t, Fingerprint("user.go:52 Register()", "insert failed after 3 tries"), messages[0].Fields["fingerprint"])
+
+	assert.Equal(t, "error digest for the last 1h", messages[1].Title)
+	assert.Contains(t, messages[1].Body, "occurred 57 times in 1h")
+
+	logMock.AssertNumberOfCalls(t, "Warn", 2)
+}
+
+func TestAlertFields(t *testing.T) {
+	msg := alert("f1", logger.Event{
+		Level:   "error",
+		Caller:  "core/middlewares.go:80",
+		Message: "panic recovered: boom",
+		Fields: map[string]interface{}{
+			"panic":     true,
+			"stack":     strings.Repeat("goroutine 1 [running]:\n", 200),
+			"error":     strings.Repeat("x", 5000),
+			"requestId": "r1",
+		},
+	})
+
+	assert.Equal(t, SeverityCritical, msg.Severity)
+	assert.NotContains(t, msg.Fields, "stack")
+	assert.NotContains(t, msg.Fields, "panic")
+	assert.Equal(t, strings.Repeat("x", maxFieldLength)+"…", msg.Fields["error"])
+	assert.Equal(t, "r1", msg.Fields["requestId"])
+	assert.Equal(t, "f1", msg.Fields["fingerprint"])
+}
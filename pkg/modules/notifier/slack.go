@@ -0,0 +1,80 @@
+package notifier
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"project-wraith/pkg/modules/req"
+)
+
+var slackColors = map[Severity]string{
+	SeverityInfo:     "#439fe0",
+	SeverityWarning:  "warning",
+	SeverityError:    "danger",
+	SeverityCritical: "#8b0000",
+}
+
+type slackField struct {
+	Title string `json:"title"`
+	Value string `json:"value"`
+	Short bool   `json:"short"`
+}
+
+type slackAttachment struct {
+	Color  string       `json:"color"`
+	Text   string       `json:"text,omitempty"`
+	Fields []slackField `json:"fields,omitempty"`
+}
+
+type slackPayload struct {
+	Text        string            `json:"text"`
+	Attachments []slackAttachment `json:"attachments"`
+}
+
+type slackWebhook struct {
+	url string
+}
+
+// NewSlackWebhook is a function constructor for a Notifier posting to a
+// Slack incoming webhook, or any service accepting the same payload.
+func NewSlackWebhook(url string) Notifier {
+	return &slackWebhook{url: url}
+}
+
+func (sw *slackWebhook) Notify(msg Message) error {
+	attachment := slackAttachment{
+		Color: slackColors[msg.Severity],
+		Text:  msg.Body,
+	}
+	for _, key := range msg.Keys() {
+		attachment.Fields = append(attachment.Fields, slackField{
+			Title: key,
+			Value: msg.Fields[key],
+			Short: len(msg.Fields[key]) < 40,
+		})
+	}
+
+	body, err := json.Marshal(slackPayload{
+		Text:        fmt.Sprintf("*[%s] %s*", msg.Severity, msg.Title),
+		Attachments: []slackAttachment{attachment},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal message: %w", err)
+	}
+
+	res, err := req.Send(context.Background(), req.HTTPRequest{
+		Method:  http.MethodPost,
+		URL:     sw.url,
+		Body:    body,
+		Headers: map[string]string{"Content-Type": "application/json"},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to send request: %w", withoutURL(err))
+	}
+	if res.StatusCode != http.StatusOK {
+		return fmt.Errorf("slack answered %d: %s", res.StatusCode, res.Body)
+	}
+
+	return nil
+}
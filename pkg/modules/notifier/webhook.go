@@ -0,0 +1,90 @@
+package notifier
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"project-wraith/pkg/modules/req"
+	"strconv"
+	"time"
+)
+
+const (
+	SignatureHeader = "X-Wraith-Signature"
+	TimestampHeader = "X-Wraith-Timestamp"
+)
+
+type webhookPayload struct {
+	Severity Severity          `json:"severity"`
+	Category string            `json:"category"`
+	Title    string            `json:"title"`
+	Body     string            `json:"body"`
+	Fields   map[string]string `json:"fields,omitempty"`
+	Time     time.Time         `json:"time"`
+}
+
+type webhook struct {
+	url    string
+	secret string
+	now    func() time.Time
+}
+
+// NewWebhook is a function constructor for a Notifier posting messages as
+// JSON to url. With a secret, every request is signed, see Sign.
+func NewWebhook(url, secret string) Notifier {
+	return &webhook{url: url, secret: secret, now: time.Now}
+}
+
+func (wh *webhook) Notify(msg Message) error {
+	now := wh.now().UTC()
+
+	body, err := json.Marshal(webhookPayload{
+		Severity: msg.Severity,
+		Category: msg.Category,
+		Title:    msg.Title,
+		Body:     msg.Body,
+		Fields:   msg.Fields,
+		Time:     now,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal message: %w", err)
+	}
+
+	headers := map[string]string{"Content-Type": "application/json"}
+	if wh.secret != "" {
+		timestamp := strconv.FormatInt(now.Unix(), 10)
+		headers[TimestampHeader] = timestamp
+		headers[SignatureHeader] = Sign(wh.secret, timestamp, body)
+	}
+
+	res, err := req.Send(context.Background(), req.HTTPRequest{
+		Method:  http.MethodPost,
+		URL:     wh.url,
+		Body:    body,
+		Headers: headers,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to send request: %w", withoutURL(err))
+	}
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return fmt.Errorf("webhook answered %d: %s", res.StatusCode, res.Body)
+	}
+
+	return nil
+}
+
+// Sign returns the SignatureHeader value of a webhook request: the
+// HMAC-SHA256 of the TimestampHeader value, a dot and the body, so receivers
+// can reject both forged and replayed requests.
+func Sign(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
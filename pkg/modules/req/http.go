@@ -2,9 +2,18 @@ package req
 
 import (
 	"bytes"
+	"context"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
+	"project-wraith/pkg/modules/tracing"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
 )
 
 type HTTPRequest struct {
@@ -14,32 +23,72 @@ type HTTPRequest struct {
 	Body    []byte
 }
 
-func SendRequest(req HTTPRequest) (string, error) {
+// HTTPResponse is what Send returns; Body is read whole.
+type HTTPResponse struct {
+	StatusCode int
+	Body       string
+}
+
+func SendRequest(ctx context.Context, req HTTPRequest) (string, error) {
+	res, err := Send(ctx, req)
+	if err != nil {
+		return "", err
+	}
+
+	return res.Body, nil
+}
+
+// Send is SendRequest keeping the status code, for callers that must tell
+// rejected requests apart. The request is traced as a client span under the
+// one in ctx, which is propagated to the server in the traceparent header.
+func Send(ctx context.Context, req HTTPRequest) (res HTTPResponse, err error) {
 	client := &http.Client{}
 
-	request, err := http.NewRequest(req.Method, req.URL, bytes.NewBuffer(req.Body))
+	request, err := http.NewRequestWithContext(ctx, req.Method, req.URL, bytes.NewBuffer(req.Body))
 	if err != nil {
-		return "", fmt.Errorf("failed to create request: %w", err)
+		return HTTPResponse{}, fmt.Errorf("failed to create request: %w", err)
 	}
 
+	// Only the host is recorded, query strings and paths may carry secrets.
+	ctx, span := tracing.Start(ctx, fmt.Sprintf("HTTP %s", req.Method),
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("http.request.method", req.Method),
+			attribute.String("server.address", request.URL.Host)))
+	defer func() {
+		span.SetAttributes(attribute.Int("http.response.status_code", res.StatusCode))
+		tracing.End(span, withoutURL(err))
+	}()
+
 	for key, value := range req.Headers {
 		request.Header.Add(key, value)
 	}
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(request.Header))
 
 	response, err := client.Do(request)
 	if err != nil {
-		return "", fmt.Errorf("failed to send request: %w", err)
+		return HTTPResponse{}, fmt.Errorf("failed to send request: %w", err)
 	}
 
 	body, err := io.ReadAll(response.Body)
 	if err != nil {
-		return "", fmt.Errorf("failed to read response body: %w", err)
+		return HTTPResponse{}, fmt.Errorf("failed to read response body: %w", err)
 	}
 
 	err = response.Body.Close()
 	if err != nil {
-		return "", fmt.Errorf("failed to close response body: %w", err)
+		return HTTPResponse{}, fmt.Errorf("failed to close response body: %w", err)
+	}
+
+	return HTTPResponse{StatusCode: response.StatusCode, Body: string(body)}, nil
+}
+
+// withoutURL keeps request URLs, which may embed tokens, out of the traces.
+func withoutURL(err error) error {
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		return fmt.Errorf("%s: %w", urlErr.Op, urlErr.Err)
 	}
 
-	return string(body), nil
+	return err
 }
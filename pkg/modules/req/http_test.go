@@ -1,10 +1,15 @@
 package req_test
 
 import (
+	"context"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/sdk/resource"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
 	"net/http"
 	"net/http/httptest"
 	"project-wraith/pkg/modules/req"
+	"project-wraith/pkg/modules/tracing"
 	"testing"
 )
 
@@ -54,7 +59,7 @@ func TestSendRequest(t *testing.T) {
 			tc.request.URL = mockServer.URL
 
 			// Call SendRequest
-			response, err := req.SendRequest(tc.request)
+			response, err := req.SendRequest(context.Background(), tc.request)
 
 			if tc.expectedError == "" {
 				assert.NoError(t, err)
@@ -66,3 +71,33 @@ func TestSendRequest(t *testing.T) {
 		})
 	}
 }
+
+func TestSendTraced(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	shutdown := tracing.Install(recorder, resource.Empty(), 1)
+	t.Cleanup(func() { _ = shutdown(context.Background()) })
+
+	var traceparent string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		traceparent = r.Header.Get("traceparent")
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	ctx, parent := tracing.Start(context.Background(), "reset")
+	res, err := req.Send(ctx, req.HTTPRequest{Method: "POST", URL: server.URL + "/bot-token/sendMessage"})
+	parent.End()
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusServiceUnavailable, res.StatusCode)
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 2)
+	client := spans[0]
+
+	assert.Equal(t, "HTTP POST", client.Name())
+	assert.Equal(t, parent.SpanContext().SpanID(), client.Parent().SpanID())
+	assert.Equal(t, "00-"+tracing.TraceID(ctx)+"-"+client.SpanContext().SpanID().String()+"-01", traceparent)
+	for _, attr := range client.Attributes() {
+		assert.NotContains(t, attr.Value.Emit(), "bot-token")
+	}
+}
@@ -0,0 +1,453 @@
+package shipper
+
+import (
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"project-wraith/pkg/consts"
+	"project-wraith/pkg/modules/alchemy"
+	"project-wraith/pkg/modules/logger"
+	"project-wraith/pkg/modules/storage"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	// SpoolFolder holds packed segments until they are uploaded, under the
+	// logs folder.
+	SpoolFolder = "spool"
+
+	gzipExt      = ".gz"
+	sealedExt    = ".enc"
+	manifestName = "manifest-"
+	// ledgerName lists, in the spool, the segments already packed.
+	ledgerName = "shipped"
+)
+
+// Entry describes one uploaded segment.
+type Entry struct {
+	Name   string `json:"name"`
+	Key    string `json:"key"`
+	Size   int64  `json:"size"`
+	SHA256 string `json:"sha256"`
+}
+
+// Manifest lists the segments produced by one rotation. It is uploaded only
+// after every segment it names, so its presence means the set is complete.
+type Manifest struct {
+	Host      string    `json:"host"`
+	CreatedAt time.Time `json:"createdAt"`
+	Encrypted bool      `json:"encrypted"`
+	Segments  []Entry   `json:"segments"`
+}
+
+type Report struct {
+	Rotated  int
+	Uploaded int
+	Pending  int
+}
+
+func (r Report) String() string {
+	return fmt.Sprintf("rotated: %d, uploaded: %d, pending: %d", r.Rotated, r.Uploaded, r.Pending)
+}
+
+type Shipper interface {
+	// Ship rotates the log files and uploads every pending segment. Segments
+	// that fail to upload stay in the spool for the next call.
+	Ship() (*Report, error)
+	// Run calls Ship every interval until stop is closed.
+	Run(interval time.Duration, stop <-chan struct{})
+}
+
+type shipper struct {
+	log        logger.Logger
+	store      storage.Storage
+	bucket     string
+	prefix     string
+	folderPath string
+	spoolPath  string
+	files      []string
+	encrypt    bool
+	encryptKey string
+	host       string
+}
+
+// NewShipper is a function constructor for Shipper. files are names inside
+// folderPath; objects are stored as prefix/logs/yyyy/mm/dd/host/segment.
+func NewShipper(log logger.Logger, store storage.Storage, bucket, prefix, folderPath string, files []string, encrypt bool, encryptKey string) Shipper {
+	host, err := os.Hostname()
+	if err != nil || host == "" {
+		host = "unknown"
+	}
+
+	return &shipper{
+		log:        log,
+		store:      store,
+		bucket:     bucket,
+		prefix:     prefix,
+		folderPath: folderPath,
+		spoolPath:  filepath.Join(folderPath, SpoolFolder),
+		files:      files,
+		encrypt:    encrypt,
+		encryptKey: encryptKey,
+		host:       host,
+	}
+}
+
+func (s *shipper) Run(interval time.Duration, stop <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			report, err := s.Ship()
+			if err != nil {
+				s.log.Error("failed to ship logs: %v", err)
+				continue
+			}
+			s.log.Info("logs shipped, %s", report)
+		}
+	}
+}
+
+func (s *shipper) Ship() (*Report, error) {
+	rotated, err := s.rotate(time.Now().UTC())
+	if err != nil {
+		return nil, err
+	}
+
+	uploaded, pending, err := s.upload()
+	if err != nil {
+		return nil, err
+	}
+
+	return &Report{Rotated: rotated, Uploaded: uploaded, Pending: pending}, nil
+}
+
+// rotate has the logger rotate its level files, then packs every rotated
+// segment not shipped yet into the spool. The segments stay in the logs folder
+// for the viewer until the logger prunes them; the ledger remembers which
+// ones were packed. On failure the packed copies are dropped and the segments
+// are tried again on the next pass.
+func (s *shipper) rotate(now time.Time) (int, error) {
+	if err := s.log.Rotate(); err != nil {
+		return 0, fmt.Errorf("failed to rotate logs: %w", err)
+	}
+
+	partition := filepath.Join(s.spoolPath, now.Format("2006"), now.Format("01"), now.Format("02"))
+	if err := os.MkdirAll(partition, os.ModePerm); err != nil {
+		return 0, err
+	}
+
+	shipped, err := s.readLedger()
+	if err != nil {
+		return 0, err
+	}
+
+	manifest := Manifest{Host: s.host, CreatedAt: now, Encrypted: s.encrypt}
+	var present, packed []string
+
+	discard := func(err error) (int, error) {
+		for _, target := range packed {
+			os.Remove(target)
+		}
+		return 0, err
+	}
+
+	for _, file := range s.files {
+		segments, err := logger.Segments(s.folderPath, strings.TrimSuffix(file, filepath.Ext(file)))
+		if err != nil {
+			return discard(err)
+		}
+
+		for _, source := range segments {
+			// The logger may compress a segment after it was shipped, so it
+			// is recorded without the gzip extension.
+			segment := strings.TrimSuffix(filepath.Base(source), gzipExt)
+			present = append(present, segment)
+			if shipped[segment] {
+				continue
+			}
+
+			name := segment + gzipExt
+			if s.encrypt {
+				name += sealedExt
+			}
+
+			target := filepath.Join(partition, name)
+			entry, err := s.pack(source, target, strings.HasSuffix(source, gzipExt))
+			if err != nil {
+				return discard(fmt.Errorf("failed to pack %s: %w", filepath.Base(source), err))
+			}
+			entry.Key = s.key(partition, name)
+
+			packed = append(packed, target)
+			manifest.Segments = append(manifest.Segments, *entry)
+		}
+	}
+
+	if len(manifest.Segments) == 0 {
+		return 0, s.writeLedger(present)
+	}
+
+	content, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return discard(err)
+	}
+
+	manifestPath := filepath.Join(partition, manifestName+now.Format(logger.SegmentLayout)+".json")
+	if err := os.WriteFile(manifestPath, content, 0600); err != nil {
+		os.Remove(manifestPath)
+		return discard(err)
+	}
+
+	if err := s.writeLedger(present); err != nil {
+		os.Remove(manifestPath)
+		return discard(err)
+	}
+
+	return len(manifest.Segments), nil
+}
+
+// readLedger returns the segments already packed, by name without the gzip
+// extension.
+func (s *shipper) readLedger() (map[string]bool, error) {
+	content, err := os.ReadFile(filepath.Join(s.spoolPath, ledgerName))
+	if errors.Is(err, fs.ErrNotExist) {
+		return map[string]bool{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	shipped := make(map[string]bool)
+	for _, segment := range strings.Fields(string(content)) {
+		shipped[segment] = true
+	}
+
+	return shipped, nil
+}
+
+// writeLedger records segments as packed. Only the segments still in the logs
+// folder are listed, so the ledger shrinks as the logger prunes them.
+func (s *shipper) writeLedger(segments []string) error {
+	ledgerPath := filepath.Join(s.spoolPath, ledgerName)
+	tmp := ledgerPath + ".tmp"
+
+	if err := os.WriteFile(tmp, []byte(strings.Join(segments, "\n")), 0600); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+
+	return os.Rename(tmp, ledgerPath)
+}
+
+// pack writes source to target as gzip, sealed with alchemy when encryption
+// is enabled, and returns the size and checksum of what was written.
+// Segments the logger already compressed are copied as they are.
+func (s *shipper) pack(source, target string, gzipped bool) (*Entry, error) {
+	in, err := os.Open(source)
+	if err != nil {
+		return nil, err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(target, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+	if err != nil {
+		return nil, err
+	}
+
+	hash := sha256.New()
+	counter := &countWriter{}
+	sink := io.MultiWriter(out, hash, counter)
+
+	var sealer io.WriteCloser = nopCloser{sink}
+	if s.encrypt {
+		sealer, err = alchemy.SealStream(sink, s.encryptKey, false, alchemy.DefaultChunkSize)
+		if err != nil {
+			out.Close()
+			os.Remove(target)
+			return nil, err
+		}
+	}
+
+	var gz io.WriteCloser = sealer
+	if !gzipped {
+		gz = gzip.NewWriter(sealer)
+	}
+
+	_, err = io.Copy(gz, in)
+	if err == nil && !gzipped {
+		err = gz.Close()
+	}
+	if err == nil {
+		err = sealer.Close()
+	}
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(target)
+		return nil, err
+	}
+
+	return &Entry{
+		Name:   filepath.Base(target),
+		Size:   counter.n,
+		SHA256: hex.EncodeToString(hash.Sum(nil)),
+	}, nil
+}
+
+// upload sends the spooled segments, then the manifests whose segments are
+// all gone from the spool. Failures are logged and left for the next pass.
+func (s *shipper) upload() (int, int, error) {
+	var segments, manifests []string
+
+	err := filepath.WalkDir(s.spoolPath, func(current string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+		if entry.IsDir() || filepath.Dir(current) == s.spoolPath {
+			return nil
+		}
+
+		if strings.HasPrefix(entry.Name(), manifestName) {
+			manifests = append(manifests, current)
+		} else {
+			segments = append(segments, current)
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, 0, err
+	}
+
+	sort.Strings(segments)
+	sort.Strings(manifests)
+
+	uploaded, pending := 0, 0
+	for _, segment := range segments {
+		if err := s.send(segment, "application/gzip"); err != nil {
+			s.log.Warn("failed to upload log segment %s: %v", filepath.Base(segment), err)
+			pending++
+			continue
+		}
+		uploaded++
+	}
+
+	for _, manifestPath := range manifests {
+		complete, err := s.complete(manifestPath)
+		if err != nil {
+			return uploaded, pending, err
+		}
+		if !complete {
+			pending++
+			continue
+		}
+
+		if err := s.send(manifestPath, "application/json"); err != nil {
+			s.log.Warn("failed to upload log manifest %s: %v", filepath.Base(manifestPath), err)
+			pending++
+			continue
+		}
+		uploaded++
+	}
+
+	return uploaded, pending, nil
+}
+
+func (s *shipper) send(localPath, contentType string) error {
+	file, err := os.Open(localPath)
+	if err != nil {
+		return err
+	}
+
+	key := s.key(filepath.Dir(localPath), filepath.Base(localPath))
+	_, err = s.store.Upload(s.bucket, key, contentType, "private", file)
+	file.Close()
+	if err != nil {
+		return err
+	}
+
+	return os.Remove(localPath)
+}
+
+func (s *shipper) complete(manifestPath string) (bool, error) {
+	content, err := os.ReadFile(manifestPath)
+	if err != nil {
+		return false, err
+	}
+
+	var manifest Manifest
+	if err := json.Unmarshal(content, &manifest); err != nil {
+		return false, fmt.Errorf("invalid manifest %s: %w", filepath.Base(manifestPath), err)
+	}
+
+	for _, segment := range manifest.Segments {
+		_, err := os.Stat(filepath.Join(filepath.Dir(manifestPath), segment.Name))
+		if err == nil {
+			return false, nil
+		}
+		if !errors.Is(err, fs.ErrNotExist) {
+			return false, err
+		}
+	}
+
+	return true, nil
+}
+
+// key maps a spool partition folder and file name to the object key.
+func (s *shipper) key(partition, name string) string {
+	rel, err := filepath.Rel(s.spoolPath, partition)
+	if err != nil {
+		rel = "."
+	}
+
+	return path.Join(s.prefix, consts.StorageLogDir, filepath.ToSlash(rel), s.host, name)
+}
+
+// OpenSegment returns the plain log lines of a shipped segment. encryptKey
+// is only needed for segments packed with encryption.
+func OpenSegment(src io.Reader, encryptKey string) (io.ReadCloser, error) {
+	var err error
+	if encryptKey != "" {
+		src, err = alchemy.OpenStream(src, encryptKey)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return gzip.NewReader(src)
+}
+
+type countWriter struct {
+	n int64
+}
+
+func (c *countWriter) Write(p []byte) (int, error) {
+	c.n += int64(len(p))
+	return len(p), nil
+}
+
+type nopCloser struct {
+	io.Writer
+}
+
+func (nopCloser) Close() error {
+	return nil
+}
@@ -0,0 +1,19 @@
+package shipper
+
+import (
+	"github.com/stretchr/testify/mock"
+	"time"
+)
+
+type MockShipper struct {
+	mock.Mock
+}
+
+func (m *MockShipper) Ship() (*Report, error) {
+	args := m.Called()
+	return args.Get(0).(*Report), args.Error(1)
+}
+
+func (m *MockShipper) Run(interval time.Duration, stop <-chan struct{}) {
+	m.Called(interval, stop)
+}
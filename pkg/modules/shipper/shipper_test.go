@@ -0,0 +1,206 @@
+package shipper_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"project-wraith/pkg/modules/logger"
+	"project-wraith/pkg/modules/shipper"
+	"project-wraith/pkg/modules/storage"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestShip(t *testing.T) {
+	tests := []struct {
+		name       string
+		segment    string
+		encrypt    bool
+		encryptKey string
+	}{
+		{name: "Gzip only", segment: "info-20261019T120000.000Z.log"},
+		{name: "Already compressed", segment: "info-20261019T120000.000Z.log.gz"},
+		{name: "Gzip and encrypt", segment: "info-20261019T120000.000Z.log", encrypt: true, encryptKey: "logs_secret"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			folder := t.TempDir()
+			writeSegment(t, filepath.Join(folder, tc.segment), `{"message":"hello"}`+"\n")
+			require.NoError(t, os.WriteFile(filepath.Join(folder, "info.log"), nil, 0600))
+
+			store := storage.NewLocalStorage(t.TempDir())
+			log := new(logger.MockLogger)
+			log.On("Rotate").Return(nil)
+
+			ship := shipper.NewShipper(log, store, "bucket", "production", folder,
+				[]string{"info.log", "warn.log", "error.log"}, tc.encrypt, tc.encryptKey)
+
+			report, err := ship.Ship()
+			require.NoError(t, err)
+			assert.Equal(t, shipper.Report{Rotated: 1, Uploaded: 2}, *report)
+
+			assert.FileExists(t, filepath.Join(folder, tc.segment))
+
+			report, err = ship.Ship()
+			require.NoError(t, err)
+			assert.Equal(t, shipper.Report{}, *report)
+
+			objects, err := store.List("bucket", "production/logs/")
+			require.NoError(t, err)
+			require.Len(t, objects, 2)
+
+			host, _ := os.Hostname()
+			var manifest shipper.Manifest
+			for _, object := range objects {
+				assert.Contains(t, object.Key, "/"+host+"/")
+				if strings.Contains(object.Key, "manifest-") {
+					reader, err := store.Download("bucket", object.Key)
+					require.NoError(t, err)
+					require.NoError(t, json.NewDecoder(reader).Decode(&manifest))
+					reader.Close()
+				}
+			}
+
+			require.Len(t, manifest.Segments, 1)
+			segment := manifest.Segments[0]
+			assert.Equal(t, tc.encrypt, manifest.Encrypted)
+
+			reader, err := store.Download("bucket", segment.Key)
+			require.NoError(t, err)
+			packed, err := io.ReadAll(reader)
+			reader.Close()
+			require.NoError(t, err)
+
+			sum := sha256.Sum256(packed)
+			assert.Equal(t, hex.EncodeToString(sum[:]), segment.SHA256)
+			assert.Equal(t, int64(len(packed)), segment.Size)
+
+			plain, err := shipper.OpenSegment(bytes.NewReader(packed), tc.encryptKey)
+			require.NoError(t, err)
+			lines, err := io.ReadAll(plain)
+			require.NoError(t, err)
+			assert.Equal(t, `{"message":"hello"}`+"\n", string(lines))
+		})
+	}
+}
+
+func TestShipRetry(t *testing.T) {
+	folder := t.TempDir()
+	writeSegment(t, filepath.Join(folder, "info-20261019T120000.000Z.log"), "line\n")
+
+	log := new(logger.MockLogger)
+	log.On("Rotate").Return(nil)
+	log.On("Warn", mock.Anything)
+
+	failing := new(storage.MockStorage)
+	failing.On("Upload", "bucket", mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+		Return((*storage.Object)(nil), errors.New("connection refused"))
+
+	ship := shipper.NewShipper(log, failing, "bucket", "production", folder, []string{"info.log"}, false, "")
+
+	report, err := ship.Ship()
+	require.NoError(t, err)
+	assert.Equal(t, shipper.Report{Rotated: 1, Pending: 2}, *report)
+
+	store := storage.NewLocalStorage(t.TempDir())
+	ship = shipper.NewShipper(log, store, "bucket", "production", folder, []string{"info.log"}, false, "")
+
+	report, err = ship.Ship()
+	require.NoError(t, err)
+	assert.Equal(t, shipper.Report{Uploaded: 2}, *report)
+
+	objects, err := store.List("bucket", "production/logs/")
+	require.NoError(t, err)
+	assert.Len(t, objects, 2)
+}
+
+func TestShipPackFailure(t *testing.T) {
+	folder := t.TempDir()
+	packable := filepath.Join(folder, "info-20261019T120000.000Z.log")
+	broken := filepath.Join(folder, "warn-20261019T120000.000Z.log")
+	writeSegment(t, packable, "line\n")
+	require.NoError(t, os.Mkdir(broken, os.ModePerm))
+
+	log := new(logger.MockLogger)
+	log.On("Rotate").Return(nil)
+
+	store := storage.NewLocalStorage(t.TempDir())
+	ship := shipper.NewShipper(log, store, "bucket", "production", folder, []string{"info.log", "warn.log"}, false, "")
+
+	_, err := ship.Ship()
+	require.Error(t, err)
+	assert.FileExists(t, packable)
+
+	var spooled []string
+	require.NoError(t, filepath.WalkDir(filepath.Join(folder, shipper.SpoolFolder), func(path string, d os.DirEntry, err error) error {
+		if err == nil && !d.IsDir() {
+			spooled = append(spooled, path)
+		}
+		return err
+	}))
+	assert.Empty(t, spooled)
+
+	require.NoError(t, os.Remove(broken))
+
+	report, err := ship.Ship()
+	require.NoError(t, err)
+	assert.Equal(t, shipper.Report{Rotated: 1, Uploaded: 2}, *report)
+	assert.FileExists(t, packable)
+}
+
+func TestShipKeepsSegmentsReadable(t *testing.T) {
+	folder := t.TempDir()
+	writeSegment(t, filepath.Join(folder, "info-20261019T120000.000Z.log"),
+		`{"severity":"info","ts":"2026-10-19T11:59:00.000Z","message":"shipped"}`+"\n")
+
+	log := new(logger.MockLogger)
+	log.On("Rotate").Return(nil)
+
+	store := storage.NewLocalStorage(t.TempDir())
+	ship := shipper.NewShipper(log, store, "bucket", "production", folder, []string{"info.log"}, false, "")
+
+	report, err := ship.Ship()
+	require.NoError(t, err)
+	assert.Equal(t, shipper.Report{Rotated: 1, Uploaded: 2}, *report)
+
+	page, err := logger.Search(folder, "", logger.Query{Levels: []string{"info"}})
+	require.NoError(t, err)
+	require.Len(t, page.Entries, 1)
+	assert.Equal(t, "shipped", page.Entries[0]["message"])
+
+	// A segment the logger compressed since is not shipped again.
+	writeSegment(t, filepath.Join(folder, "info-20261019T120000.000Z.log.gz"), "line\n")
+	require.NoError(t, os.Remove(filepath.Join(folder, "info-20261019T120000.000Z.log")))
+
+	report, err = ship.Ship()
+	require.NoError(t, err)
+	assert.Equal(t, shipper.Report{}, *report)
+}
+
+func writeSegment(t *testing.T, path, content string) {
+	t.Helper()
+
+	if !strings.HasSuffix(path, ".gz") {
+		require.NoError(t, os.WriteFile(path, []byte(content), 0600))
+		return
+	}
+
+	file, err := os.Create(path)
+	require.NoError(t, err)
+	gz := gzip.NewWriter(file)
+	_, err = gz.Write([]byte(content))
+	require.NoError(t, err)
+	require.NoError(t, gz.Close())
+	require.NoError(t, file.Close())
+}
@@ -1,16 +1,18 @@
 package sms
 
 import (
+	"context"
 	"encoding/base64"
 	"fmt"
 	"net/url"
+	"project-wraith/pkg/modules/metrics"
 	"project-wraith/pkg/modules/req"
 	"project-wraith/pkg/modules/tools"
 	"strings"
 )
 
 type Twilio interface {
-	SendSMSTwilio(to string, useAsset bool, args ...string) (string, error)
+	SendSMSTwilio(ctx context.Context, to string, useAsset bool, args ...string) (string, error)
 }
 
 type twilio struct {
@@ -29,7 +31,7 @@ func NewTwilio(from, accountSID, authToken string, asset string) Twilio {
 	}
 }
 
-func (tc twilio) SendSMSTwilio(to string, useAsset bool, args ...string) (string, error) {
+func (tc twilio) SendSMSTwilio(ctx context.Context, to string, useAsset bool, args ...string) (string, error) {
 	apiURL := fmt.Sprintf(
 		"https://api.twilio.com/2010-04-01/Accounts/%s/Messages.json", tc.accountSID)
 
@@ -58,5 +60,8 @@ func (tc twilio) SendSMSTwilio(to string, useAsset bool, args ...string) (string
 		Body: []byte(data.Encode()),
 	}
 
-	return req.SendRequest(smsRequest)
+	res, err := req.SendRequest(ctx, smsRequest)
+	metrics.SMSSent.WithLabelValues(metrics.Outcome(err)).Inc()
+
+	return res, err
 }
@@ -1,12 +1,16 @@
 package sms
 
-import "github.com/stretchr/testify/mock"
+import (
+	"context"
+
+	"github.com/stretchr/testify/mock"
+)
 
 type MockTwilio struct {
 	mock.Mock
 }
 
-func (m *MockTwilio) SendSMSTwilio(to string, useAsset bool, args ...string) (string, error) {
-	argsMock := m.Called(to, useAsset, args)
+func (m *MockTwilio) SendSMSTwilio(ctx context.Context, to string, useAsset bool, args ...string) (string, error) {
+	argsMock := m.Called(ctx, to, useAsset, args)
 	return argsMock.String(0), argsMock.Error(1)
 }
@@ -1,6 +1,7 @@
 package sms_test
 
 import (
+	"context"
 	"encoding/base64"
 	"fmt"
 	"github.com/stretchr/testify/assert"
@@ -16,7 +17,7 @@ type MockHTTPRequester struct {
 	mock.Mock
 }
 
-func (m *MockHTTPRequester) SendRequest(req req.HTTPRequest) (string, error) {
+func (m *MockHTTPRequester) SendRequest(ctx context.Context, req req.HTTPRequest) (string, error) {
 	args := m.Called(req)
 	return args.String(0), args.Error(1)
 }
@@ -75,7 +76,7 @@ func TestSendSMSTwilio(t *testing.T) {
 
 			twilioClient := sms.NewTwilio(from, accountSID, authToken, asset)
 
-			_, err := twilioClient.SendSMSTwilio(to, true, args...)
+			_, err := twilioClient.SendSMSTwilio(context.Background(), to, true, args...)
 			if tc.expectedError == "" {
 				assert.NoError(t, err)
 			}
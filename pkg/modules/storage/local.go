@@ -0,0 +1,211 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// localStorage keeps objects as files under root/bucket/key. It is meant for
+// development and tests; permissions are ignored.
+type localStorage struct {
+	root string
+}
+
+// NewLocalStorage is a function constructor for a filesystem backed Storage.
+func NewLocalStorage(root string) Storage {
+	return &localStorage{root: root}
+}
+
+func (l *localStorage) UploadObject(bucket, directory, filename, permission, localPath string) (*Object, error) {
+	file, err := os.Open(localPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file: %w", err)
+	}
+	defer file.Close()
+
+	return l.Upload(bucket, path.Join(directory, path.Base(filename)), "", permission, file)
+}
+
+func (l *localStorage) Upload(bucket, key, contentType, _ string, body io.Reader) (*Object, error) {
+	target, err := l.path(bucket, key)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(target), os.ModePerm); err != nil {
+		return nil, err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(target), filepath.Base(target)+".*.tmp")
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(tmp.Name())
+
+	size, err := io.Copy(tmp, body)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := os.Rename(tmp.Name(), target); err != nil {
+		return nil, err
+	}
+
+	if contentType == "" {
+		contentType = contentTypeOf(key)
+	}
+
+	return &Object{Key: key, Size: size, ContentType: contentType, LastModified: time.Now()}, nil
+}
+
+func (l *localStorage) Download(bucket, key string) (io.ReadCloser, error) {
+	target, err := l.path(bucket, key)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := os.Open(target)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, key)
+	}
+
+	return file, err
+}
+
+func (l *localStorage) List(bucket, prefix string) ([]Object, error) {
+	base, err := l.bucketPath(bucket)
+	if err != nil {
+		return nil, err
+	}
+
+	var objects []Object
+	err = filepath.WalkDir(base, func(current string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+
+		if entry.IsDir() || strings.HasSuffix(entry.Name(), ".tmp") {
+			return nil
+		}
+
+		rel, err := filepath.Rel(base, current)
+		if err != nil {
+			return err
+		}
+
+		key := filepath.ToSlash(rel)
+		if !strings.HasPrefix(key, prefix) {
+			return nil
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+
+		objects = append(objects, Object{
+			Key:          key,
+			Size:         info.Size(),
+			ContentType:  contentTypeOf(key),
+			LastModified: info.ModTime(),
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(objects, func(i, j int) bool { return objects[i].Key < objects[j].Key })
+	return objects, nil
+}
+
+func (l *localStorage) Delete(bucket, key string) error {
+	target, err := l.path(bucket, key)
+	if err != nil {
+		return err
+	}
+
+	err = os.Remove(target)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+
+	return err
+}
+
+func (l *localStorage) Stat(bucket, key string) (*Object, error) {
+	target, err := l.path(bucket, key)
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := os.Stat(target)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, fmt.Errorf("%w: %s", ErrNotFound, key)
+		}
+		return nil, err
+	}
+
+	return &Object{
+		Key:          key,
+		Size:         info.Size(),
+		ContentType:  contentTypeOf(key),
+		LastModified: info.ModTime(),
+	}, nil
+}
+
+// Presign has nothing to sign locally; it returns a file URL to the object.
+func (l *localStorage) Presign(bucket, key, _ string, _ time.Duration) (string, error) {
+	target, err := l.path(bucket, key)
+	if err != nil {
+		return "", err
+	}
+
+	absolute, err := filepath.Abs(target)
+	if err != nil {
+		return "", err
+	}
+
+	return (&url.URL{Scheme: "file", Path: filepath.ToSlash(absolute)}).String(), nil
+}
+
+// path resolves an object key, refusing keys that would escape the bucket.
+func (l *localStorage) path(bucket, key string) (string, error) {
+	base, err := l.bucketPath(bucket)
+	if err != nil {
+		return "", err
+	}
+
+	clean := path.Clean("/" + key)
+	if clean == "/" {
+		return "", fmt.Errorf("invalid object location %q/%q", bucket, key)
+	}
+
+	return filepath.Join(base, filepath.FromSlash(clean)), nil
+}
+
+// bucketPath resolves a bucket folder, refusing names that are not a single
+// plain path element such as "", "." or "..".
+func (l *localStorage) bucketPath(bucket string) (string, error) {
+	if bucket == "" || filepath.Base(bucket) != bucket || strings.HasPrefix(bucket, ".") {
+		return "", fmt.Errorf("invalid bucket %q", bucket)
+	}
+
+	return filepath.Join(l.root, bucket), nil
+}
@@ -0,0 +1,111 @@
+package storage_test
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"project-wraith/pkg/modules/storage"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLocalStorage(t *testing.T) {
+	root := t.TempDir()
+	store := storage.NewLocalStorage(root)
+
+	_, err := store.Upload("bucket", "logs/info.log", "", "", strings.NewReader("info"))
+	require.NoError(t, err)
+	_, err = store.Upload("bucket", "logs/error.log", "", "", strings.NewReader("error"))
+	require.NoError(t, err)
+	_, err = store.Upload("bucket", "avatars/alice.png", "image/png", "", bytes.NewReader([]byte{1, 2, 3}))
+	require.NoError(t, err)
+
+	tests := []struct {
+		name         string
+		prefix       string
+		expectedKeys []string
+	}{
+		{name: "Everything", prefix: "", expectedKeys: []string{"avatars/alice.png", "logs/error.log", "logs/info.log"}},
+		{name: "By prefix", prefix: "logs/", expectedKeys: []string{"logs/error.log", "logs/info.log"}},
+		{name: "No match", prefix: "missing/", expectedKeys: nil},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			objects, err := store.List("bucket", tc.prefix)
+			require.NoError(t, err)
+
+			var keys []string
+			for _, object := range objects {
+				keys = append(keys, object.Key)
+			}
+			assert.Equal(t, tc.expectedKeys, keys)
+		})
+	}
+}
+
+func TestLocalStorageLifecycle(t *testing.T) {
+	root := t.TempDir()
+	store := storage.NewLocalStorage(root)
+
+	source := filepath.Join(t.TempDir(), "report.txt")
+	require.NoError(t, os.WriteFile(source, []byte("quarterly report"), 0600))
+
+	object, err := store.UploadObject("bucket", "reports", "report.txt", "read", source)
+	require.NoError(t, err)
+	assert.Equal(t, "reports/report.txt", object.Key)
+	assert.Equal(t, int64(16), object.Size)
+
+	stat, err := store.Stat("bucket", "reports/report.txt")
+	require.NoError(t, err)
+	assert.Equal(t, object.Size, stat.Size)
+	assert.Contains(t, stat.ContentType, "text/plain")
+
+	reader, err := store.Download("bucket", "reports/report.txt")
+	require.NoError(t, err)
+	content, err := io.ReadAll(reader)
+	require.NoError(t, reader.Close())
+	require.NoError(t, err)
+	assert.Equal(t, "quarterly report", string(content))
+
+	url, err := store.Presign("bucket", "reports/report.txt", http.MethodGet, time.Minute)
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(url, "file://"))
+
+	require.NoError(t, store.Delete("bucket", "reports/report.txt"))
+	require.NoError(t, store.Delete("bucket", "reports/report.txt"))
+
+	_, err = store.Stat("bucket", "reports/report.txt")
+	assert.ErrorIs(t, err, storage.ErrNotFound)
+
+	_, err = store.Download("bucket", "reports/report.txt")
+	assert.ErrorIs(t, err, storage.ErrNotFound)
+}
+
+func TestLocalStorageEscape(t *testing.T) {
+	root := t.TempDir()
+	store := storage.NewLocalStorage(filepath.Join(root, "objects"))
+
+	_, err := store.Upload("bucket", "../../outside.txt", "", "", strings.NewReader("x"))
+	require.NoError(t, err)
+
+	_, err = os.Stat(filepath.Join(root, "outside.txt"))
+	assert.True(t, os.IsNotExist(err))
+
+	for _, bucket := range []string{"", ".", "..", "../bucket", "bucket/nested", ".hidden"} {
+		_, err = store.Upload(bucket, "file.txt", "", "", strings.NewReader("x"))
+		assert.Error(t, err, bucket)
+
+		_, err = store.List(bucket, "")
+		assert.Error(t, err, bucket)
+	}
+
+	_, err = os.Stat(filepath.Join(root, "file.txt"))
+	assert.True(t, os.IsNotExist(err))
+}
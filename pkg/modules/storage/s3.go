@@ -0,0 +1,203 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3manager"
+	"github.com/gabriel-vasile/mimetype"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"path"
+	"strings"
+	"time"
+)
+
+// partSize is the multipart chunk used by Upload; bodies smaller than this
+// are sent with a single request.
+const partSize = 16 * 1024 * 1024
+
+// storageClient talks to S3 or any compatible service (Vultr, MinIO, ...).
+type storageClient struct {
+	client   *s3.S3
+	uploader *s3manager.Uploader
+}
+
+// NewObjectStorage is a function constructor for an S3 compatible Storage.
+// pathStyle selects endpoint/bucket/key addressing, which most self-hosted
+// services need, instead of bucket.endpoint/key.
+func NewObjectStorage(accessKey, secretKey, endpoint, region string, pathStyle bool) (Storage, error) {
+	sess, err := session.NewSession(&aws.Config{
+		Region:           aws.String(region),
+		Credentials:      credentials.NewStaticCredentials(accessKey, secretKey, ""),
+		Endpoint:         aws.String(endpoint),
+		S3ForcePathStyle: aws.Bool(pathStyle),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create storage session: %w", err)
+	}
+
+	client := s3.New(sess)
+	uploader := s3manager.NewUploaderWithClient(client, func(u *s3manager.Uploader) {
+		u.PartSize = partSize
+	})
+
+	return &storageClient{client: client, uploader: uploader}, nil
+}
+
+func (s *storageClient) UploadObject(bucket, directory, filename, permission, localPath string) (*Object, error) {
+	file, err := os.Open(localPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file: %w", err)
+	}
+	defer file.Close()
+
+	mimeType, err := mimetype.DetectFile(localPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to detect content type: %w", err)
+	}
+
+	objectKey := path.Join(directory, path.Base(filename))
+	log.Printf("Uploading Object: %s", filename)
+
+	output, err := s.Upload(bucket, objectKey, mimeType.String(), permission, file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to upload file %q: %w", filename, err)
+	}
+
+	log.Printf("File %q uploaded successfully.", filename)
+	return output, nil
+}
+
+func (s *storageClient) Upload(bucket, key, contentType, permission string, body io.Reader) (*Object, error) {
+	if contentType == "" {
+		contentType = contentTypeOf(key)
+	}
+
+	input := &s3manager.UploadInput{
+		Body:        body,
+		Bucket:      aws.String(bucket),
+		Key:         aws.String(key),
+		ContentType: aws.String(contentType),
+	}
+	if permission != "" {
+		input.ACL = aws.String(permission)
+	}
+
+	output, err := s.uploader.Upload(input)
+	if err != nil {
+		return nil, err
+	}
+
+	object := &Object{Key: key, ContentType: contentType}
+	if output.ETag != nil {
+		object.ETag = strings.Trim(*output.ETag, `"`)
+	}
+
+	return object, nil
+}
+
+func (s *storageClient) Download(bucket, key string) (io.ReadCloser, error) {
+	output, err := s.client.GetObject(&s3.GetObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		return nil, translate(err)
+	}
+
+	return output.Body, nil
+}
+
+func (s *storageClient) List(bucket, prefix string) ([]Object, error) {
+	var objects []Object
+
+	err := s.client.ListObjectsV2Pages(&s3.ListObjectsV2Input{
+		Bucket: aws.String(bucket),
+		Prefix: aws.String(prefix),
+	}, func(page *s3.ListObjectsV2Output, _ bool) bool {
+		for _, item := range page.Contents {
+			objects = append(objects, Object{
+				Key:          aws.StringValue(item.Key),
+				Size:         aws.Int64Value(item.Size),
+				ETag:         strings.Trim(aws.StringValue(item.ETag), `"`),
+				LastModified: aws.TimeValue(item.LastModified),
+			})
+		}
+		return true
+	})
+	if err != nil {
+		return nil, translate(err)
+	}
+
+	return objects, nil
+}
+
+func (s *storageClient) Delete(bucket, key string) error {
+	_, err := s.client.DeleteObject(&s3.DeleteObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+	})
+
+	return translate(err)
+}
+
+func (s *storageClient) Stat(bucket, key string) (*Object, error) {
+	output, err := s.client.HeadObject(&s3.HeadObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		return nil, translate(err)
+	}
+
+	return &Object{
+		Key:          key,
+		Size:         aws.Int64Value(output.ContentLength),
+		ContentType:  aws.StringValue(output.ContentType),
+		ETag:         strings.Trim(aws.StringValue(output.ETag), `"`),
+		LastModified: aws.TimeValue(output.LastModified),
+	}, nil
+}
+
+func (s *storageClient) Presign(bucket, key, method string, expiry time.Duration) (string, error) {
+	var req *request.Request
+
+	switch method {
+	case http.MethodGet:
+		req, _ = s.client.GetObjectRequest(&s3.GetObjectInput{
+			Bucket: aws.String(bucket),
+			Key:    aws.String(key),
+		})
+	case http.MethodPut:
+		req, _ = s.client.PutObjectRequest(&s3.PutObjectInput{
+			Bucket: aws.String(bucket),
+			Key:    aws.String(key),
+		})
+	default:
+		return "", fmt.Errorf("unsupported presign method %q", method)
+	}
+
+	return req.Presign(expiry)
+}
+
+// translate maps missing keys and buckets to ErrNotFound.
+func translate(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	var awsErr awserr.RequestFailure
+	if errors.As(err, &awsErr) && awsErr.StatusCode() == http.StatusNotFound {
+		return fmt.Errorf("%w: %v", ErrNotFound, err)
+	}
+
+	return err
+}
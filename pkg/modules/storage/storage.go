@@ -0,0 +1,42 @@
+package storage
+
+import (
+	"errors"
+	"io"
+	"mime"
+	"path"
+	"time"
+)
+
+var ErrNotFound = errors.New("object not found")
+
+// Object describes a stored object, independently of the backend.
+type Object struct {
+	Key          string
+	Size         int64
+	ContentType  string
+	ETag         string
+	LastModified time.Time
+}
+
+type Storage interface {
+	// UploadObject uploads the file at localPath as directory/filename.
+	UploadObject(bucket, directory, filename, permission, localPath string) (*Object, error)
+	// Upload streams body to key; large bodies are sent in parts.
+	Upload(bucket, key, contentType, permission string, body io.Reader) (*Object, error)
+	// Download returns the content of key. The caller closes it.
+	Download(bucket, key string) (io.ReadCloser, error)
+	List(bucket, prefix string) ([]Object, error)
+	Delete(bucket, key string) error
+	Stat(bucket, key string) (*Object, error)
+	// Presign returns a URL granting method (GET or PUT) on key until expiry.
+	Presign(bucket, key, method string, expiry time.Duration) (string, error)
+}
+
+func contentTypeOf(key string) string {
+	contentType := mime.TypeByExtension(path.Ext(key))
+	if contentType == "" {
+		return "application/octet-stream"
+	}
+	return contentType
+}
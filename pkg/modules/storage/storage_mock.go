@@ -0,0 +1,45 @@
+package storage
+
+import (
+	"github.com/stretchr/testify/mock"
+	"io"
+	"time"
+)
+
+type MockStorage struct {
+	mock.Mock
+}
+
+func (m *MockStorage) UploadObject(bucket, directory, filename, permission, localPath string) (*Object, error) {
+	args := m.Called(bucket, directory, filename, permission, localPath)
+	return args.Get(0).(*Object), args.Error(1)
+}
+
+func (m *MockStorage) Upload(bucket, key, contentType, permission string, body io.Reader) (*Object, error) {
+	args := m.Called(bucket, key, contentType, permission, body)
+	return args.Get(0).(*Object), args.Error(1)
+}
+
+func (m *MockStorage) Download(bucket, key string) (io.ReadCloser, error) {
+	args := m.Called(bucket, key)
+	return args.Get(0).(io.ReadCloser), args.Error(1)
+}
+
+func (m *MockStorage) List(bucket, prefix string) ([]Object, error) {
+	args := m.Called(bucket, prefix)
+	return args.Get(0).([]Object), args.Error(1)
+}
+
+func (m *MockStorage) Delete(bucket, key string) error {
+	return m.Called(bucket, key).Error(0)
+}
+
+func (m *MockStorage) Stat(bucket, key string) (*Object, error) {
+	args := m.Called(bucket, key)
+	return args.Get(0).(*Object), args.Error(1)
+}
+
+func (m *MockStorage) Presign(bucket, key, method string, expiry time.Duration) (string, error) {
+	args := m.Called(bucket, key, method, expiry)
+	return args.String(0), args.Error(1)
+}
@@ -0,0 +1,43 @@
+package tracing_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/sdk/resource"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"project-wraith/pkg/modules/tracing"
+)
+
+func TestTracing(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	shutdown := tracing.Install(recorder, resource.Empty(), 1)
+	t.Cleanup(func() { _ = shutdown(context.Background()) })
+
+	assert.Empty(t, tracing.TraceID(context.Background()))
+
+	ctx, parent := tracing.Start(context.Background(), "parent")
+	traceID := tracing.TraceID(ctx)
+	assert.Len(t, traceID, 32)
+
+	_, child := tracing.Start(ctx, "child")
+	tracing.End(child, errors.New("query failed"))
+	tracing.End(parent, nil)
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 2)
+
+	assert.Equal(t, "child", spans[0].Name())
+	assert.Equal(t, traceID, spans[0].SpanContext().TraceID().String())
+	assert.Equal(t, spans[1].SpanContext().SpanID(), spans[0].Parent().SpanID())
+	assert.Equal(t, codes.Error, spans[0].Status().Code)
+	assert.Equal(t, "query failed", spans[0].Status().Description)
+	require.Len(t, spans[0].Events(), 1)
+
+	assert.Equal(t, "parent", spans[1].Name())
+	assert.Equal(t, codes.Unset, spans[1].Status().Code)
+}